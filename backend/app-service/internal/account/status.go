@@ -0,0 +1,102 @@
+// Package account 提供账号状态查询，供认证中间件在每次请求时校验
+package account
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserDisabled = errors.New("user disabled")
+)
+
+// statusTTL 账号状态缓存时间。本实例内的禁用操作会立即清除缓存，
+// 其他实例最多延迟statusTTL生效（禁用时已同时吊销全部会话）
+const statusTTL = 30 * time.Second
+
+// Status 账号状态快照
+type Status struct {
+	UserID uint
+	Status int
+	Role   string
+}
+
+type statusEntry struct {
+	status    *Status // nil表示用户不存在或已注销
+	expiresAt time.Time
+}
+
+var (
+	mu    sync.RWMutex
+	cache = make(map[uint]statusEntry)
+)
+
+// Check 校验账号是否可用，返回账号状态。用户已注销返回ErrUserNotFound，被禁用返回ErrUserDisabled
+func Check(db *gorm.DB, userID uint) (*Status, error) {
+	status, err := lookup(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrUserNotFound
+	}
+	if status.Status != model.UserStatusNormal {
+		return status, ErrUserDisabled
+	}
+	return status, nil
+}
+
+// Invalidate 清除账号状态缓存，修改用户状态或角色后调用
+func Invalidate(userID uint) {
+	mu.Lock()
+	delete(cache, userID)
+	mu.Unlock()
+}
+
+// Reset 清除全部账号状态缓存，切换数据库（如测试中重建内存数据库）后调用
+func Reset() {
+	mu.Lock()
+	cache = make(map[uint]statusEntry)
+	mu.Unlock()
+}
+
+func lookup(db *gorm.DB, userID uint) (*Status, error) {
+	now := time.Now()
+
+	mu.RLock()
+	entry, ok := cache[userID]
+	mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.status, nil
+	}
+
+	var user model.User
+	var status *Status
+	result := db.Select("id, status, role").Where("id = ?", userID).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		status = &Status{UserID: user.ID, Status: user.Status, Role: user.Role}
+	}
+
+	mu.Lock()
+	// 顺带清理过期缓存，避免长期运行后无限增长
+	if len(cache) > 10000 {
+		for id, e := range cache {
+			if now.After(e.expiresAt) {
+				delete(cache, id)
+			}
+		}
+	}
+	cache[userID] = statusEntry{status: status, expiresAt: now.Add(statusTTL)}
+	mu.Unlock()
+
+	return status, nil
+}
@@ -0,0 +1,79 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidVerificationToken 令牌不存在、已使用或已过期
+var ErrInvalidVerificationToken = errors.New("invalid verification token")
+
+// 一次性令牌有效期
+const (
+	EmailVerifyTTL   = 24 * time.Hour
+	PasswordResetTTL = 30 * time.Minute
+)
+
+// CreateVerificationToken 签发一次性令牌，同一用户同一用途的旧令牌随即作废，返回令牌明文
+func CreateVerificationToken(db *gorm.DB, userID uint, purpose, email string, ttl time.Duration) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&model.VerificationToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", &now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.VerificationToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hashVerificationToken(raw),
+			Email:     email,
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// ConsumeVerificationToken 校验并使用一次性令牌，同一令牌只能成功使用一次
+func ConsumeVerificationToken(db *gorm.DB, purpose, raw string) (*model.VerificationToken, error) {
+	var row model.VerificationToken
+	if result := db.Where("token_hash = ? AND purpose = ?", hashVerificationToken(raw), purpose).First(&row); result.Error != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	if row.UsedAt != nil || time.Now().After(row.ExpiresAt) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// 条件更新保证并发请求中只有一个能使用该令牌
+	now := time.Now()
+	result := db.Model(&row).Where("used_at IS NULL").Update("used_at", &now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidVerificationToken
+	}
+	return &row, nil
+}
+
+func hashVerificationToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package account
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&model.VerificationToken{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestVerificationTokenSingleUse(t *testing.T) {
+	db := newTestDB(t)
+
+	raw, err := CreateVerificationToken(db, 1, model.TokenPurposeEmailVerify, "alice@example.com", EmailVerifyTTL)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 用途不符的令牌不能使用
+	if _, err := ConsumeVerificationToken(db, model.TokenPurposePasswordReset, raw); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("consume with wrong purpose: %v", err)
+	}
+
+	row, err := ConsumeVerificationToken(db, model.TokenPurposeEmailVerify, raw)
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if row.UserID != 1 || row.Email != "alice@example.com" {
+		t.Fatalf("consumed %+v", row)
+	}
+
+	if _, err := ConsumeVerificationToken(db, model.TokenPurposeEmailVerify, raw); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("second consume: %v", err)
+	}
+}
+
+func TestVerificationTokenReplaced(t *testing.T) {
+	db := newTestDB(t)
+
+	first, err := CreateVerificationToken(db, 1, model.TokenPurposePasswordReset, "alice@example.com", PasswordResetTTL)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	second, err := CreateVerificationToken(db, 1, model.TokenPurposePasswordReset, "alice@example.com", PasswordResetTTL)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 重新发送后旧链接作废
+	if _, err := ConsumeVerificationToken(db, model.TokenPurposePasswordReset, first); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("consume replaced token: %v", err)
+	}
+	if _, err := ConsumeVerificationToken(db, model.TokenPurposePasswordReset, second); err != nil {
+		t.Fatalf("consume latest token: %v", err)
+	}
+}
+
+func TestVerificationTokenExpired(t *testing.T) {
+	db := newTestDB(t)
+
+	raw, err := CreateVerificationToken(db, 1, model.TokenPurposePasswordReset, "alice@example.com", -time.Minute)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := ConsumeVerificationToken(db, model.TokenPurposePasswordReset, raw); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Fatalf("consume expired token: %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package apperr 定义接口返回的业务错误。
+// 错误带有HTTP状态码、稳定的错误码和中文提示，由错误渲染中间件按Accept-Language本地化后输出
+package apperr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code 机器可读的错误码，客户端应根据错误码而不是提示文字判断错误类型，发布后不再修改
+type Code string
+
+const (
+	CodeInvalidRequest     Code = "invalid_request"     // 请求参数错误
+	CodeInvalidCursor      Code = "invalid_cursor"      // 分页游标无效
+	CodeContentRejected    Code = "content_rejected"    // 内容未通过过滤
+	CodeInvalidCredentials Code = "invalid_credentials" // 用户名或密码错误
+	CodeLinkExpired        Code = "link_expired"        // 验证、重置或授权链接无效或已过期
+	CodeLimitExceeded      Code = "limit_exceeded"      // 数量达到上限
+	CodeUnauthorized       Code = "unauthorized"        // 未登录
+	CodeTokenInvalid       Code = "token_invalid"       // 令牌无效
+	CodeTokenExpired       Code = "token_expired"       // 令牌已过期，可刷新后重试
+	CodeTokenRevoked       Code = "token_revoked"       // 令牌已吊销，需要重新登录
+	CodeForbidden          Code = "forbidden"           // 无权限
+	CodeAccountDisabled    Code = "account_disabled"    // 账号已被禁用
+	CodeNotFound           Code = "not_found"           // 资源不存在
+	CodeConflict           Code = "conflict"            // 当前状态不允许该操作
+	CodeAlreadyExists      Code = "already_exists"      // 重复创建
+	CodeRateLimited        Code = "rate_limited"        // 请求过于频繁
+	CodeLoginLocked        Code = "login_locked"        // 登录失败次数过多，账号暂时锁定
+	CodeInternal           Code = "internal_error"      // 服务器错误
+	CodeUnavailable        Code = "service_unavailable" // 依赖的服务暂不可用
+)
+
+// Error 业务错误。Message为中文提示，可以包含fmt格式占位符，参数保存在args中，
+// 本地化时先翻译再格式化
+type Error struct {
+	Status  int    // HTTP状态码
+	Code    Code   // 错误码
+	Message string // 中文提示
+	Detail  string // 补充说明，如参数校验的原始错误，不做翻译
+	Data    any    // 随错误返回的数据
+
+	args []any
+}
+
+// New 创建业务错误
+func New(status int, code Code, message string, args ...any) *Error {
+	return &Error{Status: status, Code: code, Message: message, args: args}
+}
+
+func BadRequest(message string, args ...any) *Error {
+	return New(http.StatusBadRequest, CodeInvalidRequest, message, args...)
+}
+
+func Unauthorized(message string, args ...any) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message, args...)
+}
+
+func Forbidden(message string, args ...any) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message, args...)
+}
+
+func NotFound(message string, args ...any) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message, args...)
+}
+
+func Conflict(message string, args ...any) *Error {
+	return New(http.StatusConflict, CodeConflict, message, args...)
+}
+
+func TooManyRequests(message string, args ...any) *Error {
+	return New(http.StatusTooManyRequests, CodeRateLimited, message, args...)
+}
+
+func Internal(message string, args ...any) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message, args...)
+}
+
+func Unavailable(message string, args ...any) *Error {
+	return New(http.StatusServiceUnavailable, CodeUnavailable, message, args...)
+}
+
+// Invalid 请求体绑定或校验失败，原始错误放在Detail中
+func Invalid(err error) *Error {
+	e := BadRequest("请求参数错误")
+	e.Detail = err.Error()
+	return e
+}
+
+// WithCode 返回使用指定错误码的副本
+func (e *Error) WithCode(code Code) *Error {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// WithData 返回附带数据的副本
+func (e *Error) WithData(data any) *Error {
+	copied := *e
+	copied.Data = data
+	return &copied
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.text(e.Message)
+}
+
+// Localize 返回指定语言的提示，没有对应翻译时使用中文
+func (e *Error) Localize(lang Language) string {
+	message := e.Message
+	if lang == English {
+		if translated, ok := english[message]; ok {
+			message = translated
+		}
+	}
+	return e.text(message)
+}
+
+func (e *Error) text(message string) string {
+	if len(e.args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, e.args...)
+}
+
+// Body 错误响应体。code与旧版Response.Code一致为HTTP状态码，error为错误码
+type Body struct {
+	Code    int    `json:"code"`
+	Error   Code   `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+	Data    any    `json:"data"`
+}
+
+// Body 生成指定语言的响应体
+func (e *Error) Body(lang Language) Body {
+	return Body{
+		Code:    e.Status,
+		Error:   e.Code,
+		Message: e.Localize(lang),
+		Detail:  e.Detail,
+		Data:    e.Data,
+	}
+}
+
+var legacyStatus bool
+
+// SetLegacyStatus 开启后错误响应的HTTP状态码按旧版规则返回：除401和429外一律返回200，
+// 供仍只根据响应体code判断结果的旧客户端过渡使用
+func SetLegacyStatus(enabled bool) {
+	legacyStatus = enabled
+}
+
+// HTTPStatus 错误实际使用的HTTP状态码
+func (e *Error) HTTPStatus() int {
+	if legacyStatus && e.Status != http.StatusUnauthorized && e.Status != http.StatusTooManyRequests {
+		return http.StatusOK
+	}
+	return e.Status
+}
+
+// Abort 记录错误并中止请求，响应由错误渲染中间件统一输出
+func Abort(c *gin.Context, err *Error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,280 @@
+package apperr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language 错误提示的语言
+type Language string
+
+const (
+	Chinese Language = "zh"
+	English Language = "en"
+)
+
+// ParseLanguage 按Accept-Language的权重选择支持的语言，无法识别时使用中文
+func ParseLanguage(header string) Language {
+	type candidate struct {
+		lang Language
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		switch primary {
+		case "zh":
+			candidates = append(candidates, candidate{Chinese, q})
+		case "en":
+			candidates = append(candidates, candidate{English, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	if len(candidates) == 0 || candidates[0].q <= 0 {
+		return Chinese
+	}
+	return candidates[0].lang
+}
+
+// english 中文提示到英文提示的对照表，新增错误提示时需同步补充
+var english = map[string]string{
+	// 通用
+	"请求参数错误":       "Invalid request",
+	"服务器错误":        "Internal server error",
+	"接口不存在":        "Endpoint not found",
+	"请求过于频繁，请稍后再试": "Too many requests, please try again later",
+	"无效的分页参数":      "Invalid pagination parameters",
+	"无效的游标":        "Invalid cursor",
+	"无效的排序方式":      "Invalid sort order",
+	"无效的时间范围":      "Invalid time range",
+	"该排序方式不支持游标分页，请使用page参数": "This sort order does not support cursor pagination, use the page parameter",
+	"操作失败":      "Operation failed",
+	"没有需要修改的内容": "Nothing to update",
+
+	// 认证和账号
+	"未登录":        "Not logged in",
+	"请先登录":       "Please log in first",
+	"无效的token格式": "Invalid token format",
+	"无效的token":   "Invalid token",
+	"token已过期":   "Token expired",
+	"登录已失效":      "Session is no longer valid",
+	"无权限":        "Permission denied",
+	"账号已被禁用":     "Account has been disabled",
+	"用户不存在":      "User not found",
+	"用户名已存在":     "Username already exists",
+	"用户名或密码错误":   "Incorrect username or password",
+	"登录失败次数过多，请%d分钟后再试":  "Too many failed login attempts, please try again in %d minutes",
+	"密码加密失败":             "Failed to hash password",
+	"创建用户失败":             "Failed to create user",
+	"生成token失败":          "Failed to issue token",
+	"刷新令牌无效":             "Invalid refresh token",
+	"刷新令牌已过期，请重新登录":      "Refresh token expired, please log in again",
+	"登录状态异常，请重新登录":       "Session anomaly detected, please log in again",
+	"刷新令牌失败":             "Failed to refresh token",
+	"退出失败":               "Failed to log out",
+	"会话不存在":              "Session not found",
+	"无效的会话ID":            "Invalid session ID",
+	"获取登录设备失败":           "Failed to get signed-in devices",
+	"下线失败":               "Failed to sign out the device",
+	"原密码错误":              "Incorrect current password",
+	"修改密码失败":             "Failed to change password",
+	"请先设置邮箱":             "Please set an email address first",
+	"邮箱已验证":              "Email already verified",
+	"发送验证邮件失败":           "Failed to send verification email",
+	"验证链接无效或已过期":         "Verification link is invalid or expired",
+	"重置链接无效或已过期":         "Reset link is invalid or expired",
+	"重置密码失败":             "Failed to reset password",
+	"更新用户信息失败":           "Failed to update profile",
+	"获取用户信息失败":           "Failed to get profile",
+	"不支持的登录方式":           "Unsupported login method",
+	"发起授权失败":             "Failed to start authorization",
+	"授权已过期，请重新登录":        "Authorization expired, please log in again",
+	"第三方授权失败":            "Third-party authorization failed",
+	"登录失败":               "Login failed",
+	"绑定失败":               "Failed to link account",
+	"该第三方账号已绑定其他用户":      "This third-party account is linked to another user",
+	"获取绑定信息失败":           "Failed to get linked accounts",
+	"未绑定该第三方账号":          "This third-party account is not linked",
+	"请先设置密码再解除绑定":        "Please set a password before unlinking",
+	"解除绑定失败":             "Failed to unlink account",
+	"内容包含违规信息，请修改后再提交":   "Content contains prohibited information, please revise and resubmit",
+	"内容包含疑似违规信息，请修改后再提交": "Content may contain prohibited information, please revise and resubmit",
+
+	// 问答
+	"无效的问题ID":  "Invalid question ID",
+	"问题不存在":    "Question not found",
+	"创建问题失败":   "Failed to create question",
+	"获取问题列表失败": "Failed to get questions",
+	"获取问题详情失败": "Failed to get question",
+	"创建回答失败":   "Failed to create answer",
+	"获取相关问题失败": "Failed to get related questions",
+	"获取热门内容失败": "Failed to get trending content",
+	"已经点赞过了":   "Already liked",
+	"还没有点赞":    "Not liked yet",
+	"点赞失败":     "Failed to like",
+	"取消点赞失败":   "Failed to unlike",
+
+	// 评论
+	"无效的评论ID":  "Invalid comment ID",
+	"评论不存在":    "Comment not found",
+	"创建评论失败":   "Failed to create comment",
+	"获取评论列表失败": "Failed to get comments",
+	"获取评论失败":   "Failed to get comment",
+	"回复失败":     "Failed to reply",
+	"无权删除此评论":  "You cannot delete this comment",
+	"删除评论失败":   "Failed to delete comment",
+
+	// 笔记
+	"无效的笔记ID":  "Invalid note ID",
+	"笔记不存在":    "Note not found",
+	"创建笔记失败":   "Failed to create note",
+	"获取笔记列表失败": "Failed to get notes",
+	"获取笔记详情失败": "Failed to get note",
+	"获取分类列表失败": "Failed to get categories",
+
+	// 聊天
+	"无效的聊天ID":     "Invalid chat ID",
+	"聊天不存在":       "Chat not found",
+	"无权查看此聊天":     "You cannot view this chat",
+	"创建聊天会话失败":    "Failed to create chat",
+	"获取聊天记录失败":    "Failed to get chat history",
+	"保存消息失败":      "Failed to save message",
+	"群聊不存在":       "Group chat not found",
+	"不是群聊成员":      "You are not a member of this group chat",
+	"请先加入村落":      "Please join the village first",
+	"创建群聊失败":      "Failed to create group chat",
+	"获取群聊列表失败":    "Failed to get group chats",
+	"获取群聊失败":      "Failed to get group chat",
+	"获取成员列表失败":    "Failed to get members",
+	"加入聊天室失败":     "Failed to join chat room",
+	"退出群聊失败":      "Failed to leave group chat",
+	"该聊天室不支持邀请成员": "This chat room does not support invitations",
+	"邀请成员失败":      "Failed to invite members",
+	"标记已读失败":      "Failed to mark as read",
+
+	// 地球村
+	"无效的村落ID":      "Invalid village ID",
+	"村落不存在":        "Village not found",
+	"村落已关闭":        "Village is closed",
+	"获取村落列表失败":     "Failed to get villages",
+	"获取村落失败":       "Failed to get village",
+	"创建村落失败":       "Failed to create village",
+	"修改村落失败":       "Failed to update village",
+	"关闭村落失败":       "Failed to close village",
+	"已加入该村落":       "Already joined this village",
+	"未加入该村落":       "You have not joined this village",
+	"加入村落失败":       "Failed to join village",
+	"退出村落失败":       "Failed to leave village",
+	"无效的帖子ID":      "Invalid post ID",
+	"帖子不存在":        "Post not found",
+	"仅村落成员可发帖":     "Only village members can post",
+	"该村落不允许匿名发帖":   "Anonymous posts are not allowed in this village",
+	"发布帖子失败":       "Failed to publish post",
+	"获取帖子列表失败":     "Failed to get posts",
+	"无权编辑此帖子":      "You cannot edit this post",
+	"编辑帖子失败":       "Failed to edit post",
+	"无权删除此帖子":      "You cannot delete this post",
+	"删除帖子失败":       "Failed to delete post",
+	"无效的回复ID":      "Invalid reply ID",
+	"回复不存在":        "Reply not found",
+	"该村落不允许匿名回复":   "Anonymous replies are not allowed in this village",
+	"获取回复列表失败":     "Failed to get replies",
+	"无权编辑此回复":      "You cannot edit this reply",
+	"编辑回复失败":       "Failed to edit reply",
+	"匿名帖子不存在":      "Anonymous post not found",
+	"匿名回复不存在":      "Anonymous reply not found",
+	"记录审计日志失败":     "Failed to write audit log",
+	"仅村落管理员可执行此操作": "Only village moderators can perform this action",
+	"获取待审核帖子失败":    "Failed to get pending posts",
+	"帖子不在待审核状态":    "Post is not pending review",
+	"帖子正在等待平台审核":   "Post is awaiting platform review",
+	"审核失败":         "Failed to approve",
+	"驳回失败":         "Failed to reject",
+	"只能置顶已发布的帖子":   "Only published posts can be pinned",
+	"修改发帖策略失败":     "Failed to update posting policy",
+
+	// 搜索
+	"搜索关键词不能为空": "Search keyword must not be empty",
+	"不支持的搜索类型":  "Unsupported search type",
+	"不支持的统计窗口":  "Unsupported statistics window",
+	"搜索失败":      "Search failed",
+	"搜索问题失败":    "Failed to search questions",
+	"搜索笔记失败":    "Failed to search notes",
+	"获取搜索联想失败":  "Failed to get search suggestions",
+	"获取热搜失败":    "Failed to get hot searches",
+	"获取搜索历史失败":  "Failed to get search history",
+	"无效的搜索历史ID": "Invalid search history ID",
+	"搜索历史不存在":   "Search history not found",
+	"删除搜索历史失败":  "Failed to delete search history",
+	"清空搜索历史失败":  "Failed to clear search history",
+
+	// 举报和管理后台
+	"不支持的内容类型":       "Unsupported content type",
+	"无效的内容ID":        "Invalid content ID",
+	"内容不存在":          "Content not found",
+	"不能举报自己的内容":      "You cannot report your own content",
+	"你已举报过该内容，请等待处理": "You have already reported this content, please wait for review",
+	"举报失败":           "Failed to report",
+	"无效的举报ID":        "Invalid report ID",
+	"举报不存在":          "Report not found",
+	"该举报已处理":         "This report has already been handled",
+	"获取举报列表失败":       "Failed to get reports",
+	"获取举报记录失败":       "Failed to get report history",
+	"处理举报失败":         "Failed to handle report",
+	"内容已下架":          "Content has already been removed",
+	"下架失败":           "Failed to remove content",
+	"内容未被下架":         "Content has not been removed",
+	"该内容未被下架":        "Content has not been removed",
+	"恢复失败":           "Failed to restore content",
+	"获取操作记录失败":       "Failed to get operation logs",
+	"无效的用户ID":        "Invalid user ID",
+	"无权管理该用户":        "You cannot manage this user",
+	"无效的角色":          "Invalid role",
+	"推送票据无效或已过期":     "Stream ticket is invalid or expired",
+	"获取推送票据失败":       "Failed to issue stream ticket",
+	"不能修改自己的角色":      "You cannot change your own role",
+	"角色未变化":          "Role is unchanged",
+	"修改角色失败":         "Failed to change role",
+	"不能修改自己的账号状态":    "You cannot change your own account status",
+	"账号状态未变化":        "Account status is unchanged",
+	"修改账号状态失败":       "Failed to change account status",
+	"获取用户列表失败":       "Failed to get users",
+	"获取记录失败":         "Failed to get records",
+
+	// 通知和收藏
+	"无效的通知ID":          "Invalid notification ID",
+	"通知不存在":            "Notification not found",
+	"获取通知失败":           "Failed to get notifications",
+	"无效的Last-Event-ID": "Invalid Last-Event-ID",
+	"实时推送不可用":          "Live updates are unavailable",
+	"无效的收藏ID":          "Invalid bookmark ID",
+	"收藏不存在":            "Bookmark not found",
+	"已经收藏过了":           "Already bookmarked",
+	"该内容不可收藏":          "This content cannot be bookmarked",
+	"收藏失败":             "Failed to bookmark",
+	"获取收藏失败":           "Failed to get bookmarks",
+	"修改收藏失败":           "Failed to update bookmark",
+	"取消收藏失败":           "Failed to remove bookmark",
+	"该收藏已转为笔记":         "This bookmark has already been converted to a note",
+	"原内容已不可访问":         "The original content is no longer available",
+	"转为笔记失败":           "Failed to convert to note",
+	"无效的收藏夹ID":         "Invalid folder ID",
+	"收藏夹不存在":           "Folder not found",
+	"收藏夹名称已存在":         "Folder name already exists",
+	"收藏夹数量已达上限":        "Folder limit reached",
+	"创建收藏夹失败":          "Failed to create folder",
+	"修改收藏夹失败":          "Failed to update folder",
+	"删除收藏夹失败":          "Failed to delete folder",
+}
@@ -1,19 +1,30 @@
 package config
 
 import (
-	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server ServerConfig
-	MySQL  MySQLConfig
-	Redis  RedisConfig
+	Server    ServerConfig
+	MySQL     MySQLConfig
+	Redis     RedisConfig
+	Search    SearchConfig
+	Embedding EmbeddingConfig
+	JWT       JWTConfig
+	Admin     AdminConfig
+	Mail      MailConfig
+	RateLimit RateLimitConfig
+	OAuth     OAuthConfig
+	Filter    FilterConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Mode string
+	Port              string
+	Mode              string
+	LegacyErrorStatus bool // 错误响应按旧版规则返回HTTP 200，兼容只判断响应体code的旧客户端
 }
 
 type MySQLConfig struct {
@@ -31,11 +42,83 @@ type RedisConfig struct {
 	DB       int
 }
 
+type SearchConfig struct {
+	Engine string // mysql/memory
+}
+
+type EmbeddingConfig struct {
+	Provider           string // local/http
+	APIURL             string
+	APIKey             string
+	Model              string
+	DuplicateThreshold float64
+}
+
+type JWTConfig struct {
+	Secret       string            // 当前签名密钥
+	KeyID        string            // 当前密钥的kid
+	PreviousKeys map[string]string // 轮换下来的旧密钥（kid -> secret），仅用于验证
+	Issuer       string
+	Audience     string
+	AccessTTL    time.Duration
+	RefreshTTL   time.Duration
+}
+
+type AdminConfig struct {
+	Usernames []string // 启动时授予管理员角色的用户名
+}
+
+type MailConfig struct {
+	SMTPHost    string // 为空时不发送邮件，只打印到日志
+	SMTPPort    string
+	Username    string
+	Password    string
+	From        string
+	LinkBaseURL string // 邮件中验证和重置链接指向的前端地址
+}
+
+type RateLimitConfig struct {
+	Enabled bool // 关闭后不限流，也不锁定登录失败的账号
+}
+
+type FilterConfig struct {
+	Enabled           bool
+	WordsFile         string   // 敏感词表文件，每行一个词，可用“词|reject”指定处理方式
+	Words             []string // 额外的敏感词，使用DefaultAction
+	DefaultAction     string   // 未指定处理方式的词条：mask/hold/reject
+	MaxLinks          int      // 超过该链接数转人工审核，0表示不限制
+	BlockedDomains    []string // 包含这些域名的链接直接拒绝
+	MaxRepeat         int      // 同一字符连续出现超过该次数转人工审核
+	MaxDuplicateLines int      // 同一行出现超过该次数转人工审核
+	ClassifierURL     string   // 外部分类服务地址，为空时不调用
+	ClassifierAPIKey  string
+	ClassifierTimeout time.Duration
+}
+
+type OAuthConfig struct {
+	Providers []OAuthProviderConfig
+}
+
+// OAuthProviderConfig 第三方登录配置。配置Issuer时按OIDC自动发现端点，
+// 否则需要配置AuthURL、TokenURL和UserInfoURL
+type OAuthProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       []string
+}
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Mode: getEnv("SERVER_MODE", "debug"),
+			Port:              getEnv("SERVER_PORT", "8080"),
+			Mode:              getEnv("SERVER_MODE", "debug"),
+			LegacyErrorStatus: getEnvBool("LEGACY_ERROR_STATUS", false),
 		},
 		MySQL: MySQLConfig{
 			Host:     getEnv("MYSQL_HOST", "localhost"),
@@ -48,7 +131,56 @@ func Load() *Config {
 			Host:     getEnv("REDIS_HOST", "localhost"),
 			Port:     getEnv("REDIS_PORT", "6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       0,
+			DB:       getEnvInt("REDIS_DB", 0),
+		},
+		Search: SearchConfig{
+			Engine: getEnv("SEARCH_ENGINE", "mysql"),
+		},
+		Embedding: EmbeddingConfig{
+			Provider:           getEnv("EMBEDDING_PROVIDER", "local"),
+			APIURL:             getEnv("EMBEDDING_API_URL", "https://api.openai.com/v1/embeddings"),
+			APIKey:             getEnv("EMBEDDING_API_KEY", ""),
+			Model:              getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+			DuplicateThreshold: getEnvFloat("EMBEDDING_DUPLICATE_THRESHOLD", 0.85),
+		},
+		JWT: JWTConfig{
+			Secret:       getEnv("JWT_SECRET", ""),
+			KeyID:        getEnv("JWT_KEY_ID", "default"),
+			PreviousKeys: parseKeys(getEnv("JWT_PREVIOUS_KEYS", "")),
+			Issuer:       getEnv("JWT_ISSUER", "ai-egg"),
+			Audience:     getEnv("JWT_AUDIENCE", "ai-egg-app"),
+			AccessTTL:    getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL:   getEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		},
+		Admin: AdminConfig{
+			Usernames: parseList(getEnv("ADMIN_USERNAMES", "")),
+		},
+		Mail: MailConfig{
+			SMTPHost:    getEnv("SMTP_HOST", ""),
+			SMTPPort:    getEnv("SMTP_PORT", "587"),
+			Username:    getEnv("SMTP_USERNAME", ""),
+			Password:    getEnv("SMTP_PASSWORD", ""),
+			From:        getEnv("MAIL_FROM", "noreply@ai-egg.local"),
+			LinkBaseURL: getEnv("MAIL_LINK_BASE_URL", "http://localhost:5173"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnvBool("RATE_LIMIT_ENABLED", true),
+		},
+		OAuth: OAuthConfig{
+			Providers: loadOAuthProviders(),
+		},
+		Filter: FilterConfig{
+			Enabled:           getEnvBool("FILTER_ENABLED", true),
+			WordsFile:         getEnv("FILTER_WORDS_FILE", ""),
+			Words:             parseList(getEnv("FILTER_WORDS", "")),
+			DefaultAction:     getEnv("FILTER_DEFAULT_ACTION", "mask"),
+			MaxLinks:          getEnvInt("FILTER_MAX_LINKS", 3),
+			BlockedDomains:    parseList(getEnv("FILTER_BLOCKED_DOMAINS", "")),
+			MaxRepeat:         getEnvInt("FILTER_MAX_REPEAT", 20),
+			MaxDuplicateLines: getEnvInt("FILTER_MAX_DUPLICATE_LINES", 3),
+			ClassifierURL:     getEnv("FILTER_CLASSIFIER_URL", ""),
+			ClassifierAPIKey:  getEnv("FILTER_CLASSIFIER_API_KEY", ""),
+			ClassifierTimeout: getEnvDuration("FILTER_CLASSIFIER_TIMEOUT", 2*time.Second),
 		},
 	}
 }
@@ -61,10 +193,82 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
-func InitDB(cfg *Config) {
-	initDB(cfg)
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// loadOAuthProviders 读取OAUTH_PROVIDERS中列出的第三方登录配置，
+// 每个提供方使用OAUTH_<NAME>_CLIENT_ID等环境变量
+func loadOAuthProviders() []OAuthProviderConfig {
+	var providers []OAuthProviderConfig
+	for _, name := range parseList(getEnv("OAUTH_PROVIDERS", "")) {
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OAuthProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			AuthURL:      getEnv(prefix+"AUTH_URL", ""),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  getEnv(prefix+"USERINFO_URL", ""),
+			Scopes:       parseList(getEnv(prefix+"SCOPES", "")),
+		})
+	}
+	return providers
+}
+
+// parseList 解析逗号分隔的列表
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
-func InitRedis(cfg *Config) {
-	log.Println("Redis initialized")
+// parseKeys 解析"kid1:secret1,kid2:secret2"格式的密钥列表
+func parseKeys(value string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && kid != "" && secret != "" {
+			keys[kid] = secret
+		}
+	}
+	return keys
+}
+
+func InitDB(cfg *Config) {
+	initDB(cfg)
 }
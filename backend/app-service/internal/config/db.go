@@ -19,6 +19,11 @@ func GetDB() *gorm.DB {
 	return db
 }
 
+// SetDB 使用已建立的数据库连接，测试中用于替换为SQLite等内存数据库
+func SetDB(conn *gorm.DB) {
+	db = conn
+}
+
 // InitDB 初始化数据库连接
 func initDB(cfg *Config) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var redisClient *redis.Client
+
+// GetRedis 获取Redis客户端，Redis不可用时返回nil，调用方需自行降级
+func GetRedis() *redis.Client {
+	return redisClient
+}
+
+// InitRedis 初始化Redis连接，连接失败时只记录日志
+func InitRedis(cfg *Config) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("Failed to connect to Redis, falling back to in-memory stores: %v", err)
+		client.Close()
+		return
+	}
+
+	redisClient = client
+	log.Println("Redis connected successfully")
+}
@@ -0,0 +1,151 @@
+// Package embedding 提供文本向量化和基于向量的相似内容检索
+package embedding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"time"
+
+	"ai-egg/app-service/internal/search"
+)
+
+// Provider 文本向量化接口，返回的向量需已做L2归一化
+type Provider interface {
+	// Name 模型标识，不同模型生成的向量不可相互比较
+	Name() string
+	// Embed 批量生成文本向量
+	Embed(texts []string) ([][]float32, error)
+}
+
+// HashingProvider 基于特征哈希的本地向量化实现，无需外部服务，适合离线部署和测试
+type HashingProvider struct {
+	dim int
+}
+
+// NewHashingProvider 创建特征哈希向量化实现，dim为向量维度
+func NewHashingProvider(dim int) *HashingProvider {
+	return &HashingProvider{dim: dim}
+}
+
+func (p *HashingProvider) Name() string {
+	return fmt.Sprintf("hashing-%d", p.dim)
+}
+
+func (p *HashingProvider) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		counts := make(map[string]int)
+		for _, token := range search.Tokenize(text) {
+			counts[token]++
+		}
+
+		vec := make([]float32, p.dim)
+		for token, count := range counts {
+			h := fnv.New64a()
+			h.Write([]byte(token))
+			sum := h.Sum64()
+			// 用哈希的最高位决定符号，降低哈希冲突带来的偏差
+			sign := float32(1)
+			if sum>>63 == 1 {
+				sign = -1
+			}
+			// 词频取对数，避免高频词主导向量
+			vec[sum%uint64(p.dim)] += sign * float32(1+math.Log(float64(count)))
+		}
+		vectors[i] = normalize(vec)
+	}
+	return vectors, nil
+}
+
+// HTTPProvider 调用兼容OpenAI embeddings接口的外部服务
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewHTTPProvider 创建外部向量化服务客户端，url为完整的embeddings接口地址
+func NewHTTPProvider(url, apiKey, model string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return p.model
+}
+
+func (p *HTTPProvider) Embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding service returned %d vectors for %d inputs", len(result.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding service returned invalid index %d", item.Index)
+		}
+		vectors[item.Index] = normalize(item.Embedding)
+	}
+	return vectors, nil
+}
+
+// normalize L2归一化，归一化后向量点积即为余弦相似度
+func normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vec
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
@@ -0,0 +1,230 @@
+package embedding
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"sync"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 存储向量的内容类型
+const (
+	TypeQuestion = "question"
+	TypeNote     = "note"
+)
+
+// Match 相似内容
+type Match struct {
+	TargetType string  `json:"target_type"`
+	TargetID   uint    `json:"target_id"`
+	Score      float64 `json:"score"`
+}
+
+type vectorKey struct {
+	targetType string
+	id         uint
+}
+
+// Store 向量存储，向量持久化在数据库中并在内存中缓存以便暴力检索
+type Store struct {
+	db       *gorm.DB
+	provider Provider
+
+	// DuplicateThreshold 判定为重复内容的相似度阈值
+	DuplicateThreshold float64
+
+	mu      sync.RWMutex
+	vectors map[vectorKey][]float32
+}
+
+// NewStore 创建向量存储
+func NewStore(db *gorm.DB, provider Provider) *Store {
+	return &Store{
+		db:                 db,
+		provider:           provider,
+		DuplicateThreshold: 0.85,
+		vectors:            make(map[vectorKey][]float32),
+	}
+}
+
+var store *Store
+
+// Init 设置全局向量存储
+func Init(s *Store) {
+	store = s
+}
+
+// GetStore 获取全局向量存储，未初始化时返回nil
+func GetStore() *Store {
+	return store
+}
+
+// Embed 生成单条文本的向量
+func (s *Store) Embed(text string) ([]float32, error) {
+	vectors, err := s.provider.Embed([]string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// Save 保存内容向量
+func (s *Store) Save(targetType string, id uint, vec []float32) error {
+	row := model.Embedding{
+		TargetType: targetType,
+		TargetID:   id,
+		Model:      s.provider.Name(),
+		Dim:        len(vec),
+		Vector:     encodeVector(vec),
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model", "dim", "vector", "updated_at"}),
+	}).Create(&row).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.vectors[vectorKey{targetType, id}] = vec
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete 删除内容向量
+func (s *Store) Delete(targetType string, id uint) error {
+	s.mu.Lock()
+	delete(s.vectors, vectorKey{targetType, id})
+	s.mu.Unlock()
+
+	return s.db.Where("target_type = ? AND target_id = ?", targetType, id).Delete(&model.Embedding{}).Error
+}
+
+// Vector 获取已保存的内容向量
+func (s *Store) Vector(targetType string, id uint) ([]float32, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vec, ok := s.vectors[vectorKey{targetType, id}]
+	return vec, ok
+}
+
+// Nearest 返回与vec最相似的内容，按相似度降序排列，exclude中的ID不参与比较
+func (s *Store) Nearest(targetType string, vec []float32, limit int, minScore float64, exclude ...uint) []Match {
+	skip := make(map[uint]bool, len(exclude))
+	for _, id := range exclude {
+		skip[id] = true
+	}
+
+	s.mu.RLock()
+	var matches []Match
+	for key, other := range s.vectors {
+		if key.targetType != targetType || skip[key.id] || len(other) != len(vec) {
+			continue
+		}
+		if score := dot(vec, other); score >= minScore {
+			matches = append(matches, Match{TargetType: key.targetType, TargetID: key.id, Score: score})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].TargetID > matches[j].TargetID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Load 从数据库加载当前模型生成的全部向量，并为缺少向量的问题和笔记补充生成
+func (s *Store) Load() error {
+	var rows []model.Embedding
+	if err := s.db.Where("model = ?", s.provider.Name()).FindInBatches(&rows, 500, func(tx *gorm.DB, batch int) error {
+		s.mu.Lock()
+		for _, row := range rows {
+			s.vectors[vectorKey{row.TargetType, row.TargetID}] = decodeVector(row.Vector)
+		}
+		s.mu.Unlock()
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var questions []model.Question
+	if err := s.db.Where("status = ?", 1).FindInBatches(&questions, 100, func(tx *gorm.DB, batch int) error {
+		for _, q := range questions {
+			if _, ok := s.Vector(TypeQuestion, q.ID); ok {
+				continue
+			}
+			if err := s.embedAndSave(TypeQuestion, q.ID, QuestionText(q.Title, q.Content)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var notes []model.Note
+	return s.db.Where("status = ?", 1).FindInBatches(&notes, 100, func(tx *gorm.DB, batch int) error {
+		for _, n := range notes {
+			if _, ok := s.Vector(TypeNote, n.ID); ok {
+				continue
+			}
+			if err := s.embedAndSave(TypeNote, n.ID, NoteText(n.Title, n.Content)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (s *Store) embedAndSave(targetType string, id uint, text string) error {
+	vec, err := s.Embed(text)
+	if err != nil {
+		return err
+	}
+	return s.Save(targetType, id, vec)
+}
+
+// QuestionText 生成问题的向量化文本，标题重复一次以提高权重
+func QuestionText(title, content string) string {
+	return title + "\n" + title + "\n" + content
+}
+
+// NoteText 生成笔记的向量化文本
+func NoteText(title, content string) string {
+	return title + "\n" + content
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func encodeVector(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec
+}
@@ -0,0 +1,123 @@
+package filter
+
+import "unicode"
+
+// Matcher Aho–Corasick多模式匹配器。匹配前统一大小写和全角字符，
+// 并跳过空白和标点，使“敏 感 词”“敏-感-词”等变体也能命中
+type Matcher struct {
+	nodes   []acNode
+	lengths []int // 各模式去除忽略字符后的长度
+}
+
+type acNode struct {
+	next   map[rune]int32
+	fail   int32
+	output []int // 以该节点结尾的模式下标，包含沿失败链可达的模式
+}
+
+// Match 一次命中，Start和End为原文中的rune下标，区间为[Start, End)
+type Match struct {
+	Pattern int
+	Start   int
+	End     int
+}
+
+// NewMatcher 由模式列表构建匹配器，空模式被忽略
+func NewMatcher(patterns []string) *Matcher {
+	m := &Matcher{
+		nodes:   []acNode{{next: make(map[rune]int32)}},
+		lengths: make([]int, len(patterns)),
+	}
+
+	for i, pattern := range patterns {
+		cur := int32(0)
+		for _, r := range pattern {
+			if isNoise(r) {
+				continue
+			}
+			r = fold(r)
+			next, ok := m.nodes[cur].next[r]
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, acNode{next: make(map[rune]int32)})
+				m.nodes[cur].next[r] = next
+			}
+			cur = next
+			m.lengths[i]++
+		}
+		if cur != 0 {
+			m.nodes[cur].output = append(m.nodes[cur].output, i)
+		}
+	}
+
+	// 按层次遍历构建失败指针
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if target, ok := m.nodes[fail].next[r]; ok && target != child {
+				m.nodes[child].fail = target
+			}
+			m.nodes[child].output = append(m.nodes[child].output, m.nodes[m.nodes[child].fail].output...)
+			queue = append(queue, child)
+		}
+	}
+	return m
+}
+
+// FindAll 返回文本中的全部命中，同一位置可能命中多个模式
+func (m *Matcher) FindAll(text string) []Match {
+	runes := []rune(text)
+
+	// positions记录参与匹配的字符在原文中的下标，用于还原命中区间
+	positions := make([]int, 0, len(runes))
+	var matches []Match
+	cur := int32(0)
+	for i, r := range runes {
+		if isNoise(r) {
+			continue
+		}
+		positions = append(positions, i)
+		r = fold(r)
+
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if next, ok := m.nodes[cur].next[r]; ok {
+			cur = next
+		}
+
+		for _, p := range m.nodes[cur].output {
+			start := positions[len(positions)-m.lengths[p]]
+			matches = append(matches, Match{Pattern: p, Start: start, End: i + 1})
+		}
+	}
+	return matches
+}
+
+// fold 统一大小写，并将全角ASCII字符转为半角
+func fold(r rune) rune {
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFEE0
+	}
+	return unicode.ToLower(r)
+}
+
+// isNoise 匹配时忽略的字符：空白、标点和符号
+func isNoise(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatcherFindAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		text     string
+		want     []Match
+	}{
+		{
+			name:     "overlapping patterns",
+			patterns: []string{"he", "she", "his", "hers"},
+			text:     "ushers",
+			want:     []Match{{Pattern: 1, Start: 1, End: 4}, {Pattern: 0, Start: 2, End: 4}, {Pattern: 3, Start: 2, End: 6}},
+		},
+		{
+			name:     "pattern inside another",
+			patterns: []string{"敏感词", "感"},
+			text:     "有敏感词",
+			want:     []Match{{Pattern: 1, Start: 2, End: 3}, {Pattern: 0, Start: 1, End: 4}},
+		},
+		{
+			name:     "repeated matches",
+			patterns: []string{"abc"},
+			text:     "abcxabc",
+			want:     []Match{{Pattern: 0, Start: 0, End: 3}, {Pattern: 0, Start: 4, End: 7}},
+		},
+		{
+			name:     "noise between characters",
+			patterns: []string{"敏感词"},
+			text:     "这是敏 感-词。",
+			want:     []Match{{Pattern: 0, Start: 2, End: 7}},
+		},
+		{
+			name:     "case and full width",
+			patterns: []string{"vx"},
+			text:     "加ＶＸ或VX",
+			want:     []Match{{Pattern: 0, Start: 1, End: 3}, {Pattern: 0, Start: 4, End: 6}},
+		},
+		{
+			name:     "empty pattern ignored",
+			patterns: []string{"", " - "},
+			text:     "任意文本",
+		},
+		{
+			name:     "whitespace inside word",
+			patterns: []string{"abc"},
+			text:     "ab c",
+			want:     []Match{{Pattern: 0, Start: 0, End: 4}},
+		},
+		{
+			name:     "no match",
+			patterns: []string{"abc"},
+			text:     "acb",
+		},
+		{
+			name:     "failure link across patterns",
+			patterns: []string{"abcd", "bce"},
+			text:     "abce",
+			want:     []Match{{Pattern: 1, Start: 1, End: 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMatcher(tt.patterns).FindAll(tt.text)
+			sortMatches(got)
+			sortMatches(tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FindAll(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].End != matches[j].End {
+			return matches[i].End < matches[j].End
+		}
+		return matches[i].Pattern < matches[j].Pattern
+	})
+}
@@ -0,0 +1,142 @@
+// Package filter 提供用户提交内容的过滤流水线：敏感词匹配、链接和灌水检测，以及可选的外部分类服务
+package filter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Action 过滤结果的处理方式，数值越大越严格
+type Action int
+
+const (
+	ActionPass   Action = iota // 放行
+	ActionMask                 // 命中部分替换为*后放行
+	ActionHold                 // 保存但暂不展示，等待人工审核
+	ActionReject               // 拒绝提交
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMask:
+		return "mask"
+	case ActionHold:
+		return "hold"
+	case ActionReject:
+		return "reject"
+	}
+	return "pass"
+}
+
+// ParseAction 解析配置中的处理方式
+func ParseAction(s string) (Action, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "pass":
+		return ActionPass, nil
+	case "mask":
+		return ActionMask, nil
+	case "hold":
+		return ActionHold, nil
+	case "reject":
+		return ActionReject, nil
+	}
+	return ActionPass, fmt.Errorf("unknown filter action %q", s)
+}
+
+// Result 过滤结果
+type Result struct {
+	Action  Action
+	Text    string   // 处理后的文本，命中mask规则的部分已替换
+	Reasons []string // 命中原因，用于审核记录
+}
+
+// escalate 提升处理方式并记录原因，已有更严格的处理方式时只记录原因
+func (r *Result) escalate(action Action, reason string) {
+	if action > r.Action {
+		r.Action = action
+	}
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// Stage 流水线中的一个检查步骤，可修改r.Text或提升r.Action
+type Stage interface {
+	Check(ctx context.Context, r *Result) error
+}
+
+// Filter 按顺序执行各检查步骤，结果为拒绝时不再执行后续步骤
+type Filter struct {
+	stages []Stage
+}
+
+// New 创建内容过滤流水线
+func New(stages ...Stage) *Filter {
+	return &Filter{stages: stages}
+}
+
+// Check 过滤一段文本
+func (f *Filter) Check(ctx context.Context, text string) (Result, error) {
+	r := Result{Action: ActionPass, Text: text}
+	for _, stage := range f.stages {
+		if err := stage.Check(ctx, &r); err != nil {
+			return r, err
+		}
+		if r.Action == ActionReject {
+			break
+		}
+	}
+	return r, nil
+}
+
+var global *Filter
+
+// Init 设置全局内容过滤器
+func Init(f *Filter) {
+	global = f
+}
+
+// Get 获取全局内容过滤器，未启用时返回nil
+func Get() *Filter {
+	return global
+}
+
+// Rule 敏感词规则
+type Rule struct {
+	Word   string
+	Action Action
+}
+
+// LoadRules 从词表文件读取规则。每行一个词，可用“|”指定处理方式（如“代开发票|reject”），
+// 未指定时使用defaultAction；空行和#开头的行被忽略
+func LoadRules(path string, defaultAction Action) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []Rule
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule := Rule{Word: text, Action: defaultAction}
+		if i := strings.LastIndex(text, "|"); i >= 0 {
+			action, err := ParseAction(text[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			rule = Rule{Word: strings.TrimSpace(text[:i]), Action: action}
+		}
+		if rule.Word != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
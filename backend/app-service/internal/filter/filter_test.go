@@ -0,0 +1,153 @@
+package filter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWordStage(t *testing.T) {
+	rules := []Rule{
+		{Word: "笨蛋", Action: ActionMask},
+		{Word: "加微信", Action: ActionHold},
+		{Word: "代开发票", Action: ActionReject},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		action  Action
+		output  string
+		reasons []string
+	}{
+		{name: "pass", text: "正常内容", action: ActionPass, output: "正常内容"},
+		{name: "mask", text: "你是笨蛋吗", action: ActionMask, output: "你是**吗", reasons: []string{"word:笨蛋"}},
+		{name: "mask keeps spaces", text: "笨 蛋-笨蛋", action: ActionMask, output: "* *-**", reasons: []string{"word:笨蛋"}},
+		{name: "hold over mask", text: "笨蛋，加微信", action: ActionHold, output: "**，加微信", reasons: []string{"word:笨蛋", "word:加微信"}},
+		{name: "reject over hold", text: "加微信代开发票", action: ActionReject, output: "加微信代开发票", reasons: []string{"word:加微信", "word:代开发票"}},
+		{name: "reject before hold", text: "代开发票加微信", action: ActionReject, output: "代开发票加微信", reasons: []string{"word:代开发票", "word:加微信"}},
+	}
+	stage := NewWordStage(rules)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{Text: tt.text}
+			if err := stage.Check(context.Background(), &r); err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if r.Action != tt.action || r.Text != tt.output || !reflect.DeepEqual(r.Reasons, tt.reasons) {
+				t.Fatalf("got %v %q %v, want %v %q %v", r.Action, r.Text, r.Reasons, tt.action, tt.output, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestLinkStage(t *testing.T) {
+	stage := &LinkStage{MaxLinks: 1, BlockedDomains: []string{"bad.com"}}
+	tests := []struct {
+		name   string
+		text   string
+		action Action
+	}{
+		{name: "no link", text: "没有链接", action: ActionPass},
+		{name: "one link", text: "见 https://example.com/a", action: ActionPass},
+		{name: "too many links", text: "https://a.com 和 www.b.com", action: ActionHold},
+		{name: "blocked subdomain", text: "http://x.bad.com/p", action: ActionReject},
+		{name: "similar domain allowed", text: "http://notbad.com", action: ActionPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{Text: tt.text}
+			if err := stage.Check(context.Background(), &r); err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if r.Action != tt.action {
+				t.Fatalf("action = %v, want %v (%v)", r.Action, tt.action, r.Reasons)
+			}
+		})
+	}
+}
+
+func TestSpamStage(t *testing.T) {
+	stage := &SpamStage{MaxRepeat: 3, MaxDuplicateLines: 2}
+	tests := []struct {
+		name   string
+		text   string
+		action Action
+	}{
+		{name: "normal", text: "哈哈哈，好的", action: ActionPass},
+		{name: "repeated characters", text: "哈哈哈哈", action: ActionHold},
+		{name: "spaces do not count", text: "a    b", action: ActionPass},
+		{name: "duplicate lines", text: "顶\n顶\n 顶 ", action: ActionHold},
+		{name: "blank lines ignored", text: "一\n\n\n\n二", action: ActionPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{Text: tt.text}
+			if err := stage.Check(context.Background(), &r); err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if r.Action != tt.action {
+				t.Fatalf("action = %v, want %v (%v)", r.Action, tt.action, r.Reasons)
+			}
+		})
+	}
+}
+
+// 流水线取各步骤中最严格的处理方式，拒绝后不再执行后续步骤
+func TestFilterPrecedence(t *testing.T) {
+	words := NewWordStage([]Rule{{Word: "笨蛋", Action: ActionMask}, {Word: "代开发票", Action: ActionReject}})
+	links := &LinkStage{MaxLinks: 1}
+	spam := &SpamStage{MaxRepeat: 3}
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		text    string
+		action  Action
+		output  string
+		reasons []string
+	}{
+		{
+			name:    "hold from later stage over mask",
+			filter:  New(words, links),
+			text:    "笨蛋 https://a.com https://b.com",
+			action:  ActionHold,
+			output:  "** https://a.com https://b.com",
+			reasons: []string{"word:笨蛋", "links:2"},
+		},
+		{
+			name:    "mask after hold keeps hold",
+			filter:  New(links, words),
+			text:    "https://a.com https://b.com 笨蛋",
+			action:  ActionHold,
+			output:  "https://a.com https://b.com **",
+			reasons: []string{"links:2", "word:笨蛋"},
+		},
+		{
+			name:    "reject stops later stages",
+			filter:  New(words, spam),
+			text:    "代开发票啊啊啊啊",
+			action:  ActionReject,
+			output:  "代开发票啊啊啊啊",
+			reasons: []string{"word:代开发票"},
+		},
+		{
+			name:    "reject after hold",
+			filter:  New(spam, words),
+			text:    "啊啊啊啊代开发票",
+			action:  ActionReject,
+			output:  "啊啊啊啊代开发票",
+			reasons: []string{"spam:repeat", "word:代开发票"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.filter.Check(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if r.Action != tt.action || r.Text != tt.output || !reflect.DeepEqual(r.Reasons, tt.reasons) {
+				t.Fatalf("got %v %q %v, want %v %q %v", r.Action, r.Text, r.Reasons, tt.action, tt.output, tt.reasons)
+			}
+		})
+	}
+}
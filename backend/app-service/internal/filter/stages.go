@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WordStage 敏感词检查，使用Aho–Corasick一次扫描匹配全部词条
+type WordStage struct {
+	rules   []Rule
+	matcher *Matcher
+}
+
+// NewWordStage 由敏感词规则创建检查步骤
+func NewWordStage(rules []Rule) *WordStage {
+	words := make([]string, len(rules))
+	for i, rule := range rules {
+		words[i] = rule.Word
+	}
+	return &WordStage{rules: rules, matcher: NewMatcher(words)}
+}
+
+func (s *WordStage) Check(ctx context.Context, r *Result) error {
+	matches := s.matcher.FindAll(r.Text)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	runes := []rune(r.Text)
+	masked := false
+	seen := make(map[int]bool)
+	for _, m := range matches {
+		rule := s.rules[m.Pattern]
+		if rule.Action == ActionMask {
+			for i := m.Start; i < m.End; i++ {
+				if !unicode.IsSpace(runes[i]) {
+					runes[i] = '*'
+				}
+			}
+			masked = true
+		}
+		if !seen[m.Pattern] {
+			seen[m.Pattern] = true
+			r.escalate(rule.Action, "word:"+rule.Word)
+		}
+	}
+	if masked {
+		r.Text = string(runes)
+	}
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'，。）)]+`)
+
+// LinkStage 链接检查：链接过多时转人工审核，包含屏蔽域名时拒绝
+type LinkStage struct {
+	MaxLinks       int      // 允许的最大链接数，0表示不限制
+	BlockedDomains []string // 屏蔽的域名，同时匹配其子域名
+}
+
+func (s *LinkStage) Check(ctx context.Context, r *Result) error {
+	links := linkPattern.FindAllString(r.Text, -1)
+	if len(links) == 0 {
+		return nil
+	}
+
+	for _, link := range links {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, domain := range s.BlockedDomains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				r.escalate(ActionReject, "domain:"+domain)
+				return nil
+			}
+		}
+	}
+
+	if s.MaxLinks > 0 && len(links) > s.MaxLinks {
+		r.escalate(ActionHold, fmt.Sprintf("links:%d", len(links)))
+	}
+	return nil
+}
+
+// SpamStage 灌水检查：同一字符连续重复或同一行反复出现时转人工审核
+type SpamStage struct {
+	MaxRepeat         int // 同一字符最多连续出现的次数，0表示不检查
+	MaxDuplicateLines int // 同一行最多出现的次数，0表示不检查
+}
+
+func (s *SpamStage) Check(ctx context.Context, r *Result) error {
+	if s.MaxRepeat > 0 {
+		var prev rune
+		run := 0
+		for _, c := range r.Text {
+			if c == prev && !unicode.IsSpace(c) {
+				run++
+			} else {
+				prev, run = c, 1
+			}
+			if run > s.MaxRepeat {
+				r.escalate(ActionHold, "spam:repeat")
+				break
+			}
+		}
+	}
+
+	if s.MaxDuplicateLines > 0 {
+		counts := make(map[string]int)
+		for _, line := range strings.Split(r.Text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			counts[line]++
+			if counts[line] > s.MaxDuplicateLines {
+				r.escalate(ActionHold, "spam:duplicate_lines")
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Verdict 外部分类服务的判定结果
+type Verdict struct {
+	Action Action
+	Label  string
+	Score  float64
+}
+
+// Classifier 外部内容分类服务
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Verdict, error)
+}
+
+// ClassifierStage 调用外部分类服务。服务不可用时放行，避免影响正常发布
+type ClassifierStage struct {
+	Classifier Classifier
+	Timeout    time.Duration
+}
+
+func (s *ClassifierStage) Check(ctx context.Context, r *Result) error {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	verdict, err := s.Classifier.Classify(ctx, r.Text)
+	if err != nil {
+		log.Printf("Content classifier failed: %v", err)
+		return nil
+	}
+	if verdict.Action > ActionPass {
+		r.escalate(verdict.Action, fmt.Sprintf("classifier:%s(%.2f)", verdict.Label, verdict.Score))
+	}
+	return nil
+}
+
+// HTTPClassifier 通过HTTP调用外部分类服务。
+// 请求体为{"text": "..."}，响应体为{"action": "pass|mask|hold|reject", "label": "...", "score": 0.9}
+type HTTPClassifier struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPClassifier 创建外部分类服务客户端
+func NewHTTPClassifier(url, apiKey string) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *HTTPClassifier) Classify(ctx context.Context, text string) (Verdict, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Action string  `json:"action"`
+		Label  string  `json:"label"`
+		Score  float64 `json:"score"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Verdict{}, err
+	}
+	action, err := ParseAction(result.Action)
+	if err != nil {
+		return Verdict{}, err
+	}
+	return Verdict{Action: action, Label: result.Label, Score: result.Score}, nil
+}
@@ -0,0 +1,392 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RemoveContentRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type RestoreContentRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+var (
+	errUnknownContentType = errors.New("unknown content type")
+	errContentNotFound    = errors.New("content not found")
+	errContentState       = errors.New("content state unchanged")
+)
+
+// contentModel 返回内容类型对应的模型，用于按类型读写status字段
+func contentModel(targetType string) (interface{}, error) {
+	switch targetType {
+	case model.TargetTypeQuestion:
+		return &model.Question{}, nil
+	case model.TargetTypeAnswer:
+		return &model.Answer{}, nil
+	case model.TargetTypeNote:
+		return &model.Note{}, nil
+	case model.TargetTypePost:
+		return &model.Post{}, nil
+	case model.TargetTypeComment:
+		return &model.Comment{}, nil
+	case model.TargetTypeMessage:
+		return &model.Message{}, nil
+	}
+	return nil, errUnknownContentType
+}
+
+// contentInfo 内容的作者、状态和正文
+type contentInfo struct {
+	OwnerID uint
+	Status  int
+	Text    string
+}
+
+// loadContent 读取内容的作者、状态和正文，作者已删除的内容视为不存在
+func loadContent(db *gorm.DB, targetType string, id uint) (contentInfo, error) {
+	var err error
+	var info contentInfo
+	switch targetType {
+	case model.TargetTypeQuestion:
+		var question model.Question
+		err = db.First(&question, id).Error
+		info = contentInfo{question.AuthorID, question.Status, question.Title + "\n" + question.Content}
+	case model.TargetTypeAnswer:
+		var answer model.Answer
+		err = db.First(&answer, id).Error
+		info = contentInfo{answer.AuthorID, answer.Status, answer.Content}
+	case model.TargetTypeNote:
+		var note model.Note
+		err = db.First(&note, id).Error
+		info = contentInfo{note.AuthorID, note.Status, note.Title + "\n" + note.Content}
+	case model.TargetTypePost:
+		var post model.Post
+		err = db.First(&post, id).Error
+		info = contentInfo{post.AuthorID, post.Status, post.Content}
+	case model.TargetTypeComment:
+		var comment model.Comment
+		err = db.First(&comment, id).Error
+		info = contentInfo{comment.AuthorID, comment.Status, comment.Content}
+	case model.TargetTypeMessage:
+		var message model.Message
+		err = db.First(&message, id).Error
+		info = contentInfo{message.SenderID, message.Status, message.Content}
+	default:
+		return info, errUnknownContentType
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return info, errContentNotFound
+	}
+	return info, err
+}
+
+// RemoveContent 后台下架任意问题、回答、笔记、帖子、评论或聊天消息
+func RemoveContent(c *gin.Context) {
+	var req RemoveContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	targetType, id, ok := contentParams(c)
+	if !ok {
+		return
+	}
+
+	err := config.GetDB().Transaction(func(tx *gorm.DB) error {
+		prev, err := hideContent(tx, targetType, id)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.AdminLog{
+			OperatorID: c.GetUint("userID"),
+			Action:     model.AdminActionRemoveContent,
+			TargetType: targetType,
+			TargetID:   id,
+			PrevStatus: prev,
+			Reason:     req.Reason,
+		}).Error
+	})
+	if err != nil {
+		respondContentError(c, err, "内容已下架", "下架失败")
+		return
+	}
+
+	syncContentIndex(config.GetDB(), targetType, id)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已下架",
+		Data:    nil,
+	})
+}
+
+// RestoreContent 恢复被后台下架的内容，恢复为下架前的状态
+func RestoreContent(c *gin.Context) {
+	db := config.GetDB()
+
+	var req RestoreContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	targetType, id, ok := contentParams(c)
+	if !ok {
+		return
+	}
+
+	// 只能恢复后台下架的内容，作者自行删除的内容没有下架记录
+	var removal model.AdminLog
+	if result := db.Where("action = ? AND target_type = ? AND target_id = ?", model.AdminActionRemoveContent, targetType, id).
+		Order("id DESC").First(&removal); result.Error != nil {
+		apperr.Abort(c, apperr.Conflict("该内容未被下架"))
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreContent(tx, targetType, id, removal.PrevStatus); err != nil {
+			return err
+		}
+		return tx.Create(&model.AdminLog{
+			OperatorID: c.GetUint("userID"),
+			Action:     model.AdminActionRestoreContent,
+			TargetType: targetType,
+			TargetID:   id,
+			PrevStatus: model.ContentStatusRemoved,
+			Reason:     req.Reason,
+		}).Error
+	})
+	if err != nil {
+		respondContentError(c, err, "内容未被下架", "恢复失败")
+		return
+	}
+
+	syncContentIndex(db, targetType, id)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已恢复",
+		Data:    nil,
+	})
+}
+
+// GetAdminLogs 后台操作记录，可按操作对象筛选
+func GetAdminLogs(c *gin.Context) {
+	db := config.GetDB()
+
+	page, pageSize := pagination.PageParams(c, 20)
+
+	query := db.Model(&model.AdminLog{})
+	if targetType := c.Query("targetType"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("targetId"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if operatorID := c.Query("operatorId"); operatorID != "" {
+		query = query.Where("operator_id = ?", operatorID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var logs []model.AdminLog
+	offset := (page - 1) * pageSize
+	if result := query.Preload("Operator").Order("id DESC").Limit(pageSize).Offset(offset).Find(&logs); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取操作记录失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  logs,
+			"total": total,
+		},
+	})
+}
+
+// contentParams 解析路径中的内容类型和ID，失败时已写入响应
+func contentParams(c *gin.Context) (string, uint, bool) {
+	targetType := c.Param("type")
+	if _, err := contentModel(targetType); err != nil {
+		apperr.Abort(c, apperr.BadRequest("不支持的内容类型"))
+		return "", 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的内容ID"))
+		return "", 0, false
+	}
+	return targetType, uint(id), true
+}
+
+// respondContentError 将下架/恢复内容的错误转换为响应
+func respondContentError(c *gin.Context, err error, stateMessage, failMessage string) {
+	switch {
+	case errors.Is(err, errContentNotFound):
+		apperr.Abort(c, apperr.NotFound("内容不存在"))
+	case errors.Is(err, errContentState):
+		apperr.Abort(c, apperr.Conflict(stateMessage))
+	default:
+		apperr.Abort(c, apperr.Internal(failMessage))
+	}
+}
+
+// contentStatus 读取内容当前状态，作者已删除的内容视为不存在
+func contentStatus(tx *gorm.DB, targetType string, id uint) (int, error) {
+	m, err := contentModel(targetType)
+	if err != nil {
+		return 0, err
+	}
+	var row struct{ Status int }
+	result := tx.Model(m).Select("status").Where("id = ?", id).Limit(1).Find(&row)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, errContentNotFound
+	}
+	return row.Status, nil
+}
+
+// hideContent 将内容置为下架状态并维护相关计数，返回下架前的状态
+func hideContent(tx *gorm.DB, targetType string, id uint) (int, error) {
+	prev, err := contentStatus(tx, targetType, id)
+	if err != nil {
+		return 0, err
+	}
+	if prev == model.ContentStatusRemoved {
+		return 0, errContentState
+	}
+
+	m, _ := contentModel(targetType)
+	if err := tx.Model(m).Where("id = ?", id).Update("status", model.ContentStatusRemoved).Error; err != nil {
+		return 0, err
+	}
+	return prev, adjustContentCounters(tx, targetType, id, prev, -1)
+}
+
+// restoreContent 将已下架的内容恢复为指定状态并维护相关计数
+func restoreContent(tx *gorm.DB, targetType string, id uint, status int) error {
+	current, err := contentStatus(tx, targetType, id)
+	if err != nil {
+		return err
+	}
+	if current != model.ContentStatusRemoved {
+		return errContentState
+	}
+
+	m, _ := contentModel(targetType)
+	if err := tx.Model(m).Where("id = ?", id).Update("status", status).Error; err != nil {
+		return err
+	}
+	return adjustContentCounters(tx, targetType, id, status, 1)
+}
+
+// approveContent 发布待审核的内容并维护相关计数。内容已不是待审核状态
+// （如帖子已由村落管理员审核）时不做修改
+func approveContent(tx *gorm.DB, targetType string, id uint) error {
+	current, err := contentStatus(tx, targetType, id)
+	if err != nil {
+		return err
+	}
+	if current != model.ContentStatusPending {
+		return nil
+	}
+
+	m, _ := contentModel(targetType)
+	if err := tx.Model(m).Where("id = ?", id).Update("status", model.ContentStatusNormal).Error; err != nil {
+		return err
+	}
+	return adjustContentCounters(tx, targetType, id, model.ContentStatusNormal, 1)
+}
+
+// adjustContentCounters 内容上下架时同步村落帖子数、帖子回复数，以及回答和评论计入的热度。
+// 只有正常状态的内容计入计数，status为变化前（下架）或变化后（恢复）的状态
+func adjustContentCounters(tx *gorm.DB, targetType string, id uint, status int, delta int) error {
+	if status != model.ContentStatusNormal {
+		return nil
+	}
+
+	switch targetType {
+	case model.TargetTypePost:
+		var post model.Post
+		if err := tx.Select("id, village_id").First(&post, id).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Village{}).Where("id = ? AND post_count + ? >= 0", post.VillageID, delta).
+			UpdateColumn("post_count", gorm.Expr("post_count + ?", delta)).Error
+	case model.TargetTypeAnswer:
+		var answer model.Answer
+		if err := tx.Select("id, question_id").First(&answer, id).Error; err != nil {
+			return err
+		}
+		return ranking.Bump(tx, model.TargetTypeQuestion, answer.QuestionID, float64(delta)*ranking.WeightAnswer)
+	case model.TargetTypeComment:
+		var comment model.Comment
+		if err := tx.Select("id, target_id, target_type").First(&comment, id).Error; err != nil {
+			return err
+		}
+		if err := ranking.Bump(tx, comment.TargetType, comment.TargetID, float64(delta)*ranking.WeightComment); err != nil {
+			return err
+		}
+		if comment.TargetType != model.TargetTypePost {
+			return nil
+		}
+		return tx.Model(&model.Post{}).Where("id = ? AND comments + ? >= 0", comment.TargetID, delta).
+			UpdateColumn("comments", gorm.Expr("comments + ?", delta)).Error
+	}
+	return nil
+}
+
+// syncContentIndex 按内容当前状态更新检索索引：正常状态写入索引，其他状态从索引删除
+func syncContentIndex(db *gorm.DB, targetType string, id uint) {
+	switch targetType {
+	case model.TargetTypeQuestion:
+		var question model.Question
+		if db.First(&question, id).Error == nil && question.Status == model.ContentStatusNormal {
+			indexDocument(search.QuestionDocument(question))
+		} else {
+			removeDocument(search.TypeQuestion, id)
+		}
+	case model.TargetTypeAnswer:
+		var answer model.Answer
+		if db.Preload("Question").First(&answer, id).Error == nil && answer.Status == model.ContentStatusNormal {
+			indexDocument(search.AnswerDocument(answer, answer.Question.Title))
+		} else {
+			removeDocument(search.TypeAnswer, id)
+		}
+	case model.TargetTypeNote:
+		var note model.Note
+		if db.First(&note, id).Error == nil && note.Status == model.ContentStatusNormal {
+			indexDocument(search.NoteDocument(note))
+		} else {
+			removeDocument(search.TypeNote, id)
+		}
+	case model.TargetTypePost:
+		var post model.Post
+		if db.First(&post, id).Error == nil && post.Status == model.PostStatusNormal {
+			indexDocument(search.PostDocument(post))
+		} else {
+			removeDocument(search.TypePost, id)
+		}
+	}
+}
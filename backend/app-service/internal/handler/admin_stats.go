@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// statsTrendMaxDays 趋势统计最多返回的天数
+const statsTrendMaxDays = 30
+
+// GetPlatformStats 平台统计：各类数据总量、今日新增和近days天的每日新增趋势
+func GetPlatformStats(c *gin.Context) {
+	db := config.GetDB()
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days < 1 {
+		days = 1
+	}
+	if days > statsTrendMaxDays {
+		days = statsTrendMaxDays
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var totals struct {
+		Users         int64 `json:"users"`
+		DisabledUsers int64 `json:"disabledUsers"`
+		Questions     int64 `json:"questions"`
+		Answers       int64 `json:"answers"`
+		Notes         int64 `json:"notes"`
+		Posts         int64 `json:"posts"`
+		PendingPosts  int64 `json:"pendingPosts"`
+		Comments      int64 `json:"comments"`
+		Villages      int64 `json:"villages"`
+	}
+	db.Model(&model.User{}).Count(&totals.Users)
+	db.Model(&model.User{}).Where("status = ?", model.UserStatusDisabled).Count(&totals.DisabledUsers)
+	db.Model(&model.Question{}).Where("status = ?", model.ContentStatusNormal).Count(&totals.Questions)
+	db.Model(&model.Answer{}).Where("status = ?", model.ContentStatusNormal).Count(&totals.Answers)
+	db.Model(&model.Note{}).Where("status = ?", model.ContentStatusNormal).Count(&totals.Notes)
+	db.Model(&model.Post{}).Where("status = ?", model.PostStatusNormal).Count(&totals.Posts)
+	db.Model(&model.Post{}).Where("status = ?", model.PostStatusPending).Count(&totals.PendingPosts)
+	db.Model(&model.Comment{}).Where("status = ?", model.ContentStatusNormal).Count(&totals.Comments)
+	db.Model(&model.Village{}).Where("status = ?", 1).Count(&totals.Villages)
+
+	var todayStats struct {
+		Users       int64 `json:"users"`
+		ActiveUsers int64 `json:"activeUsers"`
+		Questions   int64 `json:"questions"`
+		Answers     int64 `json:"answers"`
+		Notes       int64 `json:"notes"`
+		Posts       int64 `json:"posts"`
+		Comments    int64 `json:"comments"`
+	}
+	db.Model(&model.User{}).Where("created_at >= ?", today).Count(&todayStats.Users)
+	db.Model(&model.Session{}).Where("last_active_at >= ?", today).Distinct("user_id").Count(&todayStats.ActiveUsers)
+	db.Model(&model.Question{}).Where("created_at >= ?", today).Count(&todayStats.Questions)
+	db.Model(&model.Answer{}).Where("created_at >= ?", today).Count(&todayStats.Answers)
+	db.Model(&model.Note{}).Where("created_at >= ?", today).Count(&todayStats.Notes)
+	db.Model(&model.Post{}).Where("created_at >= ?", today).Count(&todayStats.Posts)
+	db.Model(&model.Comment{}).Where("created_at >= ?", today).Count(&todayStats.Comments)
+
+	type trendPoint struct {
+		Date      string `json:"date"`
+		Users     int64  `json:"users"`
+		Questions int64  `json:"questions"`
+		Notes     int64  `json:"notes"`
+		Posts     int64  `json:"posts"`
+	}
+	trend := make([]trendPoint, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		start := today.AddDate(0, 0, -i)
+		end := start.AddDate(0, 0, 1)
+		trend = append(trend, trendPoint{
+			Date:      start.Format("2006-01-02"),
+			Users:     countCreated(db, &model.User{}, start, end),
+			Questions: countCreated(db, &model.Question{}, start, end),
+			Notes:     countCreated(db, &model.Note{}, start, end),
+			Posts:     countCreated(db, &model.Post{}, start, end),
+		})
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"totals": totals,
+			"today":  todayStats,
+			"trend":  trend,
+		},
+	})
+}
+
+// countCreated 统计[start, end)内创建的记录数
+func countCreated(db *gorm.DB, m interface{}, start, end time.Time) int64 {
+	var count int64
+	db.Model(m).Where("created_at >= ? AND created_at < ?", start, end).Count(&count)
+	return count
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/rbac"
+	"ai-egg/app-service/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UpdateUserRoleRequest struct {
+	Role   string `json:"role" binding:"required"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type EnableUserRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// GetAdminUsers 后台用户列表，支持按用户名/邮箱、状态和角色筛选
+func GetAdminUsers(c *gin.Context) {
+	db := config.GetDB()
+
+	page, pageSize := pagination.PageParams(c, 20)
+	keyword := c.Query("keyword")
+	role := c.Query("role")
+	status := c.Query("status")
+
+	query := db.Model(&model.User{})
+	if keyword != "" {
+		query = query.Where("username LIKE ? OR email LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var users []model.User
+	offset := (page - 1) * pageSize
+	if result := query.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&users); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取用户列表失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  users,
+			"total": total,
+		},
+	})
+}
+
+// GetAdminUser 后台用户详情，包含内容数量和绑定的第三方账号
+func GetAdminUser(c *gin.Context) {
+	db := config.GetDB()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的用户ID"))
+		return
+	}
+
+	var user model.User
+	if result := db.First(&user, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return
+	}
+
+	var questionCount, answerCount, noteCount, postCount, commentCount int64
+	db.Model(&model.Question{}).Where("author_id = ?", user.ID).Count(&questionCount)
+	db.Model(&model.Answer{}).Where("author_id = ?", user.ID).Count(&answerCount)
+	db.Model(&model.Note{}).Where("author_id = ?", user.ID).Count(&noteCount)
+	db.Model(&model.Post{}).Where("author_id = ?", user.ID).Count(&postCount)
+	db.Model(&model.Comment{}).Where("author_id = ?", user.ID).Count(&commentCount)
+
+	var identities []model.UserIdentity
+	db.Where("user_id = ?", user.ID).Find(&identities)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"user":        user,
+			"permissions": rbac.Permissions(user.Role),
+			"identities":  identities,
+			"stats": gin.H{
+				"questions": questionCount,
+				"answers":   answerCount,
+				"notes":     noteCount,
+				"posts":     postCount,
+				"comments":  commentCount,
+			},
+		},
+	})
+}
+
+// UpdateUserRole 修改用户角色，角色变更即时生效
+func UpdateUserRole(c *gin.Context) {
+	db := config.GetDB()
+	operatorID := c.GetUint("userID")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的用户ID"))
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+	if !rbac.ValidRole(req.Role) {
+		apperr.Abort(c, apperr.BadRequest("无效的角色"))
+		return
+	}
+
+	// 避免管理员误操作后无人可以恢复权限
+	if uint(id) == operatorID {
+		apperr.Abort(c, apperr.Forbidden("不能修改自己的角色"))
+		return
+	}
+
+	var user model.User
+	if result := db.First(&user, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return
+	}
+
+	if user.Role == req.Role {
+		apperr.Abort(c, apperr.Conflict("角色未变化"))
+		return
+	}
+
+	prevRole := user.Role
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.AdminLog{
+			OperatorID: operatorID,
+			Action:     model.AdminActionUpdateRole,
+			TargetType: model.TargetTypeUser,
+			TargetID:   user.ID,
+			Detail:     prevRole + " -> " + req.Role,
+			Reason:     req.Reason,
+		}).Error
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("修改角色失败"))
+		return
+	}
+
+	account.Invalidate(user.ID)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "角色已修改",
+		Data:    nil,
+	})
+}
+
+// DisableUser 管理员禁用账号，同时下线该用户的全部设备
+func DisableUser(c *gin.Context) {
+	var req DisableUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	user, ok := setUserStatus(c, model.UserStatusDisabled, req.Reason)
+	if !ok {
+		return
+	}
+
+	if err := token.GetService().RevokeAllSessions(user.ID); err != nil {
+		log.Printf("Failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "账号已禁用",
+		Data:    nil,
+	})
+}
+
+// EnableUser 管理员解除账号禁用
+func EnableUser(c *gin.Context) {
+	var req EnableUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	if _, ok := setUserStatus(c, model.UserStatusNormal, req.Reason); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "账号已启用",
+		Data:    nil,
+	})
+}
+
+// GetUserStatusLogs 获取账号禁用/启用记录
+func GetUserStatusLogs(c *gin.Context) {
+	db := config.GetDB()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的用户ID"))
+		return
+	}
+
+	var logs []model.UserStatusLog
+	if result := db.Preload("Operator").Where("user_id = ?", id).Order("created_at DESC").Find(&logs); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取记录失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    logs,
+	})
+}
+
+// setUserStatus 修改账号状态并记录操作原因，失败时已写入响应
+func setUserStatus(c *gin.Context, status int, reason string) (model.User, bool) {
+	db := config.GetDB()
+	operatorID := c.GetUint("userID")
+
+	var user model.User
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的用户ID"))
+		return user, false
+	}
+
+	if uint(id) == operatorID {
+		apperr.Abort(c, apperr.Forbidden("不能修改自己的账号状态"))
+		return user, false
+	}
+
+	if result := db.First(&user, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return user, false
+	}
+
+	if !rbac.Outranks(c.GetString("userRole"), user.Role) {
+		apperr.Abort(c, apperr.Forbidden("无权管理该用户"))
+		return user, false
+	}
+
+	if user.Status == status {
+		apperr.Abort(c, apperr.Conflict("账号状态未变化"))
+		return user, false
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("status", status).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.UserStatusLog{
+			UserID:     user.ID,
+			OperatorID: operatorID,
+			Status:     status,
+			Reason:     reason,
+		}).Error
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("修改账号状态失败"))
+		return user, false
+	}
+
+	account.Invalidate(user.ID)
+	return user, true
+}
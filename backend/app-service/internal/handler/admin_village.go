@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateVillageRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	Icon        string `json:"icon" binding:"max=255"`
+	Category    string `json:"category" binding:"max=50"`
+	PostPolicy  string `json:"postPolicy" binding:"omitempty,oneof=open approval members"`
+	AllowAnon   *bool  `json:"allowAnon"`
+}
+
+type UpdateVillageRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+	Icon        *string `json:"icon" binding:"omitempty,max=255"`
+	Category    *string `json:"category" binding:"omitempty,max=50"`
+	PostPolicy  string  `json:"postPolicy" binding:"omitempty,oneof=open approval members"`
+	AllowAnon   *bool   `json:"allowAnon"`
+	Status      *int    `json:"status" binding:"omitempty,oneof=0 1"`
+	Reason      string  `json:"reason" binding:"max=500"`
+}
+
+type CloseVillageRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// GetAdminVillages 后台村落列表，包含已关闭的村落
+func GetAdminVillages(c *gin.Context) {
+	db := config.GetDB()
+
+	page, pageSize := pagination.PageParams(c, 20)
+
+	query := db.Model(&model.Village{})
+	if keyword := c.Query("keyword"); keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var villages []model.Village
+	offset := (page - 1) * pageSize
+	if result := query.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&villages); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取村落列表失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  villages,
+			"total": total,
+		},
+	})
+}
+
+// CreateVillage 后台创建村落
+func CreateVillage(c *gin.Context) {
+	db := config.GetDB()
+
+	var req CreateVillageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	village := model.Village{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Icon:        req.Icon,
+		Category:    req.Category,
+		PostPolicy:  model.PostPolicyOpen,
+		AllowAnon:   true,
+		Status:      1,
+	}
+	if req.PostPolicy != "" {
+		village.PostPolicy = req.PostPolicy
+	}
+	if req.AllowAnon != nil {
+		village.AllowAnon = *req.AllowAnon
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&village).Error; err != nil {
+			return err
+		}
+		// AllowAnon为false时零值不会写入，需单独更新
+		if !village.AllowAnon {
+			if err := tx.Model(&village).Update("allow_anon", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&model.AdminLog{
+			OperatorID: c.GetUint("userID"),
+			Action:     model.AdminActionCreateVillage,
+			TargetType: model.TargetTypeVillage,
+			TargetID:   village.ID,
+			Detail:     village.Name,
+		}).Error
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("创建村落失败"))
+		return
+	}
+
+	indexDocument(search.VillageDocument(village))
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "创建成功",
+		Data:    village,
+	})
+}
+
+// UpdateVillage 后台编辑村落资料、发帖策略和状态
+func UpdateVillage(c *gin.Context) {
+	db := config.GetDB()
+
+	var req UpdateVillageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	village, ok := adminVillage(c, db)
+	if !ok {
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Icon != nil {
+		updates["icon"] = *req.Icon
+	}
+	if req.Category != nil {
+		updates["category"] = *req.Category
+	}
+	if req.PostPolicy != "" {
+		updates["post_policy"] = req.PostPolicy
+	}
+	if req.AllowAnon != nil {
+		updates["allow_anon"] = *req.AllowAnon
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+	if len(updates) == 0 {
+		apperr.Abort(c, apperr.BadRequest("没有需要修改的内容"))
+		return
+	}
+
+	if !saveVillage(c, db, &village, updates, model.AdminActionUpdateVillage, req.Reason) {
+		apperr.Abort(c, apperr.Internal("修改村落失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    village,
+	})
+}
+
+// CloseVillage 后台关闭村落，关闭后村落及其帖子不再对外展示
+func CloseVillage(c *gin.Context) {
+	db := config.GetDB()
+
+	var req CloseVillageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	village, ok := adminVillage(c, db)
+	if !ok {
+		return
+	}
+
+	if village.Status == 0 {
+		apperr.Abort(c, apperr.Conflict("村落已关闭"))
+		return
+	}
+
+	if !saveVillage(c, db, &village, map[string]interface{}{"status": 0}, model.AdminActionCloseVillage, req.Reason) {
+		apperr.Abort(c, apperr.Internal("关闭村落失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "村落已关闭",
+		Data:    nil,
+	})
+}
+
+// adminVillage 按路径ID查找村落（不限状态），失败时已写入响应
+func adminVillage(c *gin.Context, db *gorm.DB) (model.Village, bool) {
+	var village model.Village
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
+		return village, false
+	}
+
+	if result := db.First(&village, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("村落不存在"))
+		return village, false
+	}
+	return village, true
+}
+
+// saveVillage 保存村落修改并记录操作，成功后按状态同步检索索引
+func saveVillage(c *gin.Context, db *gorm.DB, village *model.Village, updates map[string]interface{}, action, reason string) bool {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(village).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.AdminLog{
+			OperatorID: c.GetUint("userID"),
+			Action:     action,
+			TargetType: model.TargetTypeVillage,
+			TargetID:   village.ID,
+			Detail:     village.Name,
+			Reason:     reason,
+		}).Error
+	})
+	if err != nil {
+		return false
+	}
+
+	if village.Status == 1 {
+		indexDocument(search.VillageDocument(*village))
+	} else {
+		removeDocument(search.TypeVillage, village.ID)
+	}
+	return true
+}
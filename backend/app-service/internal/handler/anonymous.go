@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TraceAnonymousRequest struct {
+	TargetType string `json:"targetType" binding:"required,oneof=post reply"`
+	TargetID   uint   `json:"targetId" binding:"required"`
+	Reason     string `json:"reason" binding:"required,max=500"`
+}
+
+// hidePostAuthor 隐藏匿名帖子的作者信息，作者本人通过IsMine识别自己的帖子
+func hidePostAuthor(post *model.Post, viewerID uint) {
+	post.IsMine = post.AuthorID == viewerID
+	if !post.IsAnonymous {
+		return
+	}
+	post.AuthorID = 0
+	post.Author = model.User{Username: post.AnonName}
+}
+
+// hideCommentAuthor 隐藏匿名回复的作者信息
+func hideCommentAuthor(comment *model.Comment, viewerID uint) {
+	comment.IsMine = comment.AuthorID == viewerID
+	if !comment.IsAnonymous {
+		return
+	}
+	comment.AuthorID = 0
+	comment.Author = model.User{Username: comment.AnonName}
+}
+
+// hideEmail 游客访问公开内容时隐藏作者邮箱
+func hideEmail(user *model.User) {
+	user.Email = nil
+}
+
+// viewerID 获取当前请求的用户ID，未登录时返回0
+func viewerID(c *gin.Context) uint {
+	if userID, exists := c.Get("userID"); exists {
+		return userID.(uint)
+	}
+	return 0
+}
+
+// TraceAnonymousAuthor 村落管理员追溯匿名内容的真实作者，每次查询都会记录审计日志
+func (h *VillageHandler) TraceAnonymousAuthor(c *gin.Context) {
+	villageID, operatorID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	var req TraceAnonymousRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	audit, author, err := h.villages.TraceAnonymous(villageID, operatorID, service.AnonymousTrace{
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		respondError(c, err, "记录审计日志失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"auditId": audit.ID,
+			"author": UserInfo{
+				ID:       author.ID,
+				Username: author.Username,
+				Email:    author.EmailAddress(),
+				Avatar:   author.Avatar,
+				Bio:      author.Bio,
+			},
+		},
+	})
+}
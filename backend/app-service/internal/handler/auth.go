@@ -1,21 +1,30 @@
 package handler
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/config"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/ratelimit"
+	"ai-egg/app-service/internal/rbac"
+	"ai-egg/app-service/internal/search"
+	"ai-egg/app-service/internal/service"
+	"ai-egg/app-service/internal/token"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=20"`
 	Password string `json:"password" binding:"required,min=6"`
-	Email    string `json:"email"`
+	Email    string `json:"email" binding:"omitempty,email"`
 }
 
 type LoginRequest struct {
@@ -30,79 +39,40 @@ type Response struct {
 	Data    interface{} `json:"data"`
 }
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
-
-// GenerateToken 生成JWT token
-func GenerateToken(userID uint) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7天过期
-	})
-	return token.SignedString(jwtSecret)
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
-func Register(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *UserHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
-	// 检查用户名是否已存在
-	var existingUser model.User
-	if result := db.Where("username = ?", req.Username).First(&existingUser); result.Error == nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "用户名已存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	user, err := h.users.Register(service.Registration{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+	})
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "密码加密失败",
-			Data:    nil,
-		})
+		respondError(c, err, "创建用户失败")
 		return
 	}
 
-	// 创建用户
-	user := model.User{
-		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
-		Email:        req.Email,
-		Avatar:       "",
-		Bio:          "",
-		Status:       1,
-	}
+	indexDocument(search.UserDocument(user))
 
-	if result := db.Create(&user); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "创建用户失败",
-			Data:    nil,
-		})
-		return
+	// 填写了邮箱时发送验证邮件，发送失败不影响注册，用户可稍后重新发送
+	if user.Email != nil {
+		if err := sendVerificationEmail(config.GetDB(), user); err != nil {
+			log.Printf("Failed to send verification mail to user %d: %v", user.ID, err)
+		}
 	}
 
-	// 生成token
-	token, err := GenerateToken(user.ID)
+	// 签发访问令牌和刷新令牌
+	pair, err := token.GetService().IssuePair(user.ID, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "生成token失败",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Internal("生成token失败"))
 		return
 	}
 
@@ -110,65 +80,65 @@ func Register(c *gin.Context) {
 		Code:    200,
 		Message: "注册成功",
 		Data: gin.H{
-			"userId":  user.ID,
-			"token":   token,
-			"username": user.Username,
+			"userId":           user.ID,
+			"token":            pair.AccessToken,
+			"refreshToken":     pair.RefreshToken,
+			"expiresIn":        pair.ExpiresIn,
+			"refreshExpiresIn": pair.RefreshExpiresIn,
+			"username":         user.Username,
 		},
 	})
 }
 
-func Login(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *UserHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
-	// 查找用户
-	var user model.User
-	if result := db.Where("username = ?", req.Username).First(&user); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "用户名或密码错误",
-			Data:    nil,
-		})
-		return
+	// 连续登录失败的账号暂时锁定
+	lockKey := "login:" + strings.ToLower(req.Username)
+	if lockout := ratelimit.GetLockout(); lockout != nil {
+		if d, err := lockout.Check(lockKey); err != nil {
+			log.Printf("Failed to check login lockout: %v", err)
+		} else if d > 0 {
+			respondLoginLocked(c, d)
+			return
+		}
 	}
 
-	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "用户名或密码错误",
-			Data:    nil,
-		})
+	user, err := h.users.Authenticate(req.Username, req.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		loginFailed(c, lockKey)
+		return
+	}
+	if err != nil {
+		respondError(c, err, "登录失败")
 		return
 	}
 
+	if lockout := ratelimit.GetLockout(); lockout != nil {
+		if err := lockout.Reset(lockKey); err != nil {
+			log.Printf("Failed to reset login lockout: %v", err)
+		}
+	}
+
 	// 检查用户状态
 	if user.Status != 1 {
-		c.JSON(http.StatusOK, Response{
-			Code:    403,
-			Message: "账号已被禁用",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Forbidden("账号已被禁用").WithCode(apperr.CodeAccountDisabled))
 		return
 	}
 
-	// 生成token
-	token, err := GenerateToken(user.ID)
+	respondLogin(c, user)
+}
+
+// respondLogin 为已通过认证的用户签发令牌并返回登录结果
+func respondLogin(c *gin.Context, user model.User) {
+	// 签发访问令牌和刷新令牌
+	pair, err := token.GetService().IssuePair(user.ID, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "生成token失败",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Internal("生成token失败"))
 		return
 	}
 
@@ -176,16 +146,93 @@ func Login(c *gin.Context) {
 		Code:    200,
 		Message: "登录成功",
 		Data: gin.H{
-			"token":    token,
-			"userId":   user.ID,
-			"username": user.Username,
-			"avatar":   user.Avatar,
+			"token":            pair.AccessToken,
+			"refreshToken":     pair.RefreshToken,
+			"expiresIn":        pair.ExpiresIn,
+			"refreshExpiresIn": pair.RefreshExpiresIn,
+			"userId":           user.ID,
+			"username":         user.Username,
+			"avatar":           user.Avatar,
 		},
 	})
 }
 
+// loginFailed 记录一次登录失败，达到阈值时锁定账号
+func loginFailed(c *gin.Context, lockKey string) {
+	if lockout := ratelimit.GetLockout(); lockout != nil {
+		d, err := lockout.Fail(lockKey)
+		if err != nil {
+			log.Printf("Failed to record login failure: %v", err)
+		} else if d > 0 {
+			respondLoginLocked(c, d)
+			return
+		}
+	}
+
+	apperr.Abort(c, apperr.BadRequest("用户名或密码错误").WithCode(apperr.CodeInvalidCredentials))
+}
+
+// respondLoginLocked 账号锁定期间拒绝登录
+func respondLoginLocked(c *gin.Context, d time.Duration) {
+	seconds := ratelimit.RetryAfterSeconds(d)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	apperr.Abort(c, apperr.TooManyRequests("登录失败次数过多，请%d分钟后再试", (seconds+59)/60).WithCode(apperr.CodeLoginLocked))
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+func RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	// 账号被禁用或注销后不再续期，先检查账号再作废旧令牌，避免签发新令牌
+	userID, err := token.GetService().RefreshTokenOwner(req.RefreshToken)
+	if err != nil {
+		abortRefresh(c, err)
+		return
+	}
+	if _, err := account.Check(config.GetDB(), userID); err != nil {
+		token.GetService().RevokeAllSessions(userID)
+		apperr.Abort(c, apperr.Forbidden("账号已被禁用").WithCode(apperr.CodeAccountDisabled))
+		return
+	}
+
+	pair, err := token.GetService().Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		abortRefresh(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    pair,
+	})
+}
+
+// abortRefresh 按刷新失败的原因写入响应
+func abortRefresh(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, token.ErrTokenExpired):
+		apperr.Abort(c, apperr.Unauthorized("刷新令牌已过期，请重新登录").WithCode(apperr.CodeTokenExpired))
+	case errors.Is(err, token.ErrTokenReused):
+		apperr.Abort(c, apperr.Unauthorized("登录状态异常，请重新登录").WithCode(apperr.CodeTokenRevoked))
+	case errors.Is(err, token.ErrInvalidToken):
+		apperr.Abort(c, apperr.Unauthorized("刷新令牌无效").WithCode(apperr.CodeTokenInvalid))
+	default:
+		apperr.Abort(c, apperr.Internal("刷新令牌失败"))
+	}
+}
+
 func Logout(c *gin.Context) {
-	// JWT是无状态的，客户端只需删除token即可
+	// 吊销当前访问令牌并作废本次登录的刷新令牌
+	if err := token.GetService().Logout(currentClaims(c)); err != nil {
+		apperr.Abort(c, apperr.Internal("退出失败"))
+		return
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "退出成功",
@@ -194,7 +241,7 @@ func Logout(c *gin.Context) {
 }
 
 func CheckLogin(c *gin.Context) {
-	// 从上下文中获取用户信息（由Auth中间件设置）
+	// 从上下文中获取用户信息（由OptionalAuth中间件设置，未登录时不存在）
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusOK, Response{
@@ -224,10 +271,12 @@ func CheckLogin(c *gin.Context) {
 		Code:    200,
 		Message: "",
 		Data: gin.H{
-			"isLoggedIn": true,
-			"userId":     user.ID,
-			"username":   user.Username,
-			"avatar":     user.Avatar,
+			"isLoggedIn":  true,
+			"userId":      user.ID,
+			"username":    user.Username,
+			"avatar":      user.Avatar,
+			"role":        user.Role,
+			"permissions": rbac.Permissions(user.Role),
 		},
 	})
 }
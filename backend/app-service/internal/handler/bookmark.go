@@ -0,0 +1,593 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/notify"
+	"ai-egg/app-service/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxBookmarkFolders 每个用户最多创建的收藏夹数
+	maxBookmarkFolders = 50
+	// bookmarkNoteCategory 收藏转为笔记时的默认分类
+	bookmarkNoteCategory = "收藏"
+)
+
+type CreateBookmarkRequest struct {
+	TargetType string `json:"targetType" binding:"required,oneof=question answer note post comment"`
+	TargetID   uint   `json:"targetId" binding:"required"`
+	FolderID   *uint  `json:"folderId"`
+	Remark     string `json:"remark" binding:"max=500"`
+}
+
+// UpdateBookmarkRequest 修改收藏，folderId为0表示移到未分类
+type UpdateBookmarkRequest struct {
+	FolderID *uint   `json:"folderId"`
+	Remark   *string `json:"remark" binding:"omitempty,max=500"`
+}
+
+type BookmarkFolderRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// BookmarkToNoteRequest 收藏转为笔记，标题和分类不填时分别使用收藏标题和收藏夹名称
+type BookmarkToNoteRequest struct {
+	Title    string `json:"title" binding:"max=200"`
+	Category string `json:"category" binding:"max=50"`
+}
+
+// bookmarkSource 被收藏内容的标题、正文和作者展示名，匿名内容只展示化名
+type bookmarkSource struct {
+	Title  string
+	Text   string
+	Author string
+}
+
+// CreateBookmark 收藏问题、回答、笔记、帖子或评论
+func CreateBookmark(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	source, err := loadBookmarkSource(db, req.TargetType, req.TargetID)
+	if err != nil {
+		respondContentError(c, err, "该内容不可收藏", "收藏失败")
+		return
+	}
+
+	folderID, ok := bookmarkFolderID(c, db, userID, req.FolderID)
+	if !ok {
+		return
+	}
+
+	var count int64
+	db.Model(&model.Bookmark{}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", userID, req.TargetType, req.TargetID).
+		Count(&count)
+	if count > 0 {
+		apperr.Abort(c, apperr.Conflict("已经收藏过了").WithCode(apperr.CodeAlreadyExists))
+		return
+	}
+
+	bookmark := model.Bookmark{
+		UserID:     userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		FolderID:   folderID,
+		Title:      source.Title,
+		Excerpt:    notify.Snippet(source.Text, 200),
+		Remark:     req.Remark,
+		Available:  true,
+	}
+	if err := db.Create(&bookmark).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("收藏失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "收藏成功",
+		Data:    bookmark,
+	})
+}
+
+// GetBookmarks 获取我的收藏，可按收藏夹（folderId=0为未分类）、内容类型和关键词筛选
+func GetBookmarks(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	page, pageSize := pagination.PageParams(c, 20)
+
+	query := db.Model(&model.Bookmark{}).Where("user_id = ?", userID)
+	if folder := c.Query("folderId"); folder != "" {
+		folderID, err := strconv.ParseUint(folder, 10, 64)
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("无效的收藏夹ID"))
+			return
+		}
+		if folderID == 0 {
+			query = query.Where("folder_id IS NULL")
+		} else {
+			query = query.Where("folder_id = ?", folderID)
+		}
+	}
+	if targetType := c.Query("targetType"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if keyword := strings.TrimSpace(c.Query("keyword")); keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("title LIKE ? OR excerpt LIKE ? OR remark LIKE ?", like, like, like)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var bookmarks []model.Bookmark
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&bookmarks).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("获取收藏失败"))
+		return
+	}
+	markBookmarksAvailable(db, bookmarks)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  bookmarks,
+			"total": total,
+		},
+	})
+}
+
+// UpdateBookmark 移动收藏到其他收藏夹或修改备注
+func UpdateBookmark(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req UpdateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	bookmark, ok := findBookmark(c, db, userID)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.FolderID != nil {
+		folderID, ok := bookmarkFolderID(c, db, userID, req.FolderID)
+		if !ok {
+			return
+		}
+		updates["folder_id"] = folderID
+	}
+	if req.Remark != nil {
+		updates["remark"] = *req.Remark
+	}
+
+	if len(updates) > 0 {
+		if err := db.Model(&bookmark).Updates(updates).Error; err != nil {
+			apperr.Abort(c, apperr.Internal("修改收藏失败"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    bookmark,
+	})
+}
+
+// DeleteBookmark 取消收藏，已转成的笔记保留
+func DeleteBookmark(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	bookmark, ok := findBookmark(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if err := db.Delete(&bookmark).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("取消收藏失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已取消收藏",
+		Data:    nil,
+	})
+}
+
+// ConvertBookmarkToNote 将收藏转为自己的笔记，正文附带来源和备注。
+// 原内容已删除或下架时不能转换，避免以笔记形式重新发布
+func (h *NoteHandler) ConvertBookmarkToNote(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	// 请求体可省略
+	var req BookmarkToNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	bookmark, ok := findBookmark(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if bookmark.NoteID != nil {
+		apperr.Abort(c, apperr.Conflict("该收藏已转为笔记").WithData(gin.H{
+			"noteId": *bookmark.NoteID,
+		}))
+		return
+	}
+
+	source, err := loadBookmarkSource(db, bookmark.TargetType, bookmark.TargetID)
+	if errors.Is(err, errContentNotFound) {
+		apperr.Abort(c, apperr.NotFound("原内容已不可访问"))
+		return
+	}
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("转为笔记失败"))
+		return
+	}
+
+	title := req.Title
+	if title == "" {
+		title = bookmark.Title
+	}
+	category := req.Category
+	if category == "" {
+		category = bookmarkNoteCategory
+		if bookmark.FolderID != nil {
+			var folder model.BookmarkFolder
+			if db.First(&folder, *bookmark.FolderID).Error == nil {
+				category = folder.Name
+			}
+		}
+	}
+	content := bookmarkNoteContent(bookmark, source)
+
+	review, ok := filterText(c, &title, &content)
+	if !ok {
+		return
+	}
+
+	note := model.Note{
+		Title:    title,
+		Content:  content,
+		Category: category,
+		AuthorID: userID,
+	}
+	if err := h.notes.CreateFromBookmark(bookmark.ID, &note, review); err != nil {
+		respondError(c, err, "转为笔记失败")
+		return
+	}
+
+	message := "已转为笔记"
+	if review.Hold {
+		message = filterHoldMessage
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data: gin.H{
+			"id":     note.ID,
+			"status": note.Status,
+		},
+	})
+}
+
+// GetBookmarkFolders 获取我的收藏夹及每个收藏夹中的收藏数
+func GetBookmarkFolders(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var folders []model.BookmarkFolder
+	db.Where("user_id = ?", userID).Order("created_at ASC").Find(&folders)
+
+	var rows []struct {
+		FolderID *uint
+		Count    int64
+	}
+	db.Model(&model.Bookmark{}).
+		Select("folder_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("folder_id").
+		Scan(&rows)
+
+	counts := make(map[uint]int64, len(rows))
+	var uncategorized int64
+	for _, row := range rows {
+		if row.FolderID == nil {
+			uncategorized = row.Count
+			continue
+		}
+		counts[*row.FolderID] = row.Count
+	}
+	for i := range folders {
+		folders[i].Count = counts[folders[i].ID]
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":          folders,
+			"uncategorized": uncategorized,
+		},
+	})
+}
+
+// CreateBookmarkFolder 创建收藏夹
+func CreateBookmarkFolder(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req BookmarkFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	var count int64
+	db.Model(&model.BookmarkFolder{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxBookmarkFolders {
+		apperr.Abort(c, apperr.BadRequest("收藏夹数量已达上限").WithCode(apperr.CodeLimitExceeded))
+		return
+	}
+
+	if !bookmarkFolderNameAvailable(c, db, userID, req.Name, 0) {
+		return
+	}
+
+	folder := model.BookmarkFolder{UserID: userID, Name: req.Name}
+	if err := db.Create(&folder).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("创建收藏夹失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "创建成功",
+		Data:    folder,
+	})
+}
+
+// RenameBookmarkFolder 重命名收藏夹
+func RenameBookmarkFolder(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req BookmarkFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	folder, ok := findBookmarkFolder(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if !bookmarkFolderNameAvailable(c, db, userID, req.Name, folder.ID) {
+		return
+	}
+
+	if err := db.Model(&folder).Update("name", req.Name).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("修改收藏夹失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    folder,
+	})
+}
+
+// DeleteBookmarkFolder 删除收藏夹，其中的收藏移到未分类
+func DeleteBookmarkFolder(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	folder, ok := findBookmarkFolder(c, db, userID)
+	if !ok {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Bookmark{}).
+			Where("user_id = ? AND folder_id = ?", userID, folder.ID).
+			Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&folder).Error
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("删除收藏夹失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "删除成功",
+		Data:    nil,
+	})
+}
+
+// findBookmark 按路径参数id查找当前用户的收藏，失败时已写入响应
+func findBookmark(c *gin.Context, db *gorm.DB, userID uint) (model.Bookmark, bool) {
+	var bookmark model.Bookmark
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的收藏ID"))
+		return bookmark, false
+	}
+
+	if result := db.Where("user_id = ?", userID).First(&bookmark, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("收藏不存在"))
+		return bookmark, false
+	}
+	return bookmark, true
+}
+
+// findBookmarkFolder 按路径参数id查找当前用户的收藏夹，失败时已写入响应
+func findBookmarkFolder(c *gin.Context, db *gorm.DB, userID uint) (model.BookmarkFolder, bool) {
+	var folder model.BookmarkFolder
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的收藏夹ID"))
+		return folder, false
+	}
+
+	if result := db.Where("user_id = ?", userID).First(&folder, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("收藏夹不存在"))
+		return folder, false
+	}
+	return folder, true
+}
+
+// bookmarkFolderID 校验请求中的收藏夹属于当前用户，0或未填写表示未分类，失败时已写入响应
+func bookmarkFolderID(c *gin.Context, db *gorm.DB, userID uint, folderID *uint) (*uint, bool) {
+	if folderID == nil || *folderID == 0 {
+		return nil, true
+	}
+
+	var count int64
+	db.Model(&model.BookmarkFolder{}).Where("id = ? AND user_id = ?", *folderID, userID).Count(&count)
+	if count == 0 {
+		apperr.Abort(c, apperr.NotFound("收藏夹不存在"))
+		return nil, false
+	}
+	return folderID, true
+}
+
+// bookmarkFolderNameAvailable 检查收藏夹名称是否与用户的其他收藏夹重复，重复时已写入响应
+func bookmarkFolderNameAvailable(c *gin.Context, db *gorm.DB, userID uint, name string, excludeID uint) bool {
+	var count int64
+	db.Model(&model.BookmarkFolder{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count)
+	if count > 0 {
+		apperr.Abort(c, apperr.Conflict("收藏夹名称已存在").WithCode(apperr.CodeAlreadyExists))
+		return false
+	}
+	return true
+}
+
+// loadBookmarkSource 加载可收藏的内容，只有正常展示的内容可以收藏
+func loadBookmarkSource(db *gorm.DB, targetType string, id uint) (bookmarkSource, error) {
+	var err error
+	var source bookmarkSource
+	status := model.ContentStatusNormal
+
+	switch targetType {
+	case model.TargetTypeQuestion:
+		var question model.Question
+		err = db.Preload("Author").First(&question, id).Error
+		source = bookmarkSource{question.Title, question.Content, question.Author.Username}
+		status = question.Status
+	case model.TargetTypeAnswer:
+		var answer model.Answer
+		err = db.Preload("Author").Preload("Question").First(&answer, id).Error
+		source = bookmarkSource{"回答：" + answer.Question.Title, answer.Content, answer.Author.Username}
+		status = answer.Status
+	case model.TargetTypeNote:
+		var note model.Note
+		err = db.Preload("Author").First(&note, id).Error
+		source = bookmarkSource{note.Title, note.Content, note.Author.Username}
+		status = note.Status
+	case model.TargetTypePost:
+		var post model.Post
+		err = db.Preload("Author").First(&post, id).Error
+		source = bookmarkSource{notify.Snippet(post.Content, 50), post.Content, post.Author.Username}
+		if post.IsAnonymous {
+			source.Author = post.AnonName
+		}
+		status = post.Status
+	case model.TargetTypeComment:
+		var comment model.Comment
+		err = db.Preload("Author").First(&comment, id).Error
+		source = bookmarkSource{notify.Snippet(comment.Content, 50), comment.Content, comment.Author.Username}
+		if comment.IsAnonymous {
+			source.Author = comment.AnonName
+		}
+		status = comment.Status
+	default:
+		return source, errUnknownContentType
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && status != model.ContentStatusNormal {
+		return source, errContentNotFound
+	}
+	return source, err
+}
+
+// markBookmarksAvailable 标记收藏的原内容是否仍可访问，每种内容类型查询一次
+func markBookmarksAvailable(db *gorm.DB, bookmarks []model.Bookmark) {
+	idsByType := make(map[string][]uint)
+	for _, b := range bookmarks {
+		idsByType[b.TargetType] = append(idsByType[b.TargetType], b.TargetID)
+	}
+
+	available := make(map[string]bool)
+	for targetType, ids := range idsByType {
+		m, err := contentModel(targetType)
+		if err != nil {
+			continue
+		}
+		var found []uint
+		db.Model(m).Where("id IN ? AND status = ?", ids, model.ContentStatusNormal).Pluck("id", &found)
+		for _, id := range found {
+			available[targetType+":"+strconv.FormatUint(uint64(id), 10)] = true
+		}
+	}
+
+	for i := range bookmarks {
+		key := bookmarks[i].TargetType + ":" + strconv.FormatUint(uint64(bookmarks[i].TargetID), 10)
+		bookmarks[i].Available = available[key]
+	}
+}
+
+// bookmarkNoteContent 生成收藏转笔记的正文：原文、来源和备注
+func bookmarkNoteContent(bookmark model.Bookmark, source bookmarkSource) string {
+	var b strings.Builder
+	b.WriteString(source.Text)
+	b.WriteString("\n\n——收藏自")
+	b.WriteString(notify.TargetTypeNames[bookmark.TargetType])
+	b.WriteString("「" + bookmark.Title + "」")
+	if source.Author != "" {
+		b.WriteString("，作者：" + source.Author)
+	}
+	if bookmark.Remark != "" {
+		b.WriteString("\n\n备注：" + bookmark.Remark)
+	}
+	return b.String()
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateGroupChatRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	MemberIDs []uint `json:"memberIds"`
+}
+
+type AddChatMembersRequest struct {
+	UserIDs []uint `json:"userIds" binding:"required,min=1"`
+}
+
+type SendGroupMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+	Type    string `json:"type" binding:"omitempty,oneof=text image file"` // 系统消息只能由服务端生成
+}
+
+// CreateGroupChat 创建群聊
+func (h *ChatHandler) CreateGroupChat(c *gin.Context) {
+	var req CreateGroupChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	// 不存在的用户和创建者自己会被过滤掉
+	chat, err := h.chats.CreateGroup(userID.(uint), req.Name, req.MemberIDs)
+	if err != nil {
+		respondError(c, err, "创建群聊失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "创建成功",
+		Data: gin.H{
+			"id": chat.ID,
+		},
+	})
+}
+
+// GetGroupChats 获取当前用户加入的群聊列表
+func (h *ChatHandler) GetGroupChats(c *gin.Context) {
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	list, err := h.chats.Groups(userID.(uint))
+	if err != nil {
+		respondError(c, err, "获取群聊列表失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list": list,
+		},
+	})
+}
+
+// GetChatMembers 获取群聊成员列表
+func (h *ChatHandler) GetChatMembers(c *gin.Context) {
+	chat, _, ok := h.requireChatMember(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.chats.Members(chat.ID)
+	if err != nil {
+		respondError(c, err, "获取成员列表失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list": members,
+		},
+	})
+}
+
+// AddChatMembers 邀请用户加入群聊
+func (h *ChatHandler) AddChatMembers(c *gin.Context) {
+	chat, _, ok := h.requireChatMember(c)
+	if !ok {
+		return
+	}
+
+	var req AddChatMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	// 村落聊天室的成员与村落成员保持一致，不支持邀请
+	userIDs, err := h.chats.Invite(chat, req.UserIDs)
+	if err != nil {
+		respondError(c, err, "邀请成员失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "邀请成功",
+		Data: gin.H{
+			"added": userIDs,
+		},
+	})
+}
+
+// LeaveChat 退出群聊
+func (h *ChatHandler) LeaveChat(c *gin.Context) {
+	chat, member, ok := h.requireChatMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chats.Leave(chat, member); err != nil {
+		respondError(c, err, "退出群聊失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "退出成功",
+		Data:    nil,
+	})
+}
+
+// SendGroupMessage 发送群聊消息
+func (h *ChatHandler) SendGroupMessage(c *gin.Context) {
+	chat, member, ok := h.requireChatMember(c)
+	if !ok {
+		return
+	}
+
+	var req SendGroupMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	// 设置默认消息类型
+	msgType := req.Type
+	if msgType == "" {
+		msgType = "text"
+	}
+
+	// 图片和文件消息的内容同样由客户端提交，一并过滤
+	if !filterStrict(c, &req.Content) {
+		return
+	}
+
+	message, err := h.chats.SendToGroup(chat, member, req.Content, msgType)
+	if err != nil {
+		respondError(c, err, "保存消息失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "发送成功",
+		Data: gin.H{
+			"id": message.ID,
+		},
+	})
+}
+
+// MarkChatRead 将群聊消息标记为已读
+func (h *ChatHandler) MarkChatRead(c *gin.Context) {
+	chat, member, ok := h.requireChatMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.chats.MarkRead(chat, member); err != nil {
+		respondError(c, err, "标记已读失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    nil,
+	})
+}
+
+// JoinVillageChat 加入村落聊天室，聊天室在首次加入时创建
+func (h *ChatHandler) JoinVillageChat(c *gin.Context) {
+	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
+		return
+	}
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	chat, err := h.chats.JoinVillageChat(uint(villageID), userID.(uint))
+	if err != nil {
+		respondError(c, err, "加入聊天室失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "加入成功",
+		Data: gin.H{
+			"id": chat.ID,
+		},
+	})
+}
+
+// requireChatMember 校验当前用户是否为群聊成员，失败时直接写入响应
+func (h *ChatHandler) requireChatMember(c *gin.Context) (model.Chat, model.ChatMember, bool) {
+	chatID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的聊天ID"))
+		return model.Chat{}, model.ChatMember{}, false
+	}
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return model.Chat{}, model.ChatMember{}, false
+	}
+
+	chat, member, err := h.chats.Membership(uint(chatID), userID.(uint))
+	if err != nil {
+		respondError(c, err, "获取群聊失败")
+		return chat, member, false
+	}
+	return chat, member, true
+}
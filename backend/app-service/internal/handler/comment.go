@@ -4,12 +4,23 @@ import (
 	"net/http"
 	"strconv"
 
-	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// CommentHandler 评论模块
+type CommentHandler struct {
+	comments *service.CommentService
+}
+
+func NewCommentHandler(comments *service.CommentService) *CommentHandler {
+	return &CommentHandler{comments: comments}
+}
+
 type CreateCommentRequest struct {
 	TargetID   uint   `json:"targetId" binding:"required"`
 	TargetType string `json:"targetType" binding:"required"`
@@ -22,27 +33,22 @@ type ReplyCommentRequest struct {
 }
 
 // CreateComment 创建评论
-func CreateComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) CreateComment(c *gin.Context) {
 	var req CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	review, ok := filterText(c, &req.Content)
+	if !ok {
 		return
 	}
 
@@ -52,100 +58,68 @@ func CreateComment(c *gin.Context) {
 		Content:    req.Content,
 		AuthorID:   userID.(uint),
 		ParentID:   req.ParentID,
-		Likes:      0,
-		Status:     1,
 	}
-
-	result := db.Create(&comment)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "创建评论失败",
-			Data:    nil,
-		})
+	if err := h.comments.Create(&comment, review); err != nil {
+		respondError(c, err, "创建评论失败")
 		return
 	}
 
-	// 预加载作者信息
-	db.Preload("Author").First(&comment, comment.ID)
+	message := "评论成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "评论成功",
+		Message: message,
 		Data:    comment,
 	})
 }
 
 // GetComments 获取评论列表
-func GetComments(c *gin.Context) {
-	db := config.GetDB()
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	targetID := c.Query("targetId")
-	targetType := c.Query("targetType")
-
-	var comments []model.Comment
-	query := db.Model(&model.Comment{}).Where("status = ?", 1)
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
 
 	// 根据目标筛选
-	if targetID != "" {
-		if id, err := strconv.ParseUint(targetID, 10, 64); err == nil {
-			query = query.Where("target_id = ?", id)
-		}
-	}
-	if targetType != "" {
-		query = query.Where("target_type = ?", targetType)
+	filter := repository.CommentFilter{TargetType: c.Query("targetType")}
+	if id, err := strconv.ParseUint(c.Query("targetId"), 10, 64); err == nil {
+		filter.TargetID = uint(id)
 	}
 
-	var total int64
-	query.Count(&total)
-
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&comments)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取评论列表失败",
-			Data:    nil,
-		})
+	comments, page, err := h.comments.List(filter, p)
+	if err != nil {
+		respondError(c, err, "获取评论列表失败")
 		return
 	}
 
+	for i := range comments {
+		hideCommentAuthor(&comments[i], viewerID(c))
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
-		Data: gin.H{
-			"list":  comments,
-			"total": total,
-		},
+		Data:    page.Data(comments),
 	})
 }
 
 // GetComment 获取评论详情
-func GetComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) GetComment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的评论ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的评论ID"))
 		return
 	}
 
-	var comment model.Comment
-	result := db.Preload("Author").First(&comment, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "评论不存在",
-			Data:    nil,
-		})
+	comment, err := h.comments.View(uint(id))
+	if err != nil {
+		respondError(c, err, "获取评论失败")
 		return
 	}
+	hideCommentAuthor(&comment, viewerID(c))
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
@@ -155,71 +129,25 @@ func GetComment(c *gin.Context) {
 }
 
 // LikeComment 点赞评论
-func LikeComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) LikeComment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的评论ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的评论ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查评论是否存在
-	var comment model.Comment
-	result := db.First(&comment, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "评论不存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查是否已经点赞
-	var existingLike model.CommentLike
-	likeResult := db.Where("comment_id = ? AND user_id = ?", id, userID.(uint)).First(&existingLike)
-	if likeResult.Error == nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "已经点赞过了",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 创建点赞记录
-	like := model.CommentLike{
-		CommentID: uint(id),
-		UserID:    userID.(uint),
-	}
-	if err := db.Create(&like).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "点赞失败",
-			Data:    nil,
-		})
+	if err := h.comments.Like(uint(id), userID.(uint)); err != nil {
+		respondError(c, err, "点赞失败")
 		return
 	}
 
-	// 更新评论点赞数
-	db.Model(&comment).UpdateColumn("likes", comment.Likes+1)
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "点赞成功",
@@ -228,69 +156,25 @@ func LikeComment(c *gin.Context) {
 }
 
 // UnlikeComment 取消点赞评论
-func UnlikeComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) UnlikeComment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的评论ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的评论ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查评论是否存在
-	var comment model.Comment
-	result := db.First(&comment, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "评论不存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查是否已点赞
-	var existingLike model.CommentLike
-	likeResult := db.Where("comment_id = ? AND user_id = ?", id, userID.(uint)).First(&existingLike)
-	if likeResult.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "还没有点赞",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 删除点赞记录
-	if err := db.Delete(&existingLike).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "取消点赞失败",
-			Data:    nil,
-		})
+	if err := h.comments.Unlike(uint(id), userID.(uint)); err != nil {
+		respondError(c, err, "取消点赞失败")
 		return
 	}
 
-	// 更新评论点赞数
-	if comment.Likes > 0 {
-		db.Model(&comment).UpdateColumn("likes", comment.Likes-1)
-	}
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "取消点赞成功",
@@ -299,59 +183,23 @@ func UnlikeComment(c *gin.Context) {
 }
 
 // DeleteComment 删除评论
-func DeleteComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的评论ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的评论ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查评论是否存在
-	var comment model.Comment
-	result := db.First(&comment, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "评论不存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查是否是评论作者
-	if comment.AuthorID != userID.(uint) {
-		c.JSON(http.StatusOK, Response{
-			Code:    403,
-			Message: "无权删除此评论",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 软删除评论
-	if err := db.Delete(&comment).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "删除评论失败",
-			Data:    nil,
-		})
+	// 仅评论作者可以删除，软删除评论
+	if err := h.comments.Delete(uint(id), userID.(uint)); err != nil {
+		respondError(c, err, "删除评论失败")
 		return
 	}
 
@@ -363,80 +211,56 @@ func DeleteComment(c *gin.Context) {
 }
 
 // ReplyComment 回复评论
-func ReplyComment(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *CommentHandler) ReplyComment(c *gin.Context) {
 	parentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的评论ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的评论ID"))
 		return
 	}
 
 	var req ReplyCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
 	// 检查父评论是否存在
-	var parentComment model.Comment
-	result := db.First(&parentComment, parentID)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "评论不存在",
-			Data:    nil,
-		})
+	parent, err := h.comments.Find(uint(parentID))
+	if err != nil {
+		respondError(c, err, "回复失败")
 		return
 	}
 
-	// 创建回复评论
-	parentIDUint := uint(parentID)
-	comment := model.Comment{
-		TargetID:   parentComment.TargetID,
-		TargetType: parentComment.TargetType,
-		Content:    req.Content,
-		AuthorID:   userID.(uint),
-		ParentID:   &parentIDUint,
-		Likes:      0,
-		Status:     1,
+	review, ok := filterText(c, &req.Content)
+	if !ok {
+		return
 	}
 
-	result = db.Create(&comment)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "回复失败",
-			Data:    nil,
-		})
+	// 创建回复评论，与父评论属于同一目标
+	comment := model.Comment{
+		Content:  req.Content,
+		AuthorID: userID.(uint),
+	}
+	if err := h.comments.Reply(parent, &comment, review); err != nil {
+		respondError(c, err, "回复失败")
 		return
 	}
 
-	// 预加载作者信息
-	db.Preload("Author").First(&comment, comment.ID)
+	message := "回复成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "回复成功",
+		Message: message,
 		Data:    comment,
 	})
 }
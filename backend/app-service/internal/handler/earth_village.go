@@ -4,86 +4,63 @@ import (
 	"net/http"
 	"strconv"
 
-	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// VillageHandler 地球村模块
+type VillageHandler struct {
+	villages *service.VillageService
+}
+
+func NewVillageHandler(villages *service.VillageService) *VillageHandler {
+	return &VillageHandler{villages: villages}
+}
+
 type CreatePostRequest struct {
-	Content string `json:"content" binding:"required"`
-	Images  string `json:"images"`
+	Content   string `json:"content" binding:"required"`
+	Images    string `json:"images"`
+	Anonymous bool   `json:"anonymous"`
 }
 
 type ReplyPostRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content   string `json:"content" binding:"required"`
+	Anonymous bool   `json:"anonymous"`
+}
+
+type EditPostRequest struct {
+	Content string  `json:"content" binding:"required"`
+	Images  *string `json:"images"`
 }
 
-func JoinVillage(c *gin.Context) {
-	db := config.GetDB()
+type EditReplyRequest struct {
+	Content string `json:"content" binding:"required"`
+}
 
+func (h *VillageHandler) JoinVillage(c *gin.Context) {
 	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的村落ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查村落是否存在
-	var village model.Village
-	if result := db.First(&village, villageID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "村落不存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查是否已加入
-	var existingMember model.VillageMember
-	if result := db.Where("village_id = ? AND user_id = ?", villageID, userID.(uint)).First(&existingMember); result.Error == nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "已加入该村落",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 创建成员记录
-	member := model.VillageMember{
-		VillageID: uint(villageID),
-		UserID:    userID.(uint),
-		Role:      0,
-	}
-
-	if err := db.Create(&member).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "加入村落失败",
-			Data:    nil,
-		})
+	if err := h.villages.Join(uint(villageID), userID.(uint)); err != nil {
+		respondError(c, err, "加入村落失败")
 		return
 	}
 
-	// 更新村落成员数
-	db.Model(&village).UpdateColumn("member_count", village.MemberCount+1)
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "加入成功",
@@ -91,67 +68,26 @@ func JoinVillage(c *gin.Context) {
 	})
 }
 
-func LeaveVillage(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) LeaveVillage(c *gin.Context) {
 	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的村落ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查村落是否存在
-	var village model.Village
-	if result := db.First(&village, villageID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "村落不存在",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 查找成员记录
-	var member model.VillageMember
-	if result := db.Where("village_id = ? AND user_id = ?", villageID, userID.(uint)).First(&member); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "未加入该村落",
-			Data:    nil,
-		})
+	// 删除成员记录，同时退出村落聊天室
+	if err := h.villages.Leave(uint(villageID), userID.(uint)); err != nil {
+		respondError(c, err, "退出村落失败")
 		return
 	}
 
-	// 删除成员记录
-	if err := db.Delete(&member).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "退出村落失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 更新村落成员数
-	if village.MemberCount > 0 {
-		db.Model(&village).UpdateColumn("member_count", village.MemberCount-1)
-	}
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "退出成功",
@@ -159,25 +95,12 @@ func LeaveVillage(c *gin.Context) {
 	})
 }
 
-func GetVillages(c *gin.Context) {
-	db := config.GetDB()
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-
-	var villages []model.Village
-	var total int64
-
-	db.Model(&model.Village{}).Where("status = ?", 1).Count(&total)
+func (h *VillageHandler) GetVillages(c *gin.Context) {
+	page, pageSize := pagination.PageParams(c, 10)
 
-	offset := (page - 1) * pageSize
-	result := db.Where("status = ?", 1).Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&villages)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取村落列表失败",
-			Data:    nil,
-		})
+	villages, total, err := h.villages.List(page, pageSize)
+	if err != nil {
+		respondError(c, err, "获取村落列表失败")
 		return
 	}
 
@@ -191,27 +114,16 @@ func GetVillages(c *gin.Context) {
 	})
 }
 
-func GetVillage(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) GetVillage(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的村落ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
 		return
 	}
 
-	var village model.Village
-	result := db.First(&village, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "村落不存在",
-			Data:    nil,
-		})
+	village, err := h.villages.Get(uint(id))
+	if err != nil {
+		respondError(c, err, "获取村落失败")
 		return
 	}
 
@@ -222,328 +134,217 @@ func GetVillage(c *gin.Context) {
 	})
 }
 
-func CreatePost(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) CreatePost(c *gin.Context) {
 	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的村落ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
 		return
 	}
 
 	var req CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查村落是否存在
-	var village model.Village
-	if result := db.First(&village, villageID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "村落不存在",
-			Data:    nil,
-		})
+	// 根据村落发帖策略校验权限
+	village, err := h.villages.CheckPost(uint(villageID), userID.(uint), req.Anonymous)
+	if err != nil {
+		respondError(c, err, "发布帖子失败")
 		return
 	}
 
-	post := model.Post{
-		VillageID: uint(villageID),
-		AuthorID:  userID.(uint),
-		Content:   req.Content,
-		Images:    req.Images,
-		Likes:     0,
-		Comments:  0,
-		Status:    1,
+	review, ok := filterText(c, &req.Content)
+	if !ok {
+		return
 	}
 
-	if err := db.Create(&post).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "发布帖子失败",
-			Data:    nil,
-		})
+	post := model.Post{
+		AuthorID:    userID.(uint),
+		Content:     req.Content,
+		Images:      req.Images,
+		IsAnonymous: req.Anonymous,
+	}
+	if err := h.villages.CreatePost(village, &post, review); err != nil {
+		respondError(c, err, "发布帖子失败")
 		return
 	}
 
-	// 更新村落帖子数
-	db.Model(&village).UpdateColumn("post_count", village.PostCount+1)
+	message := "发布成功"
+	if post.Status == model.PostStatusPending {
+		message = "已提交，等待管理员审核"
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "发布成功",
+		Message: message,
 		Data: gin.H{
-			"id": post.ID,
+			"id":       post.ID,
+			"status":   post.Status,
+			"mentions": post.Mentions,
 		},
 	})
 }
 
-func GetPosts(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) GetPosts(c *gin.Context) {
 	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的村落ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-
-	var posts []model.Post
-	var total int64
-
-	db.Model(&model.Post{}).Where("village_id = ? AND status = ?", villageID, 1).Count(&total)
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
 
-	offset := (page - 1) * pageSize
-	result := db.Where("village_id = ? AND status = ?", villageID, 1).
-		Preload("Author").
-		Order("created_at DESC").
-		Limit(pageSize).Offset(offset).
-		Find(&posts)
+	order, since, ok := parseListSort(c, p)
+	if !ok {
+		return
+	}
 
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取帖子列表失败",
-			Data:    nil,
-		})
+	posts, page, err := h.villages.Posts(uint(villageID), repository.PostFilter{Since: since, Order: order}, p)
+	if err != nil {
+		respondError(c, err, "获取帖子列表失败")
 		return
 	}
 
+	for i := range posts {
+		hidePostAuthor(&posts[i], viewerID(c))
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
-		Data: gin.H{
-			"list":  posts,
-			"total": total,
-		},
+		Data:    page.Data(posts),
 	})
 }
 
-func LikePost(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) LikePost(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的帖子ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查帖子是否存在
-	var post model.Post
-	if result := db.First(&post, postID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "帖子不存在",
-			Data:    nil,
-		})
+	if err := h.villages.LikePost(uint(postID), userID.(uint)); err != nil {
+		respondError(c, err, "点赞失败")
 		return
 	}
 
-	// 检查是否已点赞
-	var existingLike model.PostLike
-	if result := db.Where("post_id = ? AND user_id = ?", postID, userID.(uint)).First(&existingLike); result.Error == nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "已经点赞过了",
-			Data:    nil,
-		})
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "点赞成功",
+		Data:    nil,
+	})
+}
+
+func (h *VillageHandler) UnlikePost(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
 		return
 	}
 
-	// 创建点赞记录
-	like := model.PostLike{
-		PostID: uint(postID),
-		UserID: userID.(uint),
-	}
-	if err := db.Create(&like).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "点赞失败",
-			Data:    nil,
-		})
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 更新帖子点赞数
-	db.Model(&post).UpdateColumn("likes", post.Likes+1)
+	if err := h.villages.UnlikePost(uint(postID), userID.(uint)); err != nil {
+		respondError(c, err, "取消点赞失败")
+		return
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "点赞成功",
+		Message: "取消点赞成功",
 		Data:    nil,
 	})
 }
 
-func UnlikePost(c *gin.Context) {
-	db := config.GetDB()
-
+// EditPost 编辑帖子，仅作者本人可操作（匿名帖子同样适用）
+func (h *VillageHandler) EditPost(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的帖子ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
+		return
+	}
+
+	var req EditPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查帖子是否存在
-	var post model.Post
-	if result := db.First(&post, postID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "帖子不存在",
-			Data:    nil,
-		})
+	post, err := h.villages.OwnPost(uint(postID), userID.(uint))
+	if err != nil {
+		respondError(c, err, "编辑帖子失败")
 		return
 	}
 
-	// 检查是否已点赞
-	var existingLike model.PostLike
-	if result := db.Where("post_id = ? AND user_id = ?", postID, userID.(uint)).First(&existingLike); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "还没有点赞",
-			Data:    nil,
-		})
+	if !filterStrict(c, &req.Content) {
 		return
 	}
 
-	// 删除点赞记录
-	if err := db.Delete(&existingLike).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "取消点赞失败",
-			Data:    nil,
-		})
+	mentions, err := h.villages.EditPost(&post, req.Content, req.Images)
+	if err != nil {
+		respondError(c, err, "编辑帖子失败")
 		return
 	}
 
-	// 更新帖子点赞数
-	if post.Likes > 0 {
-		db.Model(&post).UpdateColumn("likes", post.Likes-1)
-	}
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "取消点赞成功",
-		Data:    nil,
+		Message: "编辑成功",
+		Data: gin.H{
+			"mentions": mentions,
+		},
 	})
 }
 
-func DeletePost(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) DeletePost(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的帖子ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查帖子是否存在
-	var post model.Post
-	if result := db.First(&post, postID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "帖子不存在",
-			Data:    nil,
-		})
+	// 帖子作者或村落管理员可以删除
+	if err := h.villages.DeletePost(uint(postID), userID.(uint)); err != nil {
+		respondError(c, err, "删除帖子失败")
 		return
 	}
 
-	// 检查是否是帖子作者
-	if post.AuthorID != userID.(uint) {
-		c.JSON(http.StatusOK, Response{
-			Code:    403,
-			Message: "无权删除此帖子",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 软删除帖子
-	if err := db.Delete(&post).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "删除帖子失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 更新村落帖子数
-	var village model.Village
-	if result := db.First(&village, post.VillageID); result.Error == nil && village.PostCount > 0 {
-		db.Model(&village).UpdateColumn("post_count", village.PostCount-1)
-	}
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "删除成功",
@@ -551,127 +352,141 @@ func DeletePost(c *gin.Context) {
 	})
 }
 
-func ReplyPost(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *VillageHandler) ReplyPost(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的帖子ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
 		return
 	}
 
 	var req ReplyPostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查帖子是否存在
-	var post model.Post
-	if result := db.First(&post, postID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "帖子不存在",
-			Data:    nil,
-		})
+	post, err := h.villages.CheckReply(uint(postID), req.Anonymous)
+	if err != nil {
+		respondError(c, err, "回复失败")
 		return
 	}
 
-	// 创建评论
-	comment := model.Comment{
-		TargetID:   uint(postID),
-		TargetType: "post",
-		Content:    req.Content,
-		AuthorID:   userID.(uint),
-		Likes:      0,
-		Status:     1,
+	review, ok := filterText(c, &req.Content)
+	if !ok {
+		return
 	}
 
-	if err := db.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "回复失败",
-			Data:    nil,
-		})
+	// 匿名回复沿用作者在该帖子中的化名
+	comment := model.Comment{
+		Content:     req.Content,
+		AuthorID:    userID.(uint),
+		IsAnonymous: req.Anonymous,
+	}
+	if err := h.villages.Reply(post, &comment, review); err != nil {
+		respondError(c, err, "回复失败")
 		return
 	}
 
-	// 更新帖子评论数
-	db.Model(&post).UpdateColumn("comments", post.Comments+1)
+	message := "回复成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "回复成功",
+		Message: message,
 		Data: gin.H{
-			"id": comment.ID,
+			"id":       comment.ID,
+			"status":   comment.Status,
+			"mentions": comment.Mentions,
 		},
 	})
 }
 
-func GetReplies(c *gin.Context) {
-	db := config.GetDB()
+func (h *VillageHandler) GetReplies(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
+		return
+	}
+
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
+
+	comments, page, err := h.villages.Replies(uint(postID), p)
+	if err != nil {
+		respondError(c, err, "获取回复列表失败")
+		return
+	}
+
+	for i := range comments {
+		hideCommentAuthor(&comments[i], viewerID(c))
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    page.Data(comments),
+	})
+}
 
+// EditReply 编辑帖子回复，仅作者本人可操作（匿名回复同样适用）
+func (h *VillageHandler) EditReply(c *gin.Context) {
 	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的帖子ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	replyID, err := strconv.ParseUint(c.Param("replyId"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的回复ID"))
+		return
+	}
 
-	var comments []model.Comment
-	var total int64
+	var req EditReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
 
-	db.Model(&model.Comment{}).
-		Where("target_id = ? AND target_type = ? AND status = ?", postID, "post", 1).
-		Count(&total)
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
 
-	offset := (page - 1) * pageSize
-	result := db.Where("target_id = ? AND target_type = ? AND status = ?", postID, "post", 1).
-		Preload("Author").
-		Order("created_at DESC").
-		Limit(pageSize).Offset(offset).
-		Find(&comments)
+	comment, err := h.villages.OwnReply(uint(postID), uint(replyID), userID.(uint))
+	if err != nil {
+		respondError(c, err, "编辑回复失败")
+		return
+	}
+
+	if !filterStrict(c, &req.Content) {
+		return
+	}
 
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取回复列表失败",
-			Data:    nil,
-		})
+	mentions, err := h.villages.EditReply(&comment, req.Content)
+	if err != nil {
+		respondError(c, err, "编辑回复失败")
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "",
+		Message: "编辑成功",
 		Data: gin.H{
-			"list":  comments,
-			"total": total,
+			"mentions": mentions,
 		},
 	})
 }
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"ai-egg/app-service/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError 输出服务层返回的错误，业务错误原样返回，其余错误记录日志后按fallback返回服务器错误
+func respondError(c *gin.Context, err error, fallback string) {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		apperr.Abort(c, appErr)
+		return
+	}
+	log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+	apperr.Abort(c, apperr.Internal(fallback))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"log"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/filter"
+	"ai-egg/app-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// filterHoldMessage 内容转人工审核时的提示
+const filterHoldMessage = "内容已提交，审核通过后展示"
+
+// filterText 对用户提交的文本执行内容过滤，命中mask规则的部分直接替换。
+// 被拒绝时已写入响应并返回ok为false；review.Hold为true表示内容需人工审核后才能展示
+func filterText(c *gin.Context, texts ...*string) (review service.Review, ok bool) {
+	f := filter.Get()
+	if f == nil {
+		return review, true
+	}
+
+	action := filter.ActionPass
+	for _, text := range texts {
+		if *text == "" {
+			continue
+		}
+		result, err := f.Check(c.Request.Context(), *text)
+		if err != nil {
+			log.Printf("Content filter failed: %v", err)
+			continue
+		}
+		*text = result.Text
+		if result.Action > action {
+			action = result.Action
+		}
+		review.Reasons = append(review.Reasons, result.Reasons...)
+	}
+
+	if action == filter.ActionReject {
+		apperr.Abort(c, apperr.BadRequest("内容包含违规信息，请修改后再提交").WithCode(apperr.CodeContentRejected))
+		return review, false
+	}
+	review.Hold = action == filter.ActionHold
+	return review, true
+}
+
+// filterStrict 过滤编辑后的文本或聊天消息。这类内容无法先保存再审核，
+// 需人工审核的内容同样拒绝提交，失败时已写入响应
+func filterStrict(c *gin.Context, texts ...*string) bool {
+	review, ok := filterText(c, texts...)
+	if !ok {
+		return false
+	}
+	if review.Hold {
+		apperr.Abort(c, apperr.BadRequest("内容包含疑似违规信息，请修改后再提交").WithCode(apperr.CodeContentRejected))
+		return false
+	}
+	return true
+}
@@ -5,12 +5,22 @@ import (
 	"strconv"
 	"strings"
 
-	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// NoteHandler 笔记模块
+type NoteHandler struct {
+	notes *service.NoteService
+}
+
+func NewNoteHandler(notes *service.NoteService) *NoteHandler {
+	return &NoteHandler{notes: notes}
+}
+
 type CreateNoteRequest struct {
 	Title    string   `json:"title" binding:"required"`
 	Content  string   `json:"content" binding:"required"`
@@ -18,124 +28,99 @@ type CreateNoteRequest struct {
 	Tags     []string `json:"tags"`
 }
 
-func CreateNote(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *NoteHandler) CreateNote(c *gin.Context) {
 	var req CreateNoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
 	// 将tags数组转换为逗号分隔的字符串
 	tagsStr := strings.Join(req.Tags, ",")
 
+	review, ok := filterText(c, &req.Title, &req.Content)
+	if !ok {
+		return
+	}
+
 	note := model.Note{
 		Title:    req.Title,
 		Content:  req.Content,
 		Category: req.Category,
 		Tags:     tagsStr,
 		AuthorID: userID.(uint),
-		Status:   1,
 	}
-
-	result := db.Create(&note)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "创建笔记失败",
-			Data:    nil,
-		})
+	if err := h.notes.Create(&note, review); err != nil {
+		respondError(c, err, "创建笔记失败")
 		return
 	}
 
+	message := "发布成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "发布成功",
+		Message: message,
 		Data: gin.H{
-			"id": note.ID,
+			"id":       note.ID,
+			"status":   note.Status,
+			"mentions": note.Mentions,
 		},
 	})
 }
 
-func GetNotes(c *gin.Context) {
-	db := config.GetDB()
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	category := c.Query("category")
-
-	var notes []model.Note
-	query := db.Model(&model.Note{}).Where("status = ?", 1)
+func (h *NoteHandler) GetNotes(c *gin.Context) {
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
 
 	// 根据分类筛选
-	if category != "" {
-		query = query.Where("category = ?", category)
+	notes, page, err := h.notes.List(c.Query("category"), p)
+	if err != nil {
+		respondError(c, err, "获取笔记列表失败")
+		return
 	}
 
-	var total int64
-	query.Count(&total)
-
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&notes)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取笔记列表失败",
-			Data:    nil,
-		})
-		return
+	if viewerID(c) == 0 {
+		for i := range notes {
+			hideEmail(&notes[i].Author)
+		}
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
-		Data: gin.H{
-			"list":  notes,
-			"total": total,
-		},
+		Data:    page.Data(notes),
 	})
 }
 
-func GetNote(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *NoteHandler) GetNote(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的笔记ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的笔记ID"))
 		return
 	}
 
-	var note model.Note
-	result := db.Preload("Author").First(&note, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "笔记不存在",
-			Data:    nil,
-		})
+	note, err := h.notes.View(uint(id), viewerID(c))
+	if err != nil {
+		respondError(c, err, "获取笔记详情失败")
 		return
 	}
 
+	if viewerID(c) == 0 {
+		hideEmail(&note.Author)
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
@@ -143,18 +128,11 @@ func GetNote(c *gin.Context) {
 	})
 }
 
-func GetNoteCategories(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *NoteHandler) GetNoteCategories(c *gin.Context) {
 	// 获取所有不重复的分类
-	var categories []string
-	result := db.Model(&model.Note{}).Where("status = ?", 1).Distinct().Pluck("category", &categories)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取分类列表失败",
-			Data:    nil,
-		})
+	categories, err := h.notes.Categories()
+	if err != nil {
+		respondError(c, err, "获取分类列表失败")
 		return
 	}
 
@@ -181,36 +159,27 @@ func GetNoteCategories(c *gin.Context) {
 	})
 }
 
-func GetNotesByCategory(c *gin.Context) {
-	db := config.GetDB()
-
-	category := c.Param("id")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-
-	var notes []model.Note
-	query := db.Model(&model.Note{}).Where("status = ? AND category = ?", 1, category)
-
-	var total int64
-	query.Count(&total)
+func (h *NoteHandler) GetNotesByCategory(c *gin.Context) {
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
 
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&notes)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取笔记列表失败",
-			Data:    nil,
-		})
+	notes, page, err := h.notes.List(c.Param("id"), p)
+	if err != nil {
+		respondError(c, err, "获取笔记列表失败")
 		return
 	}
 
+	if viewerID(c) == 0 {
+		for i := range notes {
+			hideEmail(&notes[i].Author)
+		}
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
-		Data: gin.H{
-			"list":  notes,
-			"total": total,
-		},
+		Data:    page.Data(notes),
 	})
 }
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/notify"
+	"ai-egg/app-service/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NotificationGroup 合并后的通知，展示同组中最新的一条
+type NotificationGroup struct {
+	model.Notification
+	ActorCount  int64  `json:"actor_count"`
+	UnreadCount int64  `json:"unread_count"`
+	Summary     string `json:"summary"`
+}
+
+// GetNotifications 获取通知列表，同一内容的点赞等通知合并为一条
+func GetNotifications(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	page, pageSize := pagination.PageParams(c, 20)
+	notifyType := c.Query("type")
+
+	query := db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if notifyType != "" {
+		query = query.Where("type = ?", notifyType)
+	}
+
+	var total int64
+	query.Session(&gorm.Session{}).Distinct("group_key").Count(&total)
+
+	var rows []struct {
+		GroupKey    string
+		LastID      uint
+		ActorCount  int64
+		UnreadCount int64
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Session(&gorm.Session{}).
+		Select("group_key, MAX(id) AS last_id, COUNT(DISTINCT actor_id) AS actor_count, " +
+			"SUM(CASE WHEN read_at IS NULL THEN 1 ELSE 0 END) AS unread_count").
+		Group("group_key").
+		Order("last_id DESC").
+		Limit(pageSize).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("获取通知失败"))
+		return
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.LastID
+	}
+	var latest []model.Notification
+	db.Preload("Actor").Where("id IN ?", ids).Find(&latest)
+	byID := make(map[uint]model.Notification, len(latest))
+	for _, n := range latest {
+		byID[n.ID] = n
+	}
+
+	groups := make([]NotificationGroup, 0, len(rows))
+	for _, row := range rows {
+		n, ok := byID[row.LastID]
+		if !ok {
+			continue
+		}
+		hideEmail(&n.Actor)
+		groups = append(groups, NotificationGroup{
+			Notification: n,
+			ActorCount:   row.ActorCount,
+			UnreadCount:  row.UnreadCount,
+			Summary:      notify.Summary(n, row.ActorCount),
+		})
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":   groups,
+			"total":  total,
+			"unread": notify.CountUnread(db, userID),
+		},
+	})
+}
+
+// GetUnreadNotificationCount 获取未读通知数，包含按类型的统计
+func GetUnreadNotificationCount(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	db.Model(&model.Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Group("type").
+		Scan(&rows)
+
+	total := int64(0)
+	byType := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		byType[row.Type] = row.Count
+		total += row.Count
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"total":  total,
+			"byType": byType,
+		},
+	})
+}
+
+// MarkNotificationRead 将一条通知标记为已读，合并展示的同组通知一并标记
+func MarkNotificationRead(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的通知ID"))
+		return
+	}
+
+	var notification model.Notification
+	if result := db.Where("user_id = ?", userID).First(&notification, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("通知不存在"))
+		return
+	}
+
+	if err := db.Model(&model.Notification{}).
+		Where("user_id = ? AND group_key = ? AND read_at IS NULL", userID, notification.GroupKey).
+		Update("read_at", time.Now()).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("操作失败"))
+		return
+	}
+	notify.PublishUnread(db, userID)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    nil,
+	})
+}
+
+// MarkAllNotificationsRead 将全部通知标记为已读，可通过type只标记某一类
+func MarkAllNotificationsRead(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	query := db.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if notifyType := c.Query("type"); notifyType != "" {
+		query = query.Where("type = ?", notifyType)
+	}
+
+	result := query.Update("read_at", time.Now())
+	if result.Error != nil {
+		apperr.Abort(c, apperr.Internal("操作失败"))
+		return
+	}
+	if result.RowsAffected > 0 {
+		notify.PublishUnread(db, userID)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"updated": result.RowsAffected,
+		},
+	})
+}
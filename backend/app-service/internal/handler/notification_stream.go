@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/notify"
+	"ai-egg/app-service/internal/pubsub"
+	"ai-egg/app-service/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// streamHeartbeat 心跳间隔，同时在心跳时校验登录状态
+	streamHeartbeat = 25 * time.Second
+	// streamRetry 建议客户端断线后的重连间隔（毫秒）
+	streamRetry = 3000
+	// streamResumeLimit 断线重连时最多补发的通知数
+	streamResumeLimit = 100
+)
+
+// StreamNotifications 通过SSE推送新通知和未读数。
+// 携带Last-Event-ID（或lastEventId参数）重连时，先补发该ID之后的通知
+func StreamNotifications(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	broker := pubsub.Get()
+	if broker == nil {
+		apperr.Abort(c, apperr.Unavailable("实时推送不可用"))
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			apperr.Abort(c, apperr.BadRequest("无效的Last-Event-ID"))
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	// 先订阅再补发，避免两者之间产生的通知丢失
+	sub, err := broker.Subscribe(ctx, notify.Channel(userID))
+	if err != nil {
+		log.Printf("Failed to subscribe notifications for user %d: %v", userID, err)
+		apperr.Abort(c, apperr.Unavailable("实时推送不可用"))
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+
+	sent := uint(lastID)
+	if lastID > 0 {
+		var missed []model.Notification
+		db.Preload("Actor").
+			Where("user_id = ? AND id > ?", userID, lastID).
+			Order("id ASC").Limit(streamResumeLimit).
+			Find(&missed)
+		unread := notify.CountUnread(db, userID)
+		for _, n := range missed {
+			writeStreamEvent(c, notify.NotificationEvent(n, unread))
+			sent = n.ID
+		}
+	}
+	writeStreamEvent(c, notify.UnreadEvent(notify.CountUnread(db, userID)))
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			var event notify.Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				continue
+			}
+			// 补发过的通知不再重复推送
+			if event.ID != 0 {
+				if event.ID <= sent {
+					continue
+				}
+				sent = event.ID
+			}
+			writeStreamEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			// 令牌过期、被吊销或账号被禁用时结束推送，客户端换新令牌后重连
+			if !streamAuthorized(c, db, userID) {
+				return
+			}
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// CreateStreamTicket 签发建立推送连接用的一次性票据，须在有效期内通过ticket参数使用
+func CreateStreamTicket(c *gin.Context) {
+	ticket, err := token.GetService().IssueStreamTicket(currentClaims(c))
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("获取推送票据失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"ticket":    ticket,
+			"expiresIn": int64(token.StreamTicketTTL.Seconds()),
+		},
+	})
+}
+
+// streamAuthorized 校验推送连接的登录状态是否仍然有效
+func streamAuthorized(c *gin.Context, db *gorm.DB, userID uint) bool {
+	value, _ := c.Get("tokenClaims")
+	claims, ok := value.(*token.Claims)
+	if !ok {
+		return false
+	}
+	if claims.ExpiresAt != nil && time.Now().After(claims.ExpiresAt.Time) {
+		return false
+	}
+	if token.GetService().IsRevoked(claims) {
+		return false
+	}
+	_, err := account.Check(db, userID)
+	return err == nil
+}
+
+// writeStreamEvent 按SSE格式写出事件
+func writeStreamEvent(c *gin.Context, event notify.Event) {
+	if event.ID != 0 {
+		fmt.Fprintf(c.Writer, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Event, event.Data)
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/oauth"
+	"ai-egg/app-service/internal/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// errIdentityTaken 第三方账号已绑定到其他用户
+var errIdentityTaken = errors.New("identity already linked to another user")
+
+// GetOAuthProviders 获取已启用的第三方登录方式
+func GetOAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    oauth.ProviderNames(),
+	})
+}
+
+// OAuthAuthorize 生成第三方授权地址。已登录用户传 link=true 时，回调后将第三方账号绑定到当前用户
+func OAuthAuthorize(c *gin.Context) {
+	provider, err := oauth.GetProvider(c.Param("provider"))
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound("不支持的登录方式"))
+		return
+	}
+
+	state := oauth.State{
+		Provider:     provider.Name,
+		Nonce:        oauth.RandomString(),
+		CodeVerifier: oauth.RandomString(),
+	}
+	if c.Query("link") == "true" {
+		state.LinkUserID = viewerID(c)
+		if state.LinkUserID == 0 {
+			apperr.Abort(c, apperr.Unauthorized("请先登录"))
+			return
+		}
+	}
+
+	key := oauth.RandomString()
+	if err := oauth.GetStateStore().Save(key, state); err != nil {
+		apperr.Abort(c, apperr.Internal("发起授权失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"url":   provider.AuthCodeURL(key, state.Nonce, state.CodeVerifier),
+			"state": key,
+		},
+	})
+}
+
+// OAuthCallback 前端回调页提交授权码，完成第三方登录或账号绑定
+func OAuthCallback(c *gin.Context) {
+	db := config.GetDB()
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	state, err := oauth.GetStateStore().Take(req.State)
+	if err != nil || state.Provider != c.Param("provider") {
+		apperr.Abort(c, apperr.BadRequest("授权已过期，请重新登录").WithCode(apperr.CodeLinkExpired))
+		return
+	}
+
+	provider, err := oauth.GetProvider(state.Provider)
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound("不支持的登录方式"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+	defer cancel()
+	profile, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("OAuth exchange with %s failed: %v", provider.Name, err)
+		apperr.Abort(c, apperr.BadRequest("第三方授权失败"))
+		return
+	}
+
+	// 绑定到已登录用户
+	if state.LinkUserID != 0 {
+		if err := linkIdentity(db, state.LinkUserID, provider.Name, profile); err != nil {
+			if errors.Is(err, errIdentityTaken) {
+				apperr.Abort(c, apperr.Conflict("该第三方账号已绑定其他用户").WithCode(apperr.CodeAlreadyExists))
+			} else {
+				apperr.Abort(c, apperr.BadRequest("绑定失败"))
+			}
+			return
+		}
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "绑定成功",
+			Data: gin.H{
+				"provider": provider.Name,
+			},
+		})
+		return
+	}
+
+	user, created, err := findOrCreateOAuthUser(db, provider.Name, profile)
+	if err != nil {
+		log.Printf("OAuth login with %s failed: %v", provider.Name, err)
+		apperr.Abort(c, apperr.Internal("登录失败"))
+		return
+	}
+	if created {
+		indexDocument(search.UserDocument(user))
+	}
+
+	if user.Status != model.UserStatusNormal {
+		apperr.Abort(c, apperr.Forbidden("账号已被禁用").WithCode(apperr.CodeAccountDisabled))
+		return
+	}
+
+	respondLogin(c, user)
+}
+
+// GetIdentities 获取当前用户绑定的第三方账号
+func GetIdentities(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var identities []model.UserIdentity
+	if result := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取绑定信息失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    identities,
+	})
+}
+
+// UnlinkIdentity 解除第三方账号绑定。未设置密码的用户至少保留一个第三方账号
+func UnlinkIdentity(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+	providerName := c.Param("provider")
+
+	var user model.User
+	if result := db.First(&user, userID); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return
+	}
+
+	var identity model.UserIdentity
+	if result := db.Where("user_id = ? AND provider = ?", userID, providerName).First(&identity); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("未绑定该第三方账号"))
+		return
+	}
+
+	if user.PasswordHash == "" {
+		var count int64
+		db.Model(&model.UserIdentity{}).Where("user_id = ?", userID).Count(&count)
+		if count <= 1 {
+			apperr.Abort(c, apperr.Conflict("请先设置密码再解除绑定"))
+			return
+		}
+	}
+
+	if result := db.Delete(&identity); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("解除绑定失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已解除绑定",
+		Data:    nil,
+	})
+}
+
+// linkIdentity 将第三方账号绑定到指定用户
+func linkIdentity(db *gorm.DB, userID uint, providerName string, profile *oauth.Profile) error {
+	var existing model.UserIdentity
+	if result := db.Where("provider = ? AND subject = ?", providerName, profile.Subject).First(&existing); result.Error == nil {
+		if existing.UserID != userID {
+			return errIdentityTaken
+		}
+		return nil
+	}
+
+	return db.Create(&model.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  profile.Subject,
+		Email:    profile.Email,
+	}).Error
+}
+
+// findOrCreateOAuthUser 查找第三方账号对应的用户。未绑定时，若第三方邮箱已验证且与本站已验证邮箱一致则自动绑定，
+// 否则创建新用户并使用第三方资料初始化用户名和头像
+func findOrCreateOAuthUser(db *gorm.DB, providerName string, profile *oauth.Profile) (model.User, bool, error) {
+	var user model.User
+	created := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var identity model.UserIdentity
+		if result := tx.Where("provider = ? AND subject = ?", providerName, profile.Subject).First(&identity); result.Error == nil {
+			return tx.First(&user, identity.UserID).Error
+		}
+
+		found := false
+		if profile.Email != "" && profile.EmailVerified {
+			result := tx.Where("email = ? AND email_verified = ?", profile.Email, true).Limit(1).Find(&user)
+			if result.Error != nil {
+				return result.Error
+			}
+			found = result.RowsAffected > 0
+		}
+
+		if !found {
+			username, err := uniqueUsername(tx, profile)
+			if err != nil {
+				return err
+			}
+			user = model.User{
+				Username: username,
+				Avatar:   profile.AvatarURL,
+				Status:   model.UserStatusNormal,
+			}
+			// 邮箱已被其他账号占用时不填写，用户可稍后自行设置
+			if profile.Email != "" {
+				var count int64
+				tx.Model(&model.User{}).Where("email = ?", profile.Email).Count(&count)
+				if count == 0 {
+					user.Email = &profile.Email
+					user.EmailVerified = profile.EmailVerified
+					if user.EmailVerified {
+						now := time.Now()
+						user.EmailVerifiedAt = &now
+					}
+				}
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			created = true
+		}
+
+		return tx.Create(&model.UserIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  profile.Subject,
+			Email:    profile.Email,
+		}).Error
+	})
+	return user, created, err
+}
+
+// uniqueUsername 根据第三方资料生成符合注册规则（3-20个字符）且未被占用的用户名
+func uniqueUsername(db *gorm.DB, profile *oauth.Profile) (string, error) {
+	candidate := ""
+	for _, s := range []string{profile.Username, profile.Name, strings.Split(profile.Email, "@")[0]} {
+		if candidate = sanitizeUsername(s); candidate != "" {
+			break
+		}
+	}
+	if len([]rune(candidate)) < 3 {
+		candidate = "user_" + candidate
+	}
+
+	name := candidate
+	for i := 0; i < 10; i++ {
+		var count int64
+		if err := db.Unscoped().Model(&model.User{}).Where("username = ?", name).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s_%04d", candidate, rand.Intn(10000))
+	}
+	return "", errors.New("failed to generate unique username")
+}
+
+// sanitizeUsername 只保留字母、数字和下划线，最多15个字符（为去重后缀留出空间）
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		if n >= 15 {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+			n++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination 解析列表的分页参数，参数无效时已写入响应
+func parsePagination(c *gin.Context, defaultLimit int) (pagination.Params, bool) {
+	p, err := pagination.Parse(c, defaultLimit)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			apperr.Abort(c, apperr.BadRequest("无效的游标").WithCode(apperr.CodeInvalidCursor))
+		} else {
+			apperr.Abort(c, apperr.BadRequest("无效的分页参数"))
+		}
+		return p, false
+	}
+	return p, true
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/mail"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword" binding:"required,min=6"`
+}
+
+// SendVerificationEmail 向当前用户的邮箱重新发送验证邮件
+func SendVerificationEmail(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var user model.User
+	if result := db.First(&user, userID); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return
+	}
+
+	if user.Email == nil {
+		apperr.Abort(c, apperr.BadRequest("请先设置邮箱"))
+		return
+	}
+	if user.EmailVerified {
+		apperr.Abort(c, apperr.Conflict("邮箱已验证"))
+		return
+	}
+
+	if err := sendVerificationEmail(db, user); err != nil {
+		apperr.Abort(c, apperr.Internal("发送验证邮件失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "验证邮件已发送",
+		Data:    nil,
+	})
+}
+
+// VerifyEmail 使用邮件中的令牌完成邮箱验证
+func VerifyEmail(c *gin.Context) {
+	db := config.GetDB()
+
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	row, err := account.ConsumeVerificationToken(db, model.TokenPurposeEmailVerify, req.Token)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("验证链接无效或已过期").WithCode(apperr.CodeLinkExpired))
+		return
+	}
+
+	// 邮箱在签发后被修改过，旧邮箱的验证链接不再有效
+	now := time.Now()
+	result := db.Model(&model.User{}).
+		Where("id = ? AND email = ?", row.UserID, row.Email).
+		Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": &now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		apperr.Abort(c, apperr.BadRequest("验证链接无效或已过期").WithCode(apperr.CodeLinkExpired))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "邮箱验证成功",
+		Data:    nil,
+	})
+}
+
+// ForgotPassword 向已验证的邮箱发送密码重置邮件。
+// 无论邮箱是否存在都返回成功，避免被用来探测注册邮箱
+func ForgotPassword(c *gin.Context) {
+	db := config.GetDB()
+
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	var user model.User
+	result := db.Where("email = ? AND email_verified = ? AND status = ?", req.Email, true, model.UserStatusNormal).First(&user)
+	if result.Error == nil {
+		raw, err := account.CreateVerificationToken(db, user.ID, model.TokenPurposePasswordReset, user.EmailAddress(), account.PasswordResetTTL)
+		if err != nil {
+			log.Printf("Failed to create password reset token for user %d: %v", user.ID, err)
+		} else if err := mail.GetMailer().Send(mail.Message{
+			To:      user.EmailAddress(),
+			Subject: "重置蛋蛋密码",
+			Body: fmt.Sprintf("%s，你好：\n\n请点击以下链接重置密码，链接%d分钟内有效且只能使用一次：\n%s\n\n如果不是你本人操作，请忽略本邮件。",
+				user.Username, int(account.PasswordResetTTL.Minutes()), mail.Link("/reset-password", raw)),
+		}); err != nil {
+			log.Printf("Failed to send password reset mail to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "如果该邮箱已绑定账号，重置邮件将很快送达",
+		Data:    nil,
+	})
+}
+
+// ResetPassword 使用邮件中的令牌重置密码，重置后该账号的全部设备需重新登录
+func ResetPassword(c *gin.Context) {
+	db := config.GetDB()
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	row, err := account.ConsumeVerificationToken(db, model.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("重置链接无效或已过期").WithCode(apperr.CodeLinkExpired))
+		return
+	}
+
+	var user model.User
+	if result := db.Where("id = ? AND email = ?", row.UserID, row.Email).First(&user); result.Error != nil {
+		apperr.Abort(c, apperr.BadRequest("重置链接无效或已过期").WithCode(apperr.CodeLinkExpired))
+		return
+	}
+
+	if err := setPassword(db, &user, req.Password); err != nil {
+		apperr.Abort(c, apperr.Internal("重置密码失败"))
+		return
+	}
+
+	if err := token.GetService().RevokeAllSessions(user.ID); err != nil {
+		log.Printf("Failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "密码已重置，请重新登录",
+		Data:    nil,
+	})
+}
+
+// ChangePassword 登录用户修改密码，其他设备随即下线
+func ChangePassword(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	var user model.User
+	if result := db.First(&user, userID); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("用户不存在"))
+		return
+	}
+
+	// 第三方登录创建的账号没有密码，首次设置时无需原密码
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.OldPassword)); err != nil {
+			apperr.Abort(c, apperr.BadRequest("原密码错误").WithCode(apperr.CodeInvalidCredentials))
+			return
+		}
+	}
+
+	if err := setPassword(db, &user, req.NewPassword); err != nil {
+		apperr.Abort(c, apperr.Internal("修改密码失败"))
+		return
+	}
+
+	if err := token.GetService().RevokeOtherSessions(user.ID, currentClaims(c).SessionID); err != nil {
+		log.Printf("Failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "密码修改成功",
+		Data:    nil,
+	})
+}
+
+// sendVerificationEmail 签发邮箱验证令牌并发送验证邮件
+func sendVerificationEmail(db *gorm.DB, user model.User) error {
+	raw, err := account.CreateVerificationToken(db, user.ID, model.TokenPurposeEmailVerify, user.EmailAddress(), account.EmailVerifyTTL)
+	if err != nil {
+		return err
+	}
+	return mail.GetMailer().Send(mail.Message{
+		To:      user.EmailAddress(),
+		Subject: "验证你的蛋蛋邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请点击以下链接完成邮箱验证，链接%d小时内有效：\n%s\n\n如果不是你本人操作，请忽略本邮件。",
+			user.Username, int(account.EmailVerifyTTL.Hours()), mail.Link("/verify-email", raw)),
+	})
+}
+
+// setPassword 加密并保存新密码
+func setPassword(db *gorm.DB, user *model.User, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return db.Model(user).Update("password_hash", string(hashedPassword)).Error
+}
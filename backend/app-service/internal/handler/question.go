@@ -4,12 +4,23 @@ import (
 	"net/http"
 	"strconv"
 
-	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// QuestionHandler 问答模块
+type QuestionHandler struct {
+	questions *service.QuestionService
+}
+
+func NewQuestionHandler(questions *service.QuestionService) *QuestionHandler {
+	return &QuestionHandler{questions: questions}
+}
+
 type CreateQuestionRequest struct {
 	Title   string   `json:"title" binding:"required"`
 	Content string   `json:"content" binding:"required"`
@@ -22,72 +33,59 @@ type CreateAnswerRequest struct {
 }
 
 // GetQuestions 获取问题列表
-func GetQuestions(c *gin.Context) {
-	db := config.GetDB()
-
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	category := c.Query("category")
-
-	var questions []model.Question
-	query := db.Model(&model.Question{}).Where("status = ?", 1)
+func (h *QuestionHandler) GetQuestions(c *gin.Context) {
+	p, ok := parsePagination(c, 10)
+	if !ok {
+		return
+	}
+	order, since, ok := parseListSort(c, p)
+	if !ok {
+		return
+	}
 
-	// 根据分类筛选
-	if category != "" && category != "recommend" {
-		query = query.Where("tags LIKE ?", "%"+category+"%")
+	filter := repository.QuestionFilter{Since: since, Order: order}
+	// 根据分类筛选，推荐分类不筛选
+	if category := c.Query("category"); category != "recommend" {
+		filter.Category = category
 	}
 
-	var total int64
-	query.Count(&total)
-
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&questions)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "获取问题列表失败",
-			Data:    nil,
-		})
+	questions, page, err := h.questions.List(filter, p, viewerID(c))
+	if err != nil {
+		respondError(c, err, "获取问题列表失败")
 		return
 	}
 
+	if viewerID(c) == 0 {
+		for i := range questions {
+			hideEmail(&questions[i].Author)
+		}
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
-		Data: gin.H{
-			"list":  questions,
-			"total": total,
-		},
+		Data:    page.Data(questions),
 	})
 }
 
 // GetQuestion 获取问题详情
-func GetQuestion(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *QuestionHandler) GetQuestion(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的问题ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的问题ID"))
 		return
 	}
 
-	var question model.Question
-	result := db.Preload("Author").First(&question, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "问题不存在",
-			Data:    nil,
-		})
+	// 查看时增加浏览量
+	question, err := h.questions.View(uint(id), viewerID(c), visitorKey(c))
+	if err != nil {
+		respondError(c, err, "获取问题详情失败")
 		return
 	}
 
-	// 增加浏览量
-	db.Model(&question).UpdateColumn("views", question.Views+1)
+	if viewerID(c) == 0 {
+		hideEmail(&question.Author)
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
@@ -97,27 +95,22 @@ func GetQuestion(c *gin.Context) {
 }
 
 // CreateQuestion 创建问题
-func CreateQuestion(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *QuestionHandler) CreateQuestion(c *gin.Context) {
 	var req CreateQuestionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	review, ok := filterText(c, &req.Title, &req.Content)
+	if !ok {
 		return
 	}
 
@@ -125,175 +118,103 @@ func CreateQuestion(c *gin.Context) {
 		Title:    req.Title,
 		Content:  req.Content,
 		AuthorID: userID.(uint),
-		Tags:     "",
-		Likes:    0,
-		Views:    0,
-		Status:   1,
 	}
-
-	result := db.Create(&question)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "创建问题失败",
-			Data:    nil,
-		})
+	// 同时检测重复问题，仅作为提示不阻止发布
+	duplicates, err := h.questions.Create(&question, review)
+	if err != nil {
+		respondError(c, err, "创建问题失败")
 		return
 	}
 
+	message := "发布成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "发布成功",
+		Message: message,
 		Data: gin.H{
-			"id": question.ID,
+			"id":         question.ID,
+			"status":     question.Status,
+			"duplicates": duplicates,
+			"mentions":   question.Mentions,
 		},
 	})
 }
 
 // CreateAnswer 创建回答
-func CreateAnswer(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *QuestionHandler) CreateAnswer(c *gin.Context) {
 	var req CreateAnswerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
 	// 检查问题是否存在
-	var question model.Question
-	result := db.First(&question, req.QuestionID)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "问题不存在",
-			Data:    nil,
-		})
+	question, err := h.questions.Find(req.QuestionID)
+	if err != nil {
+		respondError(c, err, "创建回答失败")
 		return
 	}
 
-	answer := model.Answer{
-		QuestionID: req.QuestionID,
-		Content:    req.Content,
-		AuthorID:   userID.(uint),
-		Likes:      0,
-		Status:     1,
+	review, ok := filterText(c, &req.Content)
+	if !ok {
+		return
 	}
 
-	result = db.Create(&answer)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "创建回答失败",
-			Data:    nil,
-		})
+	answer := model.Answer{
+		Content:  req.Content,
+		AuthorID: userID.(uint),
+	}
+	if err := h.questions.CreateAnswer(question, &answer, review); err != nil {
+		respondError(c, err, "创建回答失败")
 		return
 	}
 
+	message := "回答成功"
+	if review.Hold {
+		message = filterHoldMessage
+	}
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "回答成功",
+		Message: message,
 		Data: gin.H{
-			"id": answer.ID,
+			"id":       answer.ID,
+			"status":   answer.Status,
+			"mentions": answer.Mentions,
 		},
 	})
 }
 
 // LikeQuestion 点赞问题
-func LikeQuestion(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *QuestionHandler) LikeQuestion(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的问题ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的问题ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查问题是否存在
-	var question model.Question
-	result := db.First(&question, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "问题不存在",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查是否已经点赞
-	var existingLike model.QuestionLike
-	result = db.Where("question_id = ? AND user_id = ?", id, userID.(uint)).First(&existingLike)
-	if result.Error == nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "已经点赞过了",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 创建点赞记录
-	like := model.QuestionLike{
-		QuestionID: uint(id),
-		UserID:     userID.(uint),
-	}
-
-	// 使用事务
-	tx := db.Begin()
-	if err := tx.Create(&like).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "点赞失败",
-			Data:    nil,
-		})
+	if err := h.questions.Like(uint(id), userID.(uint)); err != nil {
+		respondError(c, err, "点赞失败")
 		return
 	}
 
-	// 增加点赞数
-	if err := tx.Model(&question).UpdateColumn("likes", question.Likes+1).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "点赞失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	tx.Commit()
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "点赞成功",
@@ -302,79 +223,25 @@ func LikeQuestion(c *gin.Context) {
 }
 
 // UnlikeQuestion 取消点赞问题
-func UnlikeQuestion(c *gin.Context) {
-	db := config.GetDB()
-
+func (h *QuestionHandler) UnlikeQuestion(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "无效的问题ID",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("无效的问题ID"))
 		return
 	}
 
 	// 从上下文获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 检查问题是否存在
-	var question model.Question
-	result := db.First(&question, id)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "问题不存在",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	// 检查是否已经点赞
-	var existingLike model.QuestionLike
-	result = db.Where("question_id = ? AND user_id = ?", id, userID.(uint)).First(&existingLike)
-	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "还没有点赞",
-			Data:    nil,
-		})
+	if err := h.questions.Unlike(uint(id), userID.(uint)); err != nil {
+		respondError(c, err, "取消点赞失败")
 		return
 	}
 
-	// 使用事务
-	tx := db.Begin()
-	if err := tx.Delete(&existingLike).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "取消点赞失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	// 减少点赞数
-	if err := tx.Model(&question).UpdateColumn("likes", question.Likes-1).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "取消点赞失败",
-			Data:    nil,
-		})
-		return
-	}
-
-	tx.Commit()
-
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "取消点赞成功",
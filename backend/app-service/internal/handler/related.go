@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ai-egg/app-service/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckSimilarRequest struct {
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content"`
+}
+
+// GetRelatedQuestions 获取与问题语义相关的问题和笔记
+func (h *QuestionHandler) GetRelatedQuestions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的问题ID"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	questions, notes, err := h.questions.Related(uint(id), limit)
+	if err != nil {
+		respondError(c, err, "获取相关问题失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"questions": questions,
+			"notes":     notes,
+		},
+	})
+}
+
+// CheckSimilarQuestions 发布问题前检测是否存在重复问题
+func (h *QuestionHandler) CheckSimilarQuestions(c *gin.Context) {
+	var req CheckSimilarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"duplicates": h.questions.Duplicates(req.Title, req.Content),
+		},
+	})
+}
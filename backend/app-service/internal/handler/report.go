@@ -0,0 +1,415 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/mail"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/notify"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateReportRequest struct {
+	TargetType string `json:"targetType" binding:"required,oneof=question answer note post comment message"`
+	TargetID   uint   `json:"targetId" binding:"required"`
+	Reason     string `json:"reason" binding:"required,oneof=spam abuse porn illegal other"`
+	Detail     string `json:"detail" binding:"max=500"`
+}
+
+type ResolveReportRequest struct {
+	Action     string `json:"action" binding:"required,oneof=hide dismiss"`
+	Resolution string `json:"resolution" binding:"max=500"`
+}
+
+// adminReport 管理后台展示的举报，包含被举报内容的作者
+type adminReport struct {
+	model.Report
+	TargetOwnerID uint `json:"target_owner_id"`
+}
+
+// adminReports 转换为管理后台展示的举报
+func adminReports(reports []model.Report) []adminReport {
+	list := make([]adminReport, len(reports))
+	for i, report := range reports {
+		list[i] = adminReport{Report: report, TargetOwnerID: report.TargetOwnerID}
+	}
+	return list
+}
+
+// reportReasonNames 举报原因的展示名称
+var reportReasonNames = map[string]string{
+	model.ReportReasonSpam:    "垃圾广告",
+	model.ReportReasonAbuse:   "辱骂攻击",
+	model.ReportReasonPorn:    "色情低俗",
+	model.ReportReasonIllegal: "违法违规",
+	model.ReportReasonOther:   "其他",
+	model.ReportReasonFilter:  "内容过滤",
+}
+
+// CreateReport 举报问题、回答、笔记、帖子、评论或聊天消息
+func CreateReport(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	content, err := loadContent(db, req.TargetType, req.TargetID)
+	if err == nil && content.Status == model.ContentStatusRemoved {
+		err = errContentNotFound
+	}
+	// 聊天消息只有会话参与者可以举报
+	if err == nil && req.TargetType == model.TargetTypeMessage && !canViewMessage(db, req.TargetID, userID) {
+		err = errContentNotFound
+	}
+	if err != nil {
+		if errors.Is(err, errContentNotFound) {
+			apperr.Abort(c, apperr.NotFound("内容不存在"))
+			return
+		}
+		apperr.Abort(c, apperr.Internal("举报失败"))
+		return
+	}
+
+	if content.OwnerID == userID {
+		apperr.Abort(c, apperr.Forbidden("不能举报自己的内容"))
+		return
+	}
+
+	var count int64
+	db.Model(&model.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", userID, req.TargetType, req.TargetID, model.ReportStatusOpen).
+		Count(&count)
+	if count > 0 {
+		apperr.Abort(c, apperr.Conflict("你已举报过该内容，请等待处理").WithCode(apperr.CodeAlreadyExists))
+		return
+	}
+
+	report := model.Report{
+		ReporterID:    userID,
+		TargetType:    req.TargetType,
+		TargetID:      req.TargetID,
+		TargetOwnerID: content.OwnerID,
+		Reason:        req.Reason,
+		Detail:        req.Detail,
+		Snapshot:      content.Text,
+		Status:        model.ReportStatusOpen,
+	}
+	if result := db.Create(&report); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("举报失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "举报成功，我们会尽快处理",
+		Data: gin.H{
+			"id": report.ID,
+		},
+	})
+}
+
+// GetMyReports 获取当前用户提交的举报及处理结果
+func GetMyReports(c *gin.Context) {
+	db := config.GetDB()
+	userID := c.GetUint("userID")
+
+	page, pageSize := pagination.PageParams(c, 20)
+
+	query := db.Model(&model.Report{}).Where("reporter_id = ?", userID)
+
+	var total int64
+	query.Count(&total)
+
+	var reports []model.Report
+	offset := (page - 1) * pageSize
+	// 举报人只能看到自己的举报，不返回内容快照
+	if result := query.Omit("snapshot").Order("id DESC").Limit(pageSize).Offset(offset).Find(&reports); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取举报记录失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  reports,
+			"total": total,
+		},
+	})
+}
+
+// GetReports 审核队列，默认返回待处理的举报，最早的在前
+func GetReports(c *gin.Context) {
+	db := config.GetDB()
+
+	page, pageSize := pagination.PageParams(c, 20)
+	status := c.DefaultQuery("status", model.ReportStatusOpen)
+
+	query := db.Model(&model.Report{}).Where("status = ?", status)
+	if targetType := c.Query("targetType"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	order := "id ASC"
+	if status != model.ReportStatusOpen {
+		order = "handled_at DESC"
+	}
+
+	var reports []model.Report
+	offset := (page - 1) * pageSize
+	if result := query.Preload("Reporter").Order(order).Limit(pageSize).Offset(offset).Find(&reports); result.Error != nil {
+		apperr.Abort(c, apperr.Internal("获取举报列表失败"))
+		return
+	}
+
+	// 同一内容被多人举报时优先处理
+	for i := range reports {
+		if reports[i].Status == model.ReportStatusOpen {
+			reports[i].TargetReportCount = countOpenReports(db, reports[i].TargetType, reports[i].TargetID)
+		}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  adminReports(reports),
+			"total": total,
+		},
+	})
+}
+
+// GetReport 举报详情，包含被举报内容的当前状态和同一内容的其他举报
+func GetReport(c *gin.Context) {
+	db := config.GetDB()
+
+	report, ok := findReport(c, db)
+	if !ok {
+		return
+	}
+
+	var related []model.Report
+	db.Preload("Reporter").
+		Where("target_type = ? AND target_id = ? AND id <> ?", report.TargetType, report.TargetID, report.ID).
+		Order("id DESC").Limit(50).Find(&related)
+
+	var current interface{}
+	if content, err := loadContent(db, report.TargetType, report.TargetID); err == nil {
+		current = gin.H{
+			"ownerId": content.OwnerID,
+			"status":  content.Status,
+			"text":    content.Text,
+		}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"report":  adminReport{Report: report, TargetOwnerID: report.TargetOwnerID},
+			"content": current,
+			"related": adminReports(related),
+		},
+	})
+}
+
+// ResolveReport 处理举报：hide下架被举报内容，dismiss驳回。
+// 同一内容的全部待处理举报一并处理，并通知举报人
+func ResolveReport(c *gin.Context) {
+	db := config.GetDB()
+	operatorID := c.GetUint("userID")
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	report, ok := findReport(c, db)
+	if !ok {
+		return
+	}
+	if report.Status != model.ReportStatusOpen {
+		apperr.Abort(c, apperr.Conflict("该举报已处理"))
+		return
+	}
+
+	status := model.ReportStatusDismissed
+	if req.Action == "hide" {
+		status = model.ReportStatusActioned
+	}
+
+	var resolved []model.Report
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, model.ReportStatusOpen).
+			Find(&resolved).Error; err != nil {
+			return err
+		}
+
+		adminLog := model.AdminLog{
+			OperatorID: operatorID,
+			Action:     model.AdminActionDismissReport,
+			TargetType: report.TargetType,
+			TargetID:   report.TargetID,
+			Detail:     fmt.Sprintf("report #%d", report.ID),
+			Reason:     req.Resolution,
+		}
+		// 内容过滤拦下的内容审核通过后正常展示
+		if req.Action == "dismiss" && hasFilterReport(resolved) {
+			if err := approveContent(tx, report.TargetType, report.TargetID); err != nil {
+				return err
+			}
+		}
+		if req.Action == "hide" {
+			// 内容已被下架（如其他管理员先处理）时仅关闭举报
+			prev, err := hideContent(tx, report.TargetType, report.TargetID)
+			if err != nil && !errors.Is(err, errContentState) {
+				return err
+			}
+			if err == nil {
+				adminLog.Action = model.AdminActionRemoveContent
+				adminLog.PrevStatus = prev
+			}
+		}
+		if err := tx.Create(&adminLog).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&model.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, model.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":     status,
+				"handler_id": operatorID,
+				"handled_at": &now,
+				"resolution": req.Resolution,
+			}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errContentNotFound) {
+			apperr.Abort(c, apperr.NotFound("内容不存在"))
+			return
+		}
+		apperr.Abort(c, apperr.Internal("处理举报失败"))
+		return
+	}
+
+	syncContentIndex(db, report.TargetType, report.TargetID)
+	notifyReporters(db, resolved, status)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "处理成功",
+		Data: gin.H{
+			"resolved": len(resolved),
+		},
+	})
+}
+
+// findReport 按路径ID查找举报，失败时已写入响应
+func findReport(c *gin.Context, db *gorm.DB) (model.Report, bool) {
+	var report model.Report
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的举报ID"))
+		return report, false
+	}
+
+	if result := db.Preload("Reporter").First(&report, id); result.Error != nil {
+		apperr.Abort(c, apperr.NotFound("举报不存在"))
+		return report, false
+	}
+	return report, true
+}
+
+// countOpenReports 统计同一内容待处理的举报数
+func countOpenReports(db *gorm.DB, targetType string, targetID uint) int64 {
+	var count int64
+	db.Model(&model.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, model.ReportStatusOpen).
+		Count(&count)
+	return count
+}
+
+// hasFilterReport 是否包含内容过滤自动提交的审核
+func hasFilterReport(reports []model.Report) bool {
+	for _, report := range reports {
+		if report.Reason == model.ReportReasonFilter {
+			return true
+		}
+	}
+	return false
+}
+
+// canViewMessage 判断用户是否为消息所在会话的参与者
+func canViewMessage(db *gorm.DB, messageID, userID uint) bool {
+	var message model.Message
+	if db.Select("id, chat_id").First(&message, messageID).Error != nil {
+		return false
+	}
+	var chat model.Chat
+	if db.First(&chat, message.ChatID).Error != nil {
+		return false
+	}
+	if service.IsGroupChat(chat) {
+		var member model.ChatMember
+		return db.Where("chat_id = ? AND user_id = ?", chat.ID, userID).First(&member).Error == nil
+	}
+	return chat.UserID == userID || chat.ReceiverID == userID
+}
+
+// notifyReporters 以站内通知告知举报人处理结果，已验证邮箱的举报人同时发送邮件，发送失败只记录日志
+func notifyReporters(db *gorm.DB, reports []model.Report, status string) {
+	result := "经核实，被举报内容未发现违规，感谢你的反馈。"
+	if status == model.ReportStatusActioned {
+		result = "经核实，被举报内容存在违规，已被下架，感谢你帮助维护社区环境。"
+	}
+
+	for _, report := range reports {
+		if err := notify.Send(db, model.Notification{
+			UserID:     report.ReporterID,
+			Type:       model.NotifyReport,
+			TargetType: report.TargetType,
+			TargetID:   report.TargetID,
+			RefID:      report.ID,
+			Content:    fmt.Sprintf("你提交的举报（原因：%s）已处理。%s", reportReasonNames[report.Reason], result),
+		}); err != nil {
+			log.Printf("Failed to notify reporter %d: %v", report.ReporterID, err)
+		}
+
+		var user model.User
+		if db.Where("id = ? AND email_verified = ?", report.ReporterID, true).First(&user).Error != nil {
+			continue
+		}
+		if err := mail.GetMailer().Send(mail.Message{
+			To:      user.EmailAddress(),
+			Subject: "你的举报已处理",
+			Body: fmt.Sprintf("%s，你好：\n\n你于%s提交的举报（原因：%s）已处理。\n%s",
+				user.Username, report.CreatedAt.Format("2006-01-02 15:04"), reportReasonNames[report.Reason], result),
+		}); err != nil {
+			log.Printf("Failed to send report result mail to user %d: %v", user.ID, err)
+		}
+	}
+}
@@ -1,55 +1,100 @@
 package handler
 
 import (
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/config"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/search"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
 )
 
+// Search 统一搜索，type可选all/question/answer/note/post/user/village，按相关度排序并返回各类型命中数
+func Search(c *gin.Context) {
+	keyword := strings.TrimSpace(c.Query("keyword"))
+	docType := c.DefaultQuery("type", "all")
+	page, pageSize := pagination.PageParams(c, 10)
+
+	if keyword == "" {
+		apperr.Abort(c, apperr.BadRequest("搜索关键词不能为空"))
+		return
+	}
+
+	var types []string
+	if docType != "all" {
+		if !search.IsValidType(docType) {
+			apperr.Abort(c, apperr.BadRequest("不支持的搜索类型"))
+			return
+		}
+		types = []string{docType}
+	}
+
+	result, err := search.GetIndexer().Search(search.Query{
+		Keyword:  keyword,
+		Types:    types,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("搜索失败"))
+		return
+	}
+
+	recordSearch(c, keyword, docType, page, result.Total)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    result,
+	})
+}
+
 // SearchQuestions 搜索问题
 func SearchQuestions(c *gin.Context) {
 	db := config.GetDB()
 
 	keyword := c.Query("keyword")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	page, pageSize := pagination.PageParams(c, 10)
 
 	if keyword == "" {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "搜索关键词不能为空",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("搜索关键词不能为空"))
 		return
 	}
 
-	var questions []model.Question
-	query := db.Model(&model.Question{}).
-		Where("status = ? AND (title LIKE ? OR content LIKE ?)",
-			1, "%"+keyword+"%", "%"+keyword+"%")
-
-	var total int64
-	query.Count(&total)
+	found, err := search.GetIndexer().Search(search.Query{
+		Keyword:  keyword,
+		Types:    []string{search.TypeQuestion},
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("搜索问题失败"))
+		return
+	}
 
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").
-		Order("created_at DESC").
-		Limit(pageSize).Offset(offset).
-		Find(&questions)
+	ids, rank := hitIDs(found.Hits)
+	questions := []model.Question{}
+	total := found.Total
+	result := db.Where("id IN ? AND status = ?", ids, 1).Preload("Author").Find(&questions)
 
 	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "搜索问题失败",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Internal("搜索问题失败"))
 		return
 	}
 
+	recordSearch(c, keyword, search.TypeQuestion, page, total)
+
+	// 按相关度顺序返回
+	sort.Slice(questions, func(i, j int) bool { return rank[questions[i].ID] < rank[questions[j].ID] })
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
@@ -65,41 +110,39 @@ func SearchNotes(c *gin.Context) {
 	db := config.GetDB()
 
 	keyword := c.Query("keyword")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	page, pageSize := pagination.PageParams(c, 10)
 
 	if keyword == "" {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: "搜索关键词不能为空",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.BadRequest("搜索关键词不能为空"))
 		return
 	}
 
-	var notes []model.Note
-	query := db.Model(&model.Note{}).
-		Where("status = ? AND (title LIKE ? OR content LIKE ?)",
-			1, "%"+keyword+"%", "%"+keyword+"%")
-
-	var total int64
-	query.Count(&total)
+	found, err := search.GetIndexer().Search(search.Query{
+		Keyword:  keyword,
+		Types:    []string{search.TypeNote},
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("搜索笔记失败"))
+		return
+	}
 
-	offset := (page - 1) * pageSize
-	result := query.Preload("Author").
-		Order("created_at DESC").
-		Limit(pageSize).Offset(offset).
-		Find(&notes)
+	ids, rank := hitIDs(found.Hits)
+	notes := []model.Note{}
+	total := found.Total
+	result := db.Where("id IN ? AND status = ?", ids, 1).Preload("Author").Find(&notes)
 
 	if result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "搜索笔记失败",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Internal("搜索笔记失败"))
 		return
 	}
 
+	recordSearch(c, keyword, search.TypeNote, page, total)
+
+	// 按相关度顺序返回
+	sort.Slice(notes, func(i, j int) bool { return rank[notes[i].ID] < rank[notes[j].ID] })
+
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "",
@@ -109,3 +152,209 @@ func SearchNotes(c *gin.Context) {
 		},
 	})
 }
+
+// SearchSuggest 搜索联想，根据前缀返回问题标题、标签和用户名
+func SearchSuggest(c *gin.Context) {
+	db := config.GetDB()
+
+	prefix := c.Query("prefix")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit < 1 || limit > 10 {
+		limit = 5
+	}
+
+	suggestions, err := search.Suggest(db, prefix, limit)
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("获取搜索联想失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list": suggestions,
+		},
+	})
+}
+
+// GetSearchHistory 获取当前用户最近的搜索历史
+func GetSearchHistory(c *gin.Context) {
+	db := config.GetDB()
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	var histories []model.SearchHistory
+	if err := db.Where("user_id = ?", userID.(uint)).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&histories).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("获取搜索历史失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list": histories,
+		},
+	})
+}
+
+// DeleteSearchHistory 删除一条搜索历史
+func DeleteSearchHistory(c *gin.Context) {
+	db := config.GetDB()
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的搜索历史ID"))
+		return
+	}
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	result := db.Where("id = ? AND user_id = ?", id, userID.(uint)).Delete(&model.SearchHistory{})
+	if result.Error != nil {
+		apperr.Abort(c, apperr.Internal("删除搜索历史失败"))
+		return
+	}
+	if result.RowsAffected == 0 {
+		apperr.Abort(c, apperr.NotFound("搜索历史不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "删除成功",
+		Data:    nil,
+	})
+}
+
+// ClearSearchHistory 清空当前用户的搜索历史
+func ClearSearchHistory(c *gin.Context) {
+	db := config.GetDB()
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return
+	}
+
+	if err := db.Where("user_id = ?", userID.(uint)).Delete(&model.SearchHistory{}).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("清空搜索历史失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "清空成功",
+		Data:    nil,
+	})
+}
+
+// GetTrendingKeywords 获取热搜关键词，window可选hour/day/week
+func GetTrendingKeywords(c *gin.Context) {
+	db := config.GetDB()
+
+	window, ok := search.TrendWindows[c.DefaultQuery("window", "day")]
+	if !ok {
+		apperr.Abort(c, apperr.BadRequest("不支持的统计窗口"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	trends, err := search.Trending(db, window, limit)
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("获取热搜失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list": trends,
+		},
+	})
+}
+
+// recordSearch 记录搜索日志和用户搜索历史，翻页不重复记录
+func recordSearch(c *gin.Context, keyword, docType string, page int, total int64) {
+	userID, exists := c.Get("userID")
+	if !exists || page > 1 {
+		return
+	}
+
+	normalized := search.NormalizeKeyword(keyword)
+	if normalized == "" {
+		return
+	}
+
+	db := config.GetDB()
+	entry := model.SearchLog{
+		UserID:      userID.(uint),
+		Keyword:     normalized,
+		Type:        docType,
+		ResultCount: total,
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to save search log: %v", err)
+	}
+
+	history := model.SearchHistory{
+		UserID:  userID.(uint),
+		Keyword: normalized,
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "keyword"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+	}).Create(&history).Error; err != nil {
+		log.Printf("Failed to save search history: %v", err)
+	}
+}
+
+// hitIDs 返回检索结果的ID列表及ID到排名的映射
+func hitIDs(hits []search.Hit) ([]uint, map[uint]int) {
+	ids := make([]uint, 0, len(hits))
+	rank := make(map[uint]int, len(hits))
+	for i, hit := range hits {
+		ids = append(ids, hit.ID)
+		rank[hit.ID] = i
+	}
+	return ids, rank
+}
+
+// indexDocument 同步更新检索索引，失败时只记录日志不影响主流程
+func indexDocument(doc search.Document) {
+	if err := search.GetIndexer().Index(doc); err != nil {
+		log.Printf("Failed to index %s %d: %v", doc.Type, doc.ID, err)
+	}
+}
+
+// removeDocument 从检索索引中删除文档
+func removeDocument(docType string, id uint) {
+	if err := search.GetIndexer().Delete(docType, id); err != nil {
+		log.Printf("Failed to remove %s %d from index: %v", docType, id, err)
+	}
+}
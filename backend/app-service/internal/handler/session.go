@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions 获取当前用户已登录的设备
+func GetSessions(c *gin.Context) {
+	userID := c.GetUint("userID")
+	claims := currentClaims(c)
+
+	sessions, err := token.GetService().Sessions(userID)
+	if err != nil {
+		apperr.Abort(c, apperr.Internal("获取登录设备失败"))
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Device = deviceName(sessions[i].UserAgent)
+		sessions[i].IsCurrent = sessions[i].FamilyID == claims.SessionID
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data:    sessions,
+	})
+}
+
+// RevokeSession 下线指定设备
+func RevokeSession(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的会话ID"))
+		return
+	}
+
+	if err := token.GetService().RevokeSession(userID, uint(id)); err != nil {
+		if errors.Is(err, token.ErrSessionNotFound) {
+			apperr.Abort(c, apperr.NotFound("会话不存在"))
+			return
+		}
+		apperr.Abort(c, apperr.Internal("下线失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已下线",
+		Data:    nil,
+	})
+}
+
+// LogoutAll 退出全部设备，包括当前设备
+func LogoutAll(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	if err := token.GetService().Logout(currentClaims(c)); err != nil {
+		apperr.Abort(c, apperr.Internal("退出失败"))
+		return
+	}
+	if err := token.GetService().RevokeAllSessions(userID); err != nil {
+		apperr.Abort(c, apperr.Internal("退出失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已退出全部设备",
+		Data:    nil,
+	})
+}
+
+// currentClaims 获取当前请求的令牌载荷（由Auth中间件设置）
+func currentClaims(c *gin.Context) *token.Claims {
+	if claims, ok := c.Get("tokenClaims"); ok {
+		if tc, ok := claims.(*token.Claims); ok {
+			return tc
+		}
+	}
+	return &token.Claims{UserID: c.GetUint("userID")}
+}
+
+// clientInfo 获取请求的客户端信息
+func clientInfo(c *gin.Context) token.ClientInfo {
+	return token.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+// deviceName 从User-Agent粗略识别设备和浏览器，用于登录设备列表展示
+func deviceName(ua string) string {
+	var device string
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		device = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		device = "iPad"
+	case strings.Contains(ua, "Android"):
+		device = "Android"
+	case strings.Contains(ua, "Windows"):
+		device = "Windows"
+	case strings.Contains(ua, "Macintosh"):
+		device = "Mac"
+	case strings.Contains(ua, "Linux"):
+		device = "Linux"
+	default:
+		return "未知设备"
+	}
+
+	switch {
+	case strings.Contains(ua, "MicroMessenger"):
+		return device + " 微信"
+	case strings.Contains(ua, "Edg/"):
+		return device + " Edge"
+	case strings.Contains(ua, "Chrome/"):
+		return device + " Chrome"
+	case strings.Contains(ua, "Firefox/"):
+		return device + " Firefox"
+	case strings.Contains(ua, "Safari/"):
+		return device + " Safari"
+	}
+	return device
+}
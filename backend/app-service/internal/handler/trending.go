@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 列表排序方式
+const (
+	sortNew = "new" // 最新发布
+	sortHot = "hot" // 热度，随时间衰减
+	sortTop = "top" // 互动最多，不随时间衰减
+)
+
+// sortRanges 时间范围对应的时长，all不限制
+var sortRanges = map[string]time.Duration{
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+	"all":  0,
+}
+
+// parseListSort 解析列表的sort和range参数，返回排序字段（最新发布时为空）和起始时间（不限时为零值）。
+// 按热度排序时不支持游标分页，参数无效时已写入响应
+func parseListSort(c *gin.Context, p pagination.Params) (order string, since time.Time, ok bool) {
+	sort := c.DefaultQuery("sort", sortNew)
+	switch sort {
+	case sortNew:
+	case sortHot:
+		order = "hot_score DESC"
+	case sortTop:
+		order = "engagement DESC"
+	default:
+		apperr.Abort(c, apperr.BadRequest("无效的排序方式"))
+		return "", since, false
+	}
+
+	window, valid := sortRanges[c.DefaultQuery("range", "all")]
+	if !valid {
+		apperr.Abort(c, apperr.BadRequest("无效的时间范围"))
+		return "", since, false
+	}
+	if window > 0 {
+		since = time.Now().Add(-window)
+	}
+
+	if order != "" && p.Cursor != nil {
+		apperr.Abort(c, apperr.BadRequest("该排序方式不支持游标分页，请使用page参数"))
+		return "", since, false
+	}
+	return order, since, true
+}
+
+// GetTrending 发现页的热门问题和帖子，默认统计最近一周。游客只返回问题
+func GetTrending(c *gin.Context) {
+	db := config.GetDB()
+	userID := viewerID(c)
+
+	window, valid := sortRanges[c.DefaultQuery("range", "week")]
+	if !valid {
+		apperr.Abort(c, apperr.BadRequest("无效的时间范围"))
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	questionQuery := db.Where("status = ?", model.ContentStatusNormal)
+	if window > 0 {
+		questionQuery = questionQuery.Where("created_at >= ?", time.Now().Add(-window))
+	}
+	var questions []model.Question
+	if err := questionQuery.Preload("Author").
+		Order("hot_score DESC").
+		Limit(limit).
+		Find(&questions).Error; err != nil {
+		apperr.Abort(c, apperr.Internal("获取热门内容失败"))
+		return
+	}
+	for i := range questions {
+		hideEmail(&questions[i].Author)
+	}
+
+	// 帖子只对登录用户开放，只展示正常开放的村落中的帖子
+	posts := []model.Post{}
+	if userID != 0 {
+		postQuery := db.Joins("JOIN villages ON villages.id = posts.village_id AND villages.status = ?", 1).
+			Where("posts.status = ?", model.PostStatusNormal)
+		if window > 0 {
+			postQuery = postQuery.Where("posts.created_at >= ?", time.Now().Add(-window))
+		}
+		if err := postQuery.Preload("Author").
+			Order("posts.hot_score DESC").
+			Limit(limit).
+			Find(&posts).Error; err != nil {
+			apperr.Abort(c, apperr.Internal("获取热门内容失败"))
+			return
+		}
+		for i := range posts {
+			hideEmail(&posts[i].Author)
+			hidePostAuthor(&posts[i], userID)
+		}
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"questions": questions,
+			"posts":     posts,
+		},
+	})
+}
+
+// visitorKey 区分访客的标识，登录用户按用户ID，游客按IP
+func visitorKey(c *gin.Context) string {
+	if id := viewerID(c); id != 0 {
+		return "u" + strconv.FormatUint(uint64(id), 10)
+	}
+	return "ip" + c.ClientIP()
+}
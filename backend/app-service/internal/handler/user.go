@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"log"
 	"net/http"
 
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/config"
-	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/search"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,43 +18,47 @@ type UserInfo struct {
 	Email    string `json:"email"`
 	Avatar   string `json:"avatar"`
 	Bio      string `json:"bio"`
+
+	EmailVerified bool `json:"emailVerified"`
+}
+
+// UserHandler 用户资料、注册和登录
+type UserHandler struct {
+	users *service.UserService
+}
+
+func NewUserHandler(users *service.UserService) *UserHandler {
+	return &UserHandler{users: users}
 }
 
 type UpdateUserRequest struct {
-	Email  string `json:"email"`
+	Email  string `json:"email" binding:"omitempty,email"`
 	Avatar string `json:"avatar"`
 	Bio    string `json:"bio"`
 }
 
-func GetUser(c *gin.Context) {
+func (h *UserHandler) GetUser(c *gin.Context) {
 	// 从上下文中获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
-	db := config.GetDB()
-	var user model.User
-	if result := db.First(&user, userID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "用户不存在",
-			Data:    nil,
-		})
+	user, err := h.users.Get(userID.(uint))
+	if err != nil {
+		respondError(c, err, "获取用户信息失败")
 		return
 	}
 
 	userInfo := UserInfo{
 		ID:       user.ID,
 		Username: user.Username,
-		Email:    user.Email,
+		Email:    user.EmailAddress(),
 		Avatar:   user.Avatar,
 		Bio:      user.Bio,
+
+		EmailVerified: user.EmailVerified,
 	}
 
 	c.JSON(http.StatusOK, Response{
@@ -61,58 +68,38 @@ func GetUser(c *gin.Context) {
 	})
 }
 
-func UpdateUser(c *gin.Context) {
+func (h *UserHandler) UpdateUser(c *gin.Context) {
 	// 从上下文中获取当前用户ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusOK, Response{
-			Code:    401,
-			Message: "未登录",
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
 		return
 	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    400,
-			Message: err.Error(),
-			Data:    nil,
-		})
+		apperr.Abort(c, apperr.Invalid(err))
 		return
 	}
 
-	db := config.GetDB()
-	var user model.User
-	if result := db.First(&user, userID); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    404,
-			Message: "用户不存在",
-			Data:    nil,
-		})
+	// 更换邮箱后需要重新验证
+	user, emailChanged, err := h.users.UpdateProfile(userID.(uint), service.ProfileUpdate{
+		Email:  req.Email,
+		Avatar: req.Avatar,
+		Bio:    req.Bio,
+	})
+	if err != nil {
+		respondError(c, err, "更新用户信息失败")
 		return
 	}
 
-	// 更新用户信息
-	updates := make(map[string]interface{})
-	if req.Email != "" {
-		updates["email"] = req.Email
-	}
-	if req.Avatar != "" {
-		updates["avatar"] = req.Avatar
-	}
-	if req.Bio != "" {
-		updates["bio"] = req.Bio
-	}
+	indexDocument(search.UserDocument(user))
 
-	if result := db.Model(&user).Updates(updates); result.Error != nil {
-		c.JSON(http.StatusOK, Response{
-			Code:    500,
-			Message: "更新用户信息失败",
-			Data:    nil,
-		})
-		return
+	if emailChanged {
+		user.Email = &req.Email
+		if err := sendVerificationEmail(config.GetDB(), user); err != nil {
+			log.Printf("Failed to send verification mail to user %d: %v", user.ID, err)
+		}
 	}
 
 	c.JSON(http.StatusOK, Response{
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateVillagePolicyRequest struct {
+	PostPolicy string `json:"postPolicy" binding:"omitempty,oneof=open approval members"`
+	AllowAnon  *bool  `json:"allowAnon"`
+}
+
+type RejectPostRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// UpdateVillagePolicy 修改村落发帖策略
+func (h *VillageHandler) UpdateVillagePolicy(c *gin.Context) {
+	villageID, adminID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateVillagePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	update := service.PolicyUpdate{PostPolicy: req.PostPolicy, AllowAnon: req.AllowAnon}
+	if err := h.villages.UpdatePolicy(villageID, adminID, update); err != nil {
+		respondError(c, err, "修改发帖策略失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    nil,
+	})
+}
+
+// GetPendingPosts 获取待审核帖子列表
+func (h *VillageHandler) GetPendingPosts(c *gin.Context) {
+	villageID, adminID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := pagination.PageParams(c, 10)
+
+	posts, total, err := h.villages.PendingPosts(villageID, adminID, page, pageSize)
+	if err != nil {
+		respondError(c, err, "获取待审核帖子失败")
+		return
+	}
+
+	// 审核队列同样不暴露匿名作者，需要时通过溯源接口查询
+	for i := range posts {
+		hidePostAuthor(&posts[i], adminID)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "",
+		Data: gin.H{
+			"list":  posts,
+			"total": total,
+		},
+	})
+}
+
+// ApprovePost 审核通过帖子
+func (h *VillageHandler) ApprovePost(c *gin.Context) {
+	villageID, adminID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	postID, ok := postIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.villages.ApprovePost(villageID, postID, adminID); err != nil {
+		respondError(c, err, "审核失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "审核通过",
+		Data:    nil,
+	})
+}
+
+// RejectPost 驳回帖子
+func (h *VillageHandler) RejectPost(c *gin.Context) {
+	villageID, adminID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	var req RejectPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Invalid(err))
+		return
+	}
+
+	postID, ok := postIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.villages.RejectPost(villageID, postID, adminID, req.Reason); err != nil {
+		respondError(c, err, "驳回失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已驳回",
+		Data:    nil,
+	})
+}
+
+// PinPost 置顶帖子
+func (h *VillageHandler) PinPost(c *gin.Context) {
+	h.setPostPinned(c, true)
+}
+
+// UnpinPost 取消置顶帖子
+func (h *VillageHandler) UnpinPost(c *gin.Context) {
+	h.setPostPinned(c, false)
+}
+
+func (h *VillageHandler) setPostPinned(c *gin.Context, pinned bool) {
+	villageID, adminID, ok := villageOperator(c)
+	if !ok {
+		return
+	}
+
+	postID, ok := postIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.villages.SetPostPinned(villageID, postID, adminID, pinned); err != nil {
+		respondError(c, err, "操作失败")
+		return
+	}
+
+	message := "取消置顶成功"
+	if pinned {
+		message = "置顶成功"
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    nil,
+	})
+}
+
+// villageOperator 解析村落ID和当前用户，失败时直接写入响应。管理员权限由服务校验
+func villageOperator(c *gin.Context) (uint, uint, bool) {
+	villageID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的村落ID"))
+		return 0, 0, false
+	}
+
+	// 从上下文获取当前用户ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("未登录"))
+		return 0, 0, false
+	}
+
+	return uint(villageID), userID.(uint), true
+}
+
+// postIDParam 解析路径中的帖子ID，失败时直接写入响应
+func postIDParam(c *gin.Context) (uint, bool) {
+	postID, err := strconv.ParseUint(c.Param("postId"), 10, 64)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("无效的帖子ID"))
+		return 0, false
+	}
+	return uint(postID), true
+}
@@ -0,0 +1,135 @@
+// Package mail 提供邮件发送接口及SMTP、内存实现
+package mail
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 待发送的邮件，正文为纯文本
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPMailer 通过SMTP服务器发送邮件
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer 创建SMTP发送器，username为空时不做认证
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: host + ":" + port,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mimeEncode(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(b.String()))
+}
+
+// mimeEncode 按RFC 2047编码非ASCII的邮件头
+func mimeEncode(s string) string {
+	for _, r := range s {
+		if r > 127 {
+			return "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(s)) + "?="
+		}
+	}
+	return s
+}
+
+// MemoryMailer 将邮件保存在内存中而不实际发送，用于测试
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer 创建内存发送器
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages 返回已发送的全部邮件
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Last 返回最近发送给to的邮件
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// LogMailer 只把邮件内容打印到日志，用于未配置SMTP的开发环境
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+var (
+	mailer      Mailer = LogMailer{}
+	linkBaseURL        = "http://localhost:5173"
+)
+
+// Init 设置全局邮件发送器和邮件中链接指向的前端地址
+func Init(m Mailer, baseURL string) {
+	mailer = m
+	linkBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// Link 生成邮件中的前端链接，如Link("/reset-password", token)
+func Link(path, token string) string {
+	return linkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// GetMailer 获取全局邮件发送器，未初始化时只打印日志
+func GetMailer() Mailer {
+	return mailer
+}
@@ -0,0 +1,39 @@
+package mail
+
+import "testing"
+
+func TestMemoryMailerLast(t *testing.T) {
+	m := NewMemoryMailer()
+	m.Send(Message{To: "alice@example.com", Subject: "first"})
+	m.Send(Message{To: "bob@example.com", Subject: "other"})
+	m.Send(Message{To: "alice@example.com", Subject: "second"})
+
+	if n := len(m.Messages()); n != 3 {
+		t.Fatalf("got %d messages, want 3", n)
+	}
+	msg, ok := m.Last("alice@example.com")
+	if !ok || msg.Subject != "second" {
+		t.Fatalf("Last = %+v, %v", msg, ok)
+	}
+	if _, ok := m.Last("nobody@example.com"); ok {
+		t.Fatalf("Last returned a message for unknown recipient")
+	}
+}
+
+func TestLink(t *testing.T) {
+	defer Init(LogMailer{}, "http://localhost:5173")
+
+	Init(NewMemoryMailer(), "https://egg.example.com/")
+	if got, want := Link("/reset-password", "a+b/c"), "https://egg.example.com/reset-password?token=a%2Bb%2Fc"; got != want {
+		t.Fatalf("Link = %q, want %q", got, want)
+	}
+}
+
+func TestMimeEncode(t *testing.T) {
+	if got := mimeEncode("Reset"); got != "Reset" {
+		t.Fatalf("ascii subject encoded as %q", got)
+	}
+	if got, want := mimeEncode("重置密码"), "=?UTF-8?B?6YeN572u5a+G56CB?="; got != want {
+		t.Fatalf("mimeEncode = %q, want %q", got, want)
+	}
+}
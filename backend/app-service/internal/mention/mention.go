@@ -0,0 +1,70 @@
+// Package mention 解析文本中的@用户名
+package mention
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxUsernameLength 用户名最大长度（字符数），与用户表的字段长度一致
+	MaxUsernameLength = 50
+	// MaxMentions 一段文本最多解析的提及数，超出部分忽略
+	MaxMentions = 20
+)
+
+// Span 文本中的一处提及。Start、End为字符（rune）偏移，[Start, End)包含@符号
+type Span struct {
+	Username string
+	Start    int
+	End      int
+}
+
+// Parse 解析文本中的@用户名。@前须为文本开头或非用户名字符，避免误识别邮箱地址；
+// 用户名由字母、数字、下划线、连字符和点组成，结尾的点视为标点
+func Parse(text string) []Span {
+	runes := []rune(text)
+	var spans []Span
+	for i := 0; i < len(runes) && len(spans) < MaxMentions; i++ {
+		if runes[i] != '@' && runes[i] != '＠' {
+			continue
+		}
+		if i > 0 && isNameRune(runes[i-1]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && end-i-1 < MaxUsernameLength && isNameRune(runes[end]) {
+			end++
+		}
+		for end > i+1 && runes[end-1] == '.' {
+			end--
+		}
+		if end == i+1 {
+			continue
+		}
+
+		spans = append(spans, Span{Username: string(runes[i+1 : end]), Start: i, End: end})
+		i = end - 1
+	}
+	return spans
+}
+
+// Usernames 返回提及的用户名，忽略大小写去重
+func Usernames(spans []Span) []string {
+	seen := make(map[string]bool, len(spans))
+	var names []string
+	for _, s := range spans {
+		key := strings.ToLower(s.Username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, s.Username)
+	}
+	return names
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 要求当前用户的角色拥有指定权限，否则返回403，需在Auth之后使用
+func RequirePermission(perm rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rbac.Has(c.GetString("userRole"), perm) {
+			apperr.Abort(c, apperr.Forbidden("无权限"))
+			return
+		}
+		c.Next()
+	}
+}
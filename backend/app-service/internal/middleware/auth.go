@@ -1,52 +1,112 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/token"
+
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
-
+// Auth 要求请求携带有效token，否则返回401
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从Header获取token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("未登录"))
 			return
 		}
 
-		// 提取Bearer token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的token格式"})
-			c.Abort()
+		if err := authenticate(c, authHeader); err != nil {
+			apperr.Abort(c, err)
 			return
 		}
-		tokenString := parts[1]
 
-		// 验证token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+		c.Next()
+	}
+}
+
+// OptionalAuth 携带有效token时设置当前用户，未携带或token无效时按游客处理，不拒绝请求
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			authenticate(c, authHeader)
+		}
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的token"})
-			c.Abort()
+// StreamAuth 推送连接的认证。浏览器EventSource无法设置请求头，通过ticket参数携带一次性推送票据，
+// 避免访问令牌出现在URL和访问日志中；未携带票据时按Auth处理
+func StreamAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			Auth()(c)
 			return
 		}
 
-		// 获取用户ID
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("userID", uint(userID))
-			}
+		claims, err := token.GetService().RedeemStreamTicket(ticket)
+		if err != nil {
+			apperr.Abort(c, apperr.Unauthorized("推送票据无效或已过期").WithCode(apperr.CodeTokenInvalid))
+			return
+		}
+		if err := authorize(c, claims); err != nil {
+			apperr.Abort(c, err)
+			return
 		}
 
 		c.Next()
 	}
 }
+
+// authenticate 校验Authorization头，成功时在上下文中设置userID、userRole和tokenClaims
+func authenticate(c *gin.Context, authHeader string) *apperr.Error {
+	// 提取Bearer token
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return apperr.Unauthorized("无效的token格式").WithCode(apperr.CodeTokenInvalid)
+	}
+	tokenString := parts[1]
+
+	// 验证token
+	claims, err := token.GetService().ParseAccessToken(tokenString)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenExpired) {
+			return apperr.Unauthorized("token已过期").WithCode(apperr.CodeTokenExpired)
+		}
+		return apperr.Unauthorized("无效的token").WithCode(apperr.CodeTokenInvalid)
+	}
+	return authorize(c, claims)
+}
+
+// authorize 校验令牌是否被吊销及账号状态，成功时在上下文中设置userID、userRole和tokenClaims
+func authorize(c *gin.Context, claims *token.Claims) *apperr.Error {
+	// 已退出或被下线的令牌
+	if token.GetService().IsRevoked(claims) {
+		return apperr.Unauthorized("登录已失效").WithCode(apperr.CodeTokenRevoked)
+	}
+
+	// 校验账号状态，被禁用或已注销的用户即使持有有效token也无法访问
+	status, err := account.Check(config.GetDB(), claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrUserDisabled):
+			return apperr.Forbidden("账号已被禁用").WithCode(apperr.CodeAccountDisabled)
+		case errors.Is(err, account.ErrUserNotFound):
+			return apperr.Unauthorized("用户不存在")
+		default:
+			return apperr.Internal("服务器错误")
+		}
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("userRole", status.Role)
+	c.Set("tokenClaims", claims)
+	return nil
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"ai-egg/app-service/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors 统一输出请求中记录的错误，需注册在所有中间件之前。
+// 提示按Accept-Language本地化，非apperr的错误按服务器错误处理，已写出响应的请求不再处理
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+
+		var appErr *apperr.Error
+		if !errors.As(last.Err, &appErr) {
+			log.Printf("Unhandled error on %s %s: %v", c.Request.Method, c.FullPath(), last.Err)
+			appErr = apperr.Internal("服务器错误")
+		}
+		c.JSON(appErr.HTTPStatus(), appErr.Body(apperr.ParseLanguage(c.GetHeader("Accept-Language"))))
+	}
+}
+
+// NoRoute 未匹配到路由时返回404
+func NoRoute(c *gin.Context) {
+	apperr.Abort(c, apperr.NotFound("接口不存在"))
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc 生成限流维度的键
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP 按客户端IP限流
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser 按登录用户限流，游客按IP限流。需在Auth或OptionalAuth之后使用
+func KeyByUser(c *gin.Context) string {
+	if userID := c.GetUint("userID"); userID != 0 {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return KeyByIP(c)
+}
+
+// RateLimit 令牌桶限流，name区分不同的限流规则。
+// 超出限制时返回429和Retry-After；限流后端出错时放行，避免影响正常访问
+func RateLimit(name string, limit ratelimit.Limit, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := ratelimit.GetLimiter()
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(name+":"+key(c), limit)
+		if err != nil {
+			log.Printf("Rate limiter error: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(result.RetryAfter)))
+			apperr.Abort(c, apperr.TooManyRequests("请求过于频繁，请稍后再试"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// AdminLog 后台操作记录
+type AdminLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OperatorID uint   `gorm:"not null;index" json:"operator_id"`
+	Action     string `gorm:"size:50;not null" json:"action"`
+	TargetType string `gorm:"size:20;not null;index:idx_admin_log_target" json:"target_type"`
+	TargetID   uint   `gorm:"not null;index:idx_admin_log_target" json:"target_id"`
+	PrevStatus int    `gorm:"default:0" json:"prev_status"` // 操作前的状态，恢复内容时使用
+	Detail     string `gorm:"size:255" json:"detail"`
+	Reason     string `gorm:"size:500" json:"reason"`
+
+	Operator User `gorm:"foreignKey:OperatorID" json:"operator,omitempty"`
+}
+
+// 后台操作类型
+const (
+	AdminActionRemoveContent  = "content.remove"
+	AdminActionRestoreContent = "content.restore"
+	AdminActionUpdateRole     = "user.role"
+	AdminActionCreateVillage  = "village.create"
+	AdminActionUpdateVillage  = "village.update"
+	AdminActionCloseVillage   = "village.close"
+	AdminActionDismissReport  = "report.dismiss"
+)
+
+// 后台操作对象类型，内容类型与评论的target_type保持一致
+const (
+	TargetTypeUser     = "user"
+	TargetTypeQuestion = "question"
+	TargetTypeAnswer   = "answer"
+	TargetTypeNote     = "note"
+	TargetTypePost     = "post"
+	TargetTypeComment  = "comment"
+	TargetTypeMessage  = "message"
+	TargetTypeVillage  = "village"
+)
+
+// 内容状态，问题、回答、笔记、帖子、评论、聊天消息通用
+const (
+	ContentStatusRemoved = 0
+	ContentStatusNormal  = 1
+	ContentStatusPending = 2 // 内容过滤转人工审核，审核通过前不展示
+)
+
+// TableName 指定表名
+func (AdminLog) TableName() string {
+	return "admin_logs"
+}
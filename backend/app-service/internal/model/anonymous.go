@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+// AnonymousAlias 匿名化名模型，同一帖子内同一用户的化名保持不变
+type AnonymousAlias struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	PostID uint   `gorm:"not null;index:idx_alias_post_user,unique;index:idx_alias_post_name,unique" json:"post_id"`
+	UserID uint   `gorm:"not null;index:idx_alias_post_user,unique" json:"user_id"`
+	Name   string `gorm:"size:50;not null;index:idx_alias_post_name,unique" json:"name"`
+}
+
+// TableName 指定表名
+func (AnonymousAlias) TableName() string {
+	return "anonymous_aliases"
+}
+
+// AnonymousAuditLog 匿名内容溯源审计记录
+type AnonymousAuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OperatorID uint   `gorm:"not null;index" json:"operator_id"`
+	VillageID  uint   `gorm:"not null;index" json:"village_id"`
+	TargetType string `gorm:"size:20;not null" json:"target_type"` // post/reply
+	TargetID   uint   `gorm:"not null" json:"target_id"`
+	AuthorID   uint   `gorm:"not null" json:"author_id"`
+	Reason     string `gorm:"size:500;not null" json:"reason"`
+}
+
+// TableName 指定表名
+func (AnonymousAuditLog) TableName() string {
+	return "anonymous_audit_logs"
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// BookmarkFolder 收藏夹
+type BookmarkFolder struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint   `gorm:"not null;uniqueIndex:idx_bookmark_folder_name" json:"user_id"`
+	Name   string `gorm:"size:50;not null;uniqueIndex:idx_bookmark_folder_name" json:"name"`
+
+	Count int64 `gorm:"-" json:"count"` // 收藏夹中的收藏数
+}
+
+// TableName 指定表名
+func (BookmarkFolder) TableName() string {
+	return "bookmark_folders"
+}
+
+// Bookmark 收藏，同一用户对同一内容只能收藏一次。
+// Title和Excerpt为收藏时的快照，原内容被删除后仍可查看
+type Bookmark struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_bookmark_target" json:"user_id"`
+	TargetType string `gorm:"size:20;not null;uniqueIndex:idx_bookmark_target" json:"target_type"` // question/answer/note/post/comment
+	TargetID   uint   `gorm:"not null;uniqueIndex:idx_bookmark_target" json:"target_id"`
+	FolderID   *uint  `gorm:"index" json:"folder_id"` // 为空表示未分类
+	Title      string `gorm:"size:200" json:"title"`
+	Excerpt    string `gorm:"size:500" json:"excerpt"`
+	Remark     string `gorm:"size:500" json:"remark"`
+	NoteID     *uint  `json:"note_id"` // 转为笔记后的笔记ID
+
+	Available bool `gorm:"-" json:"available"` // 原内容是否仍可访问
+}
+
+// TableName 指定表名
+func (Bookmark) TableName() string {
+	return "bookmarks"
+}
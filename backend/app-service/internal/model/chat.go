@@ -13,9 +13,11 @@ type Chat struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
-	UserID     uint   `gorm:"not null" json:"user_id"`
-	ReceiverID uint   `gorm:"not null" json:"receiver_id"` // 对方ID（用户/智能体/员工）
-	Type       string `gorm:"size:20;not null" json:"type"` // user/agent/employee
+	UserID      uint   `gorm:"not null" json:"user_id"`
+	ReceiverID  uint   `gorm:"not null" json:"receiver_id"`             // 对方ID（用户/智能体/员工），群聊为0
+	Type        string `gorm:"size:20;not null" json:"type"`            // user/agent/employee/group/village
+	Name        string `gorm:"size:100" json:"name"`                    // 群聊名称
+	VillageID   *uint  `gorm:"uniqueIndex" json:"village_id,omitempty"` // 村落聊天室对应的村落ID
 	LastMessage string `gorm:"type:text" json:"last_message"`
 	UnreadCount int    `gorm:"default:0" json:"unread_count"`
 }
@@ -27,17 +29,42 @@ func (Chat) TableName() string {
 
 // Message 消息模型
 type Message struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
 
-	ChatID     uint   `gorm:"not null" json:"chat_id"`
-	SenderID   uint   `gorm:"not null" json:"sender_id"`
-	Content    string `gorm:"type:text;not null" json:"content"`
-	Type       string `gorm:"size:20;default:'text'" json:"type"` // text/image/file
-	Status     int    `gorm:"default:1" json:"status"` // 1:已发送 2:已读
+	ChatID   uint   `gorm:"not null" json:"chat_id"`
+	SenderID uint   `gorm:"not null" json:"sender_id"`
+	Content  string `gorm:"type:text;not null" json:"content"`
+	Type     string `gorm:"size:20;default:'text'" json:"type"` // text/image/file/system
+	Status   int    `gorm:"default:1" json:"status"`            // 0:已删除 1:已发送 2:已读
 }
 
 // TableName 指定表名
 func (Message) TableName() string {
 	return "messages"
 }
+
+// 群聊会话类型
+const (
+	ChatTypeGroup   = "group"   // 自建群聊
+	ChatTypeVillage = "village" // 村落聊天室
+)
+
+// ChatMember 群聊成员模型
+type ChatMember struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ChatID            uint `gorm:"not null;index:idx_chat_member,unique" json:"chat_id"`
+	UserID            uint `gorm:"not null;index:idx_chat_member,unique;index" json:"user_id"`
+	Role              int  `gorm:"default:0" json:"role"` // 0:成员 1:群主
+	LastReadMessageID uint `gorm:"default:0" json:"last_read_message_id"`
+
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (ChatMember) TableName() string {
+	return "chat_members"
+}
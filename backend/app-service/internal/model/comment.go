@@ -13,7 +13,7 @@ type Comment struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
-	TargetID   uint   `gorm:"not null" json:"target_id"`   // 目标ID
+	TargetID   uint   `gorm:"not null" json:"target_id"`           // 目标ID
 	TargetType string `gorm:"size:20;not null" json:"target_type"` // question/answer/note/post
 	Content    string `gorm:"type:text;not null" json:"content"`
 	AuthorID   uint   `gorm:"not null" json:"author_id"`
@@ -21,7 +21,13 @@ type Comment struct {
 	Likes      int    `gorm:"default:0" json:"likes"`
 	Status     int    `gorm:"default:1" json:"status"`
 
-	Author User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	// 匿名回复对外只展示AnonName，与所属帖子中同一作者的化名一致
+	IsAnonymous bool   `gorm:"default:false" json:"is_anonymous"`
+	AnonName    string `gorm:"size:50" json:"anon_name,omitempty"`
+	IsMine      bool   `gorm:"-" json:"is_mine"`
+
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Mentions []Mention `gorm:"polymorphic:Source;polymorphicValue:comment" json:"mentions,omitempty"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// Embedding 内容向量，Vector为小端序float32数组
+type Embedding struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	TargetType string `gorm:"size:20;not null;uniqueIndex:idx_embedding_target" json:"target_type"` // question/note
+	TargetID   uint   `gorm:"not null;uniqueIndex:idx_embedding_target" json:"target_id"`
+	Model      string `gorm:"size:100;not null" json:"model"` // 生成向量的模型，切换模型后旧向量不再参与比较
+	Dim        int    `gorm:"not null" json:"dim"`
+	Vector     []byte `gorm:"not null" json:"-"`
+}
+
+// TableName 指定表名
+func (Embedding) TableName() string {
+	return "embeddings"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Mention 内容中的@提及，Start、End为被提及用户名在正文中的字符偏移，供客户端渲染链接
+type Mention struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	SourceType string `gorm:"size:20;not null;index:idx_mention_source" json:"source_type"` // question/answer/note/post/comment
+	SourceID   uint   `gorm:"not null;index:idx_mention_source" json:"source_id"`
+	UserID     uint   `gorm:"not null;index" json:"user_id"` // 被提及的用户
+	AuthorID   uint   `gorm:"not null" json:"-"`             // 提及者，匿名内容不对外展示
+	Username   string `gorm:"size:50" json:"username"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+}
+
+// TableName 指定表名
+func (Mention) TableName() string {
+	return "mentions"
+}
@@ -0,0 +1,39 @@
+package model
+
+// All 返回需要自动迁移的全部模型，服务启动和测试共用同一份列表
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&Question{},
+		&Answer{},
+		&QuestionLike{},
+		&Note{},
+		&NoteLike{},
+		&Comment{},
+		&CommentLike{},
+		&Chat{},
+		&Message{},
+		&ChatMember{},
+		&Village{},
+		&VillageMember{},
+		&Post{},
+		&PostLike{},
+		&AnonymousAlias{},
+		&AnonymousAuditLog{},
+		&SearchDocument{},
+		&SearchLog{},
+		&SearchHistory{},
+		&Embedding{},
+		&RefreshToken{},
+		&Session{},
+		&UserStatusLog{},
+		&VerificationToken{},
+		&UserIdentity{},
+		&AdminLog{},
+		&Report{},
+		&Notification{},
+		&Mention{},
+		&Bookmark{},
+		&BookmarkFolder{},
+	}
+}
@@ -20,7 +20,10 @@ type Note struct {
 	Tags     string `gorm:"size:500" json:"tags"` // JSON格式存储
 	Status   int    `gorm:"default:1;index" json:"status"`
 
-	Author User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Mentions []Mention `gorm:"polymorphic:Source;polymorphicValue:note" json:"mentions,omitempty"`
+
+	IsLiked *bool `gorm:"-" json:"is_liked,omitempty"` // 当前用户是否已点赞，游客不返回
 }
 
 // TableName 指定表名
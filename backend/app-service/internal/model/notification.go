@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Notification 站内通知。同一GroupKey的通知在列表中合并展示，如“5人赞了你的帖子”
+type Notification struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint       `gorm:"not null;index:idx_notification_user_group" json:"user_id"` // 接收人
+	ActorID    uint       `gorm:"not null;default:0" json:"actor_id"`                        // 触发人，0表示系统或匿名用户
+	ActorName  string     `gorm:"size:50" json:"actor_name,omitempty"`                       // 匿名用户的化名
+	Type       string     `gorm:"size:20;not null;index" json:"type"`
+	TargetType string     `gorm:"size:20" json:"target_type"` // 被操作的内容（接收人的问题、评论、帖子等）
+	TargetID   uint       `json:"target_id"`
+	RefID      uint       `json:"ref_id,omitempty"` // 新产生的回答、评论等的ID
+	GroupKey   string     `gorm:"size:100;not null;index:idx_notification_user_group" json:"group_key"`
+	Content    string     `gorm:"size:500" json:"content"` // 内容摘要或系统消息
+	ReadAt     *time.Time `gorm:"index" json:"read_at"`
+
+	Actor User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+// 通知类型
+const (
+	NotifyAnswer  = "answer"  // 问题被回答
+	NotifyComment = "comment" // 内容被评论
+	NotifyReply   = "reply"   // 评论被回复
+	NotifyLike    = "like"    // 内容被点赞，按内容合并
+	NotifyMention = "mention" // 被@提及
+	NotifyVillage = "village" // 村落管理员审核、置顶、删除帖子
+	NotifyReport  = "report"  // 举报处理结果
+)
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -21,7 +21,13 @@ type Question struct {
 	Views    int    `gorm:"default:0" json:"views"`
 	Status   int    `gorm:"default:1;index" json:"status"` // 1:正常 0:删除
 
-	Author User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Engagement float64 `gorm:"default:0" json:"-"`               // 互动分，按点赞、回答、评论和浏览加权累加
+	HotScore   float64 `gorm:"default:0;index" json:"hot_score"` // 热度，见ranking包
+
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Mentions []Mention `gorm:"polymorphic:Source;polymorphicValue:question" json:"mentions,omitempty"`
+
+	IsLiked *bool `gorm:"-" json:"is_liked,omitempty"` // 当前用户是否已点赞，游客不返回
 }
 
 // TableName 指定表名
@@ -43,8 +49,9 @@ type Answer struct {
 	IsAI       bool   `gorm:"default:false" json:"is_ai"` // 是否为AI回答
 	Status     int    `gorm:"default:1;index" json:"status"`
 
-	Author   User     `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
-	Question Question `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Mentions []Mention `gorm:"polymorphic:Source;polymorphicValue:answer" json:"mentions,omitempty"`
+	Question Question  `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Report 内容举报
+type Report struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ReporterID    uint   `gorm:"not null;index" json:"reporter_id"`
+	TargetType    string `gorm:"size:20;not null;index:idx_report_target" json:"target_type"` // question/answer/note/post/comment/message
+	TargetID      uint   `gorm:"not null;index:idx_report_target" json:"target_id"`
+	TargetOwnerID uint   `gorm:"not null;index" json:"-"` // 匿名内容的真实作者，只在管理后台展示
+	Reason        string `gorm:"size:20;not null" json:"reason"`
+	Detail        string `gorm:"size:500" json:"detail"`
+	Snapshot      string `gorm:"type:text" json:"snapshot"` // 举报时的内容快照，避免被举报后修改
+	Status        string `gorm:"size:20;default:open;index" json:"status"`
+
+	HandlerID  *uint      `json:"handler_id,omitempty"`
+	HandledAt  *time.Time `json:"handled_at,omitempty"`
+	Resolution string     `gorm:"size:500" json:"resolution,omitempty"`
+
+	Reporter User `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
+
+	TargetReportCount int64 `gorm:"-" json:"target_report_count,omitempty"` // 同一内容待处理的举报数
+}
+
+// 举报状态
+const (
+	ReportStatusOpen      = "open"      // 待处理
+	ReportStatusActioned  = "actioned"  // 已处理，内容已下架
+	ReportStatusDismissed = "dismissed" // 已驳回，内容无违规
+)
+
+// 举报原因
+const (
+	ReportReasonSpam    = "spam"    // 垃圾广告
+	ReportReasonAbuse   = "abuse"   // 辱骂攻击
+	ReportReasonPorn    = "porn"    // 色情低俗
+	ReportReasonIllegal = "illegal" // 违法违规
+	ReportReasonOther   = "other"   // 其他
+	ReportReasonFilter  = "filter"  // 内容过滤自动提交审核，举报人为0
+)
+
+// TableName 指定表名
+func (Report) TableName() string {
+	return "reports"
+}
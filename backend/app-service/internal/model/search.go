@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// SearchDocument 全文检索文档，title和content使用ngram分词的FULLTEXT索引以支持中文
+type SearchDocument struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	DocType     string    `gorm:"size:20;not null;uniqueIndex:idx_search_doc" json:"doc_type"` // question/answer/note/post/user/village
+	DocID       uint      `gorm:"not null;uniqueIndex:idx_search_doc" json:"doc_id"`
+	Title       string    `gorm:"size:255;index:idx_search_title,class:FULLTEXT,option:WITH PARSER ngram;index:idx_search_all,class:FULLTEXT,option:WITH PARSER ngram,priority:1" json:"title"`
+	Content     string    `gorm:"type:text;index:idx_search_all,class:FULLTEXT,option:WITH PARSER ngram,priority:2" json:"content"`
+	PublishedAt time.Time `json:"published_at"` // 原内容的发布时间
+}
+
+// TableName 指定表名
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
+
+// SearchLog 搜索日志，用于统计热搜关键词
+type SearchLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	Keyword     string `gorm:"size:100;not null;index" json:"keyword"` // 归一化后的关键词（小写、去首尾空白）
+	Type        string `gorm:"size:20" json:"type"`
+	ResultCount int64  `gorm:"default:0" json:"result_count"`
+}
+
+// TableName 指定表名
+func (SearchLog) TableName() string {
+	return "search_logs"
+}
+
+// SearchHistory 用户搜索历史，同一关键词只保留一条并记录最近搜索时间
+type SearchHistory struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `gorm:"index" json:"updated_at"`
+
+	UserID  uint   `gorm:"not null;index:idx_history_user_keyword,unique" json:"user_id"`
+	Keyword string `gorm:"size:100;not null;index:idx_history_user_keyword,unique" json:"keyword"`
+}
+
+// TableName 指定表名
+func (SearchHistory) TableName() string {
+	return "search_histories"
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，数据库中只保存令牌的SHA-256哈希。
+// 每次刷新都会签发新令牌并作废旧令牌，同一登录产生的令牌共享FamilyID
+type RefreshToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	FamilyID   string     `gorm:"size:36;not null;index" json:"family_id"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uint      `json:"replaced_by,omitempty"`
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// Session 登录会话，对应一组刷新令牌（同一FamilyID），记录设备信息供用户查看和下线
+type Session struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID      string     `gorm:"size:36;not null;uniqueIndex" json:"-"`
+	AccessTokenID string     `gorm:"size:32" json:"-"` // 最近签发的访问令牌jti，下线时用于吊销
+	UserAgent     string     `gorm:"size:255" json:"user_agent"`
+	IP            string     `gorm:"size:64" json:"ip"`
+	LastActiveAt  time.Time  `json:"last_active_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"-"`
+
+	Device    string `gorm:"-" json:"device"`
+	IsCurrent bool   `gorm:"-" json:"is_current"`
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "sessions"
+}
@@ -13,15 +13,106 @@ type User struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
-	Username     string `gorm:"uniqueIndex;size:50;not null" json:"username"`
-	PasswordHash string `gorm:"size:255;not null" json:"-"`
-	Email        string `gorm:"uniqueIndex;size:100" json:"email"`
-	Avatar       string `gorm:"size:255" json:"avatar"`
-	Bio          string `gorm:"size:500" json:"bio"`
-	Status       int    `gorm:"default:1" json:"status"` // 1:正常 0:禁用
+	Username     string  `gorm:"uniqueIndex;size:50;not null" json:"username"`
+	PasswordHash string  `gorm:"size:255;not null" json:"-"`
+	Email        *string `gorm:"uniqueIndex;size:100" json:"email"`
+	Avatar       string  `gorm:"size:255" json:"avatar"`
+	Bio          string  `gorm:"size:500" json:"bio"`
+	Status       int     `gorm:"default:1" json:"status"`                // 1:正常 0:禁用
+	Role         string  `gorm:"size:20;default:user;index" json:"role"` // user/moderator/admin
+
+	EmailVerified   bool       `gorm:"default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"-"`
 }
 
+// 用户状态
+const (
+	UserStatusDisabled = 0
+	UserStatusNormal   = 1
+)
+
+// 用户角色
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator" // 版主：处理内容和违规账号
+	RoleAdmin     = "admin"
+)
+
 // TableName 指定表名
 func (User) TableName() string {
 	return "users"
 }
+
+// EmailAddress 返回用户邮箱，未设置时为空字符串
+func (u User) EmailAddress() string {
+	if u.Email == nil {
+		return ""
+	}
+	return *u.Email
+}
+
+// OptionalEmail 转换为User.Email，空邮箱存为NULL，多个未设置邮箱的用户不会违反唯一索引
+func OptionalEmail(email string) *string {
+	if email == "" {
+		return nil
+	}
+	return &email
+}
+
+// UserStatusLog 账号禁用/启用记录
+type UserStatusLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID     uint   `gorm:"not null;index" json:"user_id"`
+	OperatorID uint   `gorm:"not null" json:"operator_id"`
+	Status     int    `gorm:"not null" json:"status"` // 操作后的状态
+	Reason     string `gorm:"size:500" json:"reason"`
+
+	Operator User `gorm:"foreignKey:OperatorID" json:"operator,omitempty"`
+}
+
+// TableName 指定表名
+func (UserStatusLog) TableName() string {
+	return "user_status_logs"
+}
+
+// 一次性验证令牌用途
+const (
+	TokenPurposeEmailVerify   = "email_verify"
+	TokenPurposePasswordReset = "password_reset"
+)
+
+// VerificationToken 邮箱验证、密码重置等一次性令牌，只保存令牌的SHA-256哈希
+type VerificationToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:20;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Email     string     `gorm:"size:100" json:"email"` // 签发时的邮箱，邮箱变更后旧令牌失效
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// TableName 指定表名
+func (VerificationToken) TableName() string {
+	return "verification_tokens"
+}
+
+// UserIdentity 绑定到用户的第三方账号
+type UserIdentity struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Provider string `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_provider_subject" json:"-"`
+	Email    string `gorm:"size:100" json:"email"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
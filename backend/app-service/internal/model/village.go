@@ -19,9 +19,18 @@ type Village struct {
 	Category    string `gorm:"size:50" json:"category"`
 	MemberCount int    `gorm:"default:0" json:"member_count"`
 	PostCount   int    `gorm:"default:0" json:"post_count"`
+	PostPolicy  string `gorm:"size:20;default:'open'" json:"post_policy"` // open/approval/members
+	AllowAnon   bool   `gorm:"default:true" json:"allow_anon"`            // 是否允许匿名发帖（心里话）
 	Status      int    `gorm:"default:1" json:"status"`
 }
 
+// 村落发帖策略
+const (
+	PostPolicyOpen     = "open"     // 任何人可直接发帖
+	PostPolicyApproval = "approval" // 发帖需管理员审核
+	PostPolicyMembers  = "members"  // 仅村落成员可发帖
+)
+
 // TableName 指定表名
 func (Village) TableName() string {
 	return "villages"
@@ -37,6 +46,13 @@ type VillageMember struct {
 	Role      int  `gorm:"default:0" json:"role"` // 0:成员 1:管理员 2:创建者
 }
 
+// 村落成员角色
+const (
+	VillageRoleMember  = 0
+	VillageRoleAdmin   = 1
+	VillageRoleCreator = 2
+)
+
 // TableName 指定表名
 func (VillageMember) TableName() string {
 	return "village_members"
@@ -55,12 +71,35 @@ type Post struct {
 	Images    string `gorm:"type:text" json:"images"` // JSON格式存储图片URL
 	Likes     int    `gorm:"default:0" json:"likes"`
 	Comments  int    `gorm:"default:0" json:"comments"`
-	Status    int    `gorm:"default:1" json:"status"`
+	Status    int    `gorm:"default:1;index" json:"status"` // 0:删除 1:正常 2:待审核 3:已驳回
+	IsPinned  bool   `gorm:"default:false" json:"is_pinned"`
+
+	Engagement float64 `gorm:"default:0" json:"-"`               // 互动分，按点赞和回复加权累加
+	HotScore   float64 `gorm:"default:0;index" json:"hot_score"` // 热度，见ranking包
 
-	Author  User    `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
-	Village Village `gorm:"foreignKey:VillageID" json:"village,omitempty"`
+	// 匿名帖子对外只展示AnonName，AuthorID仅用于作者本人操作和审计
+	IsAnonymous bool   `gorm:"default:false" json:"is_anonymous"`
+	AnonName    string `gorm:"size:50" json:"anon_name,omitempty"`
+	IsMine      bool   `gorm:"-" json:"is_mine"`
+
+	PinnedAt     *time.Time `json:"pinned_at,omitempty"`
+	ReviewedBy   *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	RejectReason string     `gorm:"size:255" json:"reject_reason,omitempty"`
+
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Mentions []Mention `gorm:"polymorphic:Source;polymorphicValue:post" json:"mentions,omitempty"`
+	Village  Village   `gorm:"foreignKey:VillageID" json:"village,omitempty"`
 }
 
+// 帖子状态
+const (
+	PostStatusDeleted  = 0
+	PostStatusNormal   = 1
+	PostStatusPending  = 2
+	PostStatusRejected = 3
+)
+
 // TableName 指定表名
 func (Post) TableName() string {
 	return "posts"
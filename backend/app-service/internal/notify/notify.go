@@ -0,0 +1,112 @@
+// Package notify 创建站内通知并实时推送给在线的接收人。
+// 通知发给谁由业务服务决定，这里只负责写入、按内容合并点赞通知和推送
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SnippetLength 通知中内容摘要的最大长度
+const SnippetLength = 100
+
+// TargetTypeNames 内容类型的展示名称
+var TargetTypeNames = map[string]string{
+	model.TargetTypeQuestion: "问题",
+	model.TargetTypeAnswer:   "回答",
+	model.TargetTypeNote:     "笔记",
+	model.TargetTypePost:     "帖子",
+	model.TargetTypeComment:  "评论",
+	model.TargetTypeMessage:  "消息",
+}
+
+// Send 创建站内通知并实时推送，不通知用户本人，推送失败只记录日志。
+// 点赞按内容合并，同一用户重复点赞不重复通知；其他类型每条单独展示
+func Send(db *gorm.DB, n model.Notification) error {
+	if n.UserID == 0 || n.UserID == n.ActorID {
+		return nil
+	}
+	n.Content = Snippet(n.Content, SnippetLength)
+
+	if n.Type == model.NotifyLike {
+		n.GroupKey = fmt.Sprintf("%s:%s:%d", n.Type, n.TargetType, n.TargetID)
+		var count int64
+		if err := db.Model(&model.Notification{}).
+			Where("user_id = ? AND group_key = ? AND actor_id = ?", n.UserID, n.GroupKey, n.ActorID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := db.Create(&n).Error; err != nil {
+			return err
+		}
+		publish(db, n)
+		return nil
+	}
+
+	// 分组键需要通知ID，先以临时值写入
+	n.GroupKey = fmt.Sprintf("pending:%d", time.Now().UnixNano())
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&n).Error; err != nil {
+			return err
+		}
+		return tx.Model(&n).Update("group_key", fmt.Sprintf("%s:%d", n.Type, n.ID)).Error
+	})
+	if err != nil {
+		return err
+	}
+	publish(db, n)
+	return nil
+}
+
+// Summary 生成通知的展示文案，actorCount为合并展示的点赞人数
+func Summary(n model.Notification, actorCount int64) string {
+	actor := n.Actor.Username
+	if n.ActorID == 0 {
+		actor = n.ActorName
+	}
+	if actor == "" {
+		actor = "有人"
+	}
+	target := TargetTypeNames[n.TargetType]
+
+	switch n.Type {
+	case model.NotifyAnswer:
+		return actor + "回答了你的问题"
+	case model.NotifyComment:
+		return fmt.Sprintf("%s评论了你的%s", actor, target)
+	case model.NotifyReply:
+		return actor + "回复了你的评论"
+	case model.NotifyLike:
+		if actorCount > 1 {
+			return fmt.Sprintf("%s等%d人赞了你的%s", actor, actorCount, target)
+		}
+		return fmt.Sprintf("%s赞了你的%s", actor, target)
+	case model.NotifyMention:
+		return fmt.Sprintf("%s在%s中提到了你", actor, target)
+	}
+	// 系统通知直接展示内容
+	return n.Content
+}
+
+// CountUnread 统计未读通知数
+func CountUnread(db *gorm.DB, userID uint) int64 {
+	var count int64
+	db.Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count)
+	return count
+}
+
+// Snippet 截取内容摘要，超出limit个字符的部分以省略号代替
+func Snippet(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pubsub"
+
+	"gorm.io/gorm"
+)
+
+// Event 推送给客户端的事件，notification事件的ID为通知ID，用于断线续传
+type Event struct {
+	ID    uint            `json:"id,omitempty"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// notificationPayload notification事件的内容
+type notificationPayload struct {
+	Notification model.Notification `json:"notification"`
+	Summary      string             `json:"summary"`
+	Unread       int64              `json:"unread"`
+}
+
+// Channel 用户的通知推送频道
+func Channel(userID uint) string {
+	return fmt.Sprintf("notify:%d", userID)
+}
+
+// NotificationEvent 构造新通知事件，不包含操作人的邮箱
+func NotificationEvent(n model.Notification, unread int64) Event {
+	n.Actor.Email = nil
+	data, _ := json.Marshal(notificationPayload{
+		Notification: n,
+		Summary:      Summary(n, 1),
+		Unread:       unread,
+	})
+	return Event{ID: n.ID, Event: "notification", Data: data}
+}
+
+// UnreadEvent 构造未读数事件
+func UnreadEvent(unread int64) Event {
+	data, _ := json.Marshal(map[string]int64{"unread": unread})
+	return Event{Event: "unread", Data: data}
+}
+
+// PublishUnread 向用户推送最新未读数，用于标记已读后同步其他设备的角标
+func PublishUnread(db *gorm.DB, userID uint) {
+	publishEvent(userID, UnreadEvent(CountUnread(db, userID)))
+}
+
+// publish 向用户推送新通知，推送失败只记录日志
+func publish(db *gorm.DB, n model.Notification) {
+	if n.ActorID != 0 {
+		db.First(&n.Actor, n.ActorID)
+	}
+	publishEvent(n.UserID, NotificationEvent(n, CountUnread(db, n.UserID)))
+}
+
+func publishEvent(userID uint, event Event) {
+	broker := pubsub.Get()
+	if broker == nil {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := broker.Publish(ctx, Channel(userID), payload); err != nil {
+		log.Printf("Failed to publish notification event for user %d: %v", userID, err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package oauth 实现第三方OAuth2/OIDC登录
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"ai-egg/app-service/internal/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// Profile 第三方账号信息
+type Profile struct {
+	Subject       string // 第三方账号的唯一标识
+	Email         string
+	EmailVerified bool
+	Username      string
+	Name          string
+	AvatarURL     string
+}
+
+// Provider 第三方登录提供方。配置了Issuer时按OIDC处理（自动发现端点并校验ID Token），
+// 否则按普通OAuth2处理，通过UserInfoURL获取账号信息
+type Provider struct {
+	Name        string
+	oauth2      oauth2.Config
+	verifier    *oidc.IDTokenVerifier
+	userInfoURL string
+}
+
+// NewProvider 根据配置创建提供方，OIDC提供方会请求发现文档
+func NewProvider(ctx context.Context, cfg config.OAuthProviderConfig) (*Provider, error) {
+	p := &Provider{
+		Name: cfg.Name,
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+		},
+		userInfoURL: cfg.UserInfoURL,
+	}
+
+	if cfg.Issuer != "" {
+		discovered, err := oidc.NewProvider(ctx, cfg.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("discover %s: %w", cfg.Name, err)
+		}
+		p.oauth2.Endpoint = discovered.Endpoint()
+		p.verifier = discovered.Verifier(&oidc.Config{ClientID: cfg.ClientID})
+		if len(p.oauth2.Scopes) == 0 {
+			p.oauth2.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+		}
+		if p.userInfoURL == "" {
+			var claims struct {
+				UserInfoURL string `json:"userinfo_endpoint"`
+			}
+			if err := discovered.Claims(&claims); err == nil {
+				p.userInfoURL = claims.UserInfoURL
+			}
+		}
+	}
+	return p, nil
+}
+
+// AuthCodeURL 生成跳转到第三方授权页的地址，使用PKCE（S256）
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(codeVerifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+	if p.verifier != nil {
+		opts = append(opts, oidc.Nonce(nonce))
+	}
+	return p.oauth2.AuthCodeURL(state, opts...)
+}
+
+// Exchange 用授权码换取令牌并获取第三方账号信息
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error) {
+	tok, err := p.oauth2.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	if p.verifier != nil {
+		rawIDToken, ok := tok.Extra("id_token").(string)
+		if !ok {
+			return nil, errors.New("id_token missing in token response")
+		}
+		idToken, err := p.verifier.Verify(ctx, rawIDToken)
+		if err != nil {
+			return nil, fmt.Errorf("verify id_token: %w", err)
+		}
+		if idToken.Nonce != nonce {
+			return nil, errors.New("id_token nonce mismatch")
+		}
+
+		var claims userInfoClaims
+		if err := idToken.Claims(&claims); err != nil {
+			return nil, err
+		}
+		profile := claims.profile()
+		profile.Subject = idToken.Subject
+
+		// ID Token中缺少的资料从UserInfo补充，Subject以ID Token为准
+		if p.userInfoURL != "" && (profile.Email == "" || profile.AvatarURL == "") {
+			if extra, err := p.fetchUserInfo(ctx, tok); err == nil {
+				if profile.Email == "" {
+					profile.Email, profile.EmailVerified = extra.Email, extra.EmailVerified
+				}
+				if profile.AvatarURL == "" {
+					profile.AvatarURL = extra.AvatarURL
+				}
+				if profile.Username == "" {
+					profile.Username = extra.Username
+				}
+			}
+		}
+		return profile, nil
+	}
+
+	if p.userInfoURL == "" {
+		return nil, errors.New("userinfo url not configured")
+	}
+	profile, err := p.fetchUserInfo(ctx, tok)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Subject == "" {
+		return nil, errors.New("userinfo response missing subject")
+	}
+	return profile, nil
+}
+
+func (p *Provider) fetchUserInfo(ctx context.Context, tok *oauth2.Token) (*Profile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.oauth2.Client(ctx, tok).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo returned status %d", resp.StatusCode)
+	}
+
+	var claims userInfoClaims
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, err
+	}
+	return claims.profile(), nil
+}
+
+// userInfoClaims 兼容OIDC标准字段和GitHub等常见OAuth2接口的字段
+type userInfoClaims struct {
+	Sub               string          `json:"sub"`
+	ID                json.RawMessage `json:"id"`
+	Email             string          `json:"email"`
+	EmailVerified     *bool           `json:"email_verified"`
+	PreferredUsername string          `json:"preferred_username"`
+	Login             string          `json:"login"`
+	Name              string          `json:"name"`
+	Picture           string          `json:"picture"`
+	AvatarURL         string          `json:"avatar_url"`
+}
+
+func (c userInfoClaims) profile() *Profile {
+	profile := &Profile{
+		Subject:   c.Sub,
+		Email:     c.Email,
+		Username:  c.PreferredUsername,
+		Name:      c.Name,
+		AvatarURL: c.Picture,
+	}
+	if profile.Subject == "" && len(c.ID) > 0 {
+		// id可能是数字或字符串
+		var s string
+		if err := json.Unmarshal(c.ID, &s); err == nil {
+			profile.Subject = s
+		} else {
+			var n int64
+			if err := json.Unmarshal(c.ID, &n); err == nil {
+				profile.Subject = strconv.FormatInt(n, 10)
+			}
+		}
+	}
+	if profile.Username == "" {
+		profile.Username = c.Login
+	}
+	if profile.AvatarURL == "" {
+		profile.AvatarURL = c.AvatarURL
+	}
+	profile.EmailVerified = c.EmailVerified != nil && *c.EmailVerified
+	return profile
+}
+
+var providers = map[string]*Provider{}
+
+// Init 设置已启用的提供方
+func Init(list []*Provider) {
+	providers = make(map[string]*Provider, len(list))
+	for _, p := range list {
+		providers[p.Name] = p
+	}
+}
+
+// GetProvider 按名称获取提供方
+func GetProvider(name string) (*Provider, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// ProviderNames 已启用的提供方名称，按字母排序
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomString 生成URL安全的随机字符串，用于state、nonce和PKCE
+func RandomString() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/oauth/oauthtest"
+)
+
+const testRedirectURL = "http://localhost:5173/oauth/callback"
+
+// authorize 走一遍授权页，返回授权码
+func authorize(t *testing.T, server *oauthtest.Server, p *Provider, state, nonce, verifier string) string {
+	t.Helper()
+	code, gotState, err := server.Authorize(p.AuthCodeURL(state, nonce, verifier))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if gotState != state {
+		t.Fatalf("state = %q, want %q", gotState, state)
+	}
+	return code
+}
+
+func TestOIDCExchange(t *testing.T) {
+	server := oauthtest.NewServer()
+	defer server.Close()
+	server.SetUser(oauthtest.User{Subject: "42", Email: "alice@example.com", EmailVerified: true, Username: "alice", Picture: "https://example.com/a.png"})
+
+	p, err := NewProvider(context.Background(), server.ProviderConfig("mock", testRedirectURL))
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+
+	state, nonce, verifier := RandomString(), RandomString(), RandomString()
+	code := authorize(t, server, p, state, nonce, verifier)
+
+	profile, err := p.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	want := Profile{Subject: "42", Email: "alice@example.com", EmailVerified: true, Username: "alice", AvatarURL: "https://example.com/a.png"}
+	if *profile != want {
+		t.Fatalf("profile = %+v, want %+v", *profile, want)
+	}
+
+	// 授权码只能使用一次
+	if _, err := p.Exchange(context.Background(), code, verifier, nonce); err == nil {
+		t.Fatalf("reused code was accepted")
+	}
+}
+
+func TestOIDCExchangeRejectsMismatch(t *testing.T) {
+	server := oauthtest.NewServer()
+	defer server.Close()
+
+	p, err := NewProvider(context.Background(), server.ProviderConfig("mock", testRedirectURL))
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+
+	verifier := RandomString()
+	code := authorize(t, server, p, RandomString(), "nonce", verifier)
+	if _, err := p.Exchange(context.Background(), code, verifier, "other-nonce"); err == nil {
+		t.Fatalf("nonce mismatch was accepted")
+	}
+
+	code = authorize(t, server, p, RandomString(), "nonce", verifier)
+	if _, err := p.Exchange(context.Background(), code, RandomString(), "nonce"); err == nil {
+		t.Fatalf("PKCE verifier mismatch was accepted")
+	}
+}
+
+func TestOAuth2UserInfo(t *testing.T) {
+	server := oauthtest.NewServer()
+	defer server.Close()
+	server.SetUser(oauthtest.User{Subject: "7", Email: "bob@example.com", Username: "bob"})
+
+	// 未配置Issuer时按普通OAuth2处理，资料来自UserInfo接口
+	p, err := NewProvider(context.Background(), config.OAuthProviderConfig{
+		Name:         "plain",
+		ClientID:     oauthtest.ClientID,
+		ClientSecret: oauthtest.ClientSecret,
+		RedirectURL:  testRedirectURL,
+		AuthURL:      server.URL + "/authorize",
+		TokenURL:     server.URL + "/token",
+		UserInfoURL:  server.URL + "/userinfo",
+	})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+
+	verifier := RandomString()
+	code := authorize(t, server, p, RandomString(), "", verifier)
+	profile, err := p.Exchange(context.Background(), code, verifier, "")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if profile.Subject != "7" || profile.Email != "bob@example.com" || profile.EmailVerified || profile.Username != "bob" {
+		t.Fatalf("profile = %+v", *profile)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	defer Init(nil)
+	Init([]*Provider{{Name: "google"}, {Name: "github"}})
+
+	if names := ProviderNames(); len(names) != 2 || names[0] != "github" || names[1] != "google" {
+		t.Fatalf("ProviderNames = %v", names)
+	}
+	if _, err := GetProvider("unknown"); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("GetProvider(unknown) = %v", err)
+	}
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	store := NewMemoryStateStore()
+	if err := store.Save("key", State{Provider: "mock", LinkUserID: 3}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	state, err := store.Take("key")
+	if err != nil || state.Provider != "mock" || state.LinkUserID != 3 {
+		t.Fatalf("take = %+v, %v", state, err)
+	}
+	if _, err := store.Take("key"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("second take = %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidState state不存在、已使用或已过期
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// StateTTL 从发起授权到回调的最长时间
+const StateTTL = 10 * time.Minute
+
+// State 发起授权时保存的上下文，回调时取出且只能使用一次
+type State struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	LinkUserID   uint   `json:"link_user_id,omitempty"` // 非0表示将第三方账号绑定到该用户
+}
+
+// StateStore state存储
+type StateStore interface {
+	Save(key string, state State) error
+	// Take 取出并删除state
+	Take(key string) (State, error)
+}
+
+// MemoryStateStore 基于内存的state存储，仅适用于单实例部署
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryState
+}
+
+type memoryState struct {
+	state     State
+	expiresAt time.Time
+}
+
+// NewMemoryStateStore 创建内存state存储
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{entries: make(map[string]memoryState)}
+}
+
+func (s *MemoryStateStore) Save(key string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryState{state: state, expiresAt: now.Add(StateTTL)}
+	return nil
+}
+
+func (s *MemoryStateStore) Take(key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	delete(s.entries, key)
+	if !ok || time.Now().After(e.expiresAt) {
+		return State{}, ErrInvalidState
+	}
+	return e.state, nil
+}
+
+// RedisStateStore 基于Redis的state存储，多实例共享
+type RedisStateStore struct {
+	client *redis.Client
+}
+
+// NewRedisStateStore 创建Redis state存储
+func NewRedisStateStore(client *redis.Client) *RedisStateStore {
+	return &RedisStateStore{client: client}
+}
+
+const stateKeyPrefix = "oauth:state:"
+
+func (s *RedisStateStore) Save(key string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(context.Background(), stateKeyPrefix+key, data, StateTTL).Err()
+}
+
+func (s *RedisStateStore) Take(key string) (State, error) {
+	data, err := s.client.GetDel(context.Background(), stateKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return State{}, ErrInvalidState
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+var stateStore StateStore = NewMemoryStateStore()
+
+// InitStateStore 设置全局state存储
+func InitStateStore(s StateStore) {
+	stateStore = s
+}
+
+// GetStateStore 获取全局state存储
+func GetStateStore() StateStore {
+	return stateStore
+}
@@ -0,0 +1,183 @@
+// Package pagination 列表分页。默认使用基于(created_at, id)的游标分页，
+// 请求携带page参数时为兼容旧客户端使用页码分页
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultLimit 未指定每页数量时的默认值
+	DefaultLimit = 20
+	// MaxLimit 每页数量上限
+	MaxLimit = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidParams = errors.New("invalid pagination params")
+)
+
+// Cursor 游标，指向上一页的最后一条记录
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// Encode 将游标编码为不透明的字符串
+func (c Cursor) Encode() string {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(c.CreatedAt.UnixNano()))
+	binary.BigEndian.PutUint64(buf[8:], uint64(c.ID))
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeCursor 解析Encode生成的游标
+func DecodeCursor(s string) (Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(buf) != 16 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{
+		CreatedAt: time.Unix(0, int64(binary.BigEndian.Uint64(buf[:8]))),
+		ID:        uint(binary.BigEndian.Uint64(buf[8:])),
+	}, nil
+}
+
+// Params 分页参数
+type Params struct {
+	Limit     int
+	Cursor    *Cursor // 游标分页时上一页的位置，为空表示第一页
+	Page      int     // 页码分页时的页码，为0表示使用游标分页
+	WithTotal bool    // 是否需要总数，页码分页总是返回总数
+}
+
+// Parse 解析查询参数：cursor、limit（兼容pageSize）、page和withTotal。
+// 每页数量超出上限时按上限处理
+func Parse(c *gin.Context, defaultLimit int) (Params, error) {
+	p := Params{Limit: defaultLimit}
+
+	limit := c.Query("limit")
+	if limit == "" {
+		limit = c.Query("pageSize")
+	}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return p, ErrInvalidParams
+		}
+		p.Limit = n
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return p, err
+		}
+		p.Cursor = &decoded
+	} else if page := c.Query("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return p, ErrInvalidParams
+		}
+		p.Page = n
+	}
+
+	p.WithTotal = p.Page > 0 || c.Query("withTotal") == "true"
+	return p, nil
+}
+
+// Total 需要总数时统计查询的记录数，否则返回nil
+func (p Params) Total(query *gorm.DB) *int64 {
+	if !p.WithTotal {
+		return nil
+	}
+	var total int64
+	query.Session(&gorm.Session{}).Count(&total)
+	return &total
+}
+
+// Apply 为查询添加按(created_at, id)倒序的排序、游标条件和数量限制。
+// 多取一条用于判断是否还有下一页，查询结果需经Trim处理
+func (p Params) Apply(query *gorm.DB) *gorm.DB {
+	query = query.Order("created_at DESC").Order("id DESC").Limit(p.Limit + 1)
+	if p.Page > 0 {
+		return query.Offset((p.Page - 1) * p.Limit)
+	}
+	if p.Cursor != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)",
+			p.Cursor.CreatedAt, p.Cursor.CreatedAt, p.Cursor.ID)
+	}
+	return query
+}
+
+// ApplyOrder 按指定排序（如热度）分页。这类排序值会随时间变化，无法使用游标，
+// 只支持页码分页，未指定page时为第一页
+func (p Params) ApplyOrder(query *gorm.DB, order string) *gorm.DB {
+	page := p.Page
+	if page < 1 {
+		page = 1
+	}
+	return query.Order(order).Order("id DESC").Limit(p.Limit + 1).Offset((page - 1) * p.Limit)
+}
+
+// Page 一页查询结果的分页信息
+type Page struct {
+	HasMore    bool
+	NextCursor string
+	Total      *int64
+}
+
+// Trim 去掉Apply或ApplyOrder多取的一条记录，并生成下一页的游标。
+// total为Params.Total的结果，cursor为nil时不生成游标
+func Trim[T any](p Params, items []T, total *int64, cursor func(T) Cursor) ([]T, Page) {
+	page := Page{Total: total}
+	if len(items) > p.Limit {
+		items = items[:p.Limit]
+		page.HasMore = true
+	}
+	if page.HasMore && len(items) > 0 && cursor != nil {
+		page.NextCursor = cursor(items[len(items)-1]).Encode()
+	}
+	return items, page
+}
+
+// Data 组装列表响应：list、hasMore、nextCursor，需要总数时包含total
+func (page Page) Data(list interface{}) gin.H {
+	data := gin.H{
+		"list":       list,
+		"hasMore":    page.HasMore,
+		"nextCursor": page.NextCursor,
+	}
+	if page.Total != nil {
+		data["total"] = *page.Total
+	}
+	return data
+}
+
+// PageParams 解析只支持页码分页的列表参数page和pageSize，
+// 无效值按默认值处理，每页数量不超过MaxLimit
+func PageParams(c *gin.Context, defaultLimit int) (page, pageSize int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err = strconv.Atoi(c.Query("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultLimit
+	}
+	if pageSize > MaxLimit {
+		pageSize = MaxLimit
+	}
+	return page, pageSize
+}
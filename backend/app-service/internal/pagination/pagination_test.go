@@ -0,0 +1,196 @@
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), ID: 42}
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Fatalf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	valid := Cursor{CreatedAt: time.Now(), ID: 7}.Encode()
+	for _, s := range []string{
+		"not a cursor!",
+		valid[:len(valid)-2],
+		valid + "AA",
+		"MTIz",
+	} {
+		if _, err := DecodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Unix(1700000000, 0), ID: 9}
+	tests := []struct {
+		query     string
+		err       error
+		limit     int
+		page      int
+		cursor    bool
+		withTotal bool
+	}{
+		{query: "", limit: 20},
+		{query: "limit=5", limit: 5},
+		{query: "pageSize=8", limit: 8},
+		{query: "limit=1000", limit: MaxLimit},
+		{query: "limit=0", err: ErrInvalidParams},
+		{query: "limit=abc", err: ErrInvalidParams},
+		{query: "page=2", limit: 20, page: 2, withTotal: true},
+		{query: "page=0", err: ErrInvalidParams},
+		{query: "withTotal=true", limit: 20, withTotal: true},
+		{query: "cursor=" + cursor.Encode(), limit: 20, cursor: true},
+		{query: "cursor=" + cursor.Encode() + "&page=3", limit: 20, cursor: true},
+		{query: "cursor=%25%25bad", err: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+		p, err := Parse(c, 20)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.query, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.query, err)
+			continue
+		}
+		if p.Limit != tt.limit || p.Page != tt.page || (p.Cursor != nil) != tt.cursor || p.WithTotal != tt.withTotal {
+			t.Errorf("Parse(%q) = %+v", tt.query, p)
+		}
+		if tt.cursor && (p.Cursor.ID != cursor.ID || !p.Cursor.CreatedAt.Equal(cursor.CreatedAt)) {
+			t.Errorf("Parse(%q) cursor = %+v, want %+v", tt.query, *p.Cursor, cursor)
+		}
+	}
+}
+
+type item struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Score     int
+}
+
+func itemCursor(i item) Cursor {
+	return Cursor{CreatedAt: i.CreatedAt, ID: i.ID}
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// 同一时间创建的记录按ID区分先后，翻页时不重复也不遗漏
+func TestApplyCursorTies(t *testing.T) {
+	db := newTestDB(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := []time.Time{base, base.Add(time.Minute), base.Add(time.Minute), base.Add(time.Minute), base.Add(2 * time.Minute)}
+	for i, at := range created {
+		if err := db.Create(&item{CreatedAt: at, Score: i}).Error; err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	var seen []uint
+	p := Params{Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > len(created) {
+			t.Fatalf("pagination did not terminate")
+		}
+		var items []item
+		if err := p.Apply(db.Model(&item{})).Find(&items).Error; err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		items, page := Trim(p, items, nil, itemCursor)
+		for _, i := range items {
+			seen = append(seen, i.ID)
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Fatalf("last page has next cursor")
+			}
+			break
+		}
+		cursor, err := DecodeCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("decode next cursor: %v", err)
+		}
+		p.Cursor = &cursor
+	}
+
+	want := []uint{5, 4, 3, 2, 1}
+	if len(seen) != len(want) {
+		t.Fatalf("walked %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("walked %v, want %v", seen, want)
+		}
+	}
+}
+
+// 按分值排序时只支持页码分页，不返回游标
+func TestApplyOrderPages(t *testing.T) {
+	db := newTestDB(t)
+	for _, score := range []int{3, 1, 2} {
+		if err := db.Create(&item{CreatedAt: time.Now(), Score: score}).Error; err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	var first []item
+	p := Params{Limit: 2}
+	if err := p.ApplyOrder(db.Model(&item{}), "score DESC").Find(&first).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	first, page := Trim(p, first, nil, nil)
+	if len(first) != 2 || first[0].Score != 3 || first[1].Score != 2 || !page.HasMore || page.NextCursor != "" {
+		t.Fatalf("first page = %+v, %+v", first, page)
+	}
+
+	var second []item
+	p.Page = 2
+	if err := p.ApplyOrder(db.Model(&item{}), "score DESC").Find(&second).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	second, page = Trim(p, second, nil, nil)
+	if len(second) != 1 || second[0].Score != 1 || page.HasMore {
+		t.Fatalf("second page = %+v, %+v", second, page)
+	}
+}
@@ -0,0 +1,93 @@
+// Package pubsub 提供频道发布订阅，多实例部署时通过Redis在实例间转发消息，
+// Redis不可用时使用进程内实现
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriptionBuffer 每个订阅的消息缓冲，订阅方处理过慢时丢弃新消息
+const subscriptionBuffer = 16
+
+// Broker 发布订阅
+type Broker interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) (*Subscription, error)
+}
+
+// Subscription 一个频道订阅，使用完毕后需调用Close
+type Subscription struct {
+	C <-chan []byte
+
+	once  sync.Once
+	close func()
+}
+
+// Close 取消订阅，可重复调用
+func (s *Subscription) Close() {
+	s.once.Do(s.close)
+}
+
+var broker Broker
+
+// Init 设置全局发布订阅
+func Init(b Broker) {
+	broker = b
+}
+
+// Get 获取全局发布订阅，未初始化时返回nil
+func Get() Broker {
+	return broker
+}
+
+// MemoryBroker 进程内发布订阅，仅适用于单实例部署
+type MemoryBroker struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan []byte]struct{}
+}
+
+// NewMemoryBroker 创建进程内发布订阅
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: make(map[string]map[chan []byte]struct{})}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs[channel] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, channel string) (*Subscription, error) {
+	ch := make(chan []byte, subscriptionBuffer)
+
+	b.mu.Lock()
+	if b.subs[channel] == nil {
+		b.subs[channel] = make(map[chan []byte]struct{})
+	}
+	b.subs[channel][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return &Subscription{C: ch, close: func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[channel], ch)
+		if len(b.subs[channel]) == 0 {
+			delete(b.subs, channel)
+		}
+		close(ch)
+	}}, nil
+}
+
+// subscriberCount 返回频道的本地订阅数
+func (b *MemoryBroker) subscriberCount(channel string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[channel])
+}
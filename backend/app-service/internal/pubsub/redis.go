@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBroker 基于Redis的发布订阅。每个实例只占用一个Redis订阅连接，
+// 按本地订阅情况增减Redis频道，收到的消息在进程内分发
+type RedisBroker struct {
+	client *redis.Client
+	local  *MemoryBroker
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+}
+
+// NewRedisBroker 创建基于Redis的发布订阅
+func NewRedisBroker(client *redis.Client) *RedisBroker {
+	return &RedisBroker{client: client, local: NewMemoryBroker()}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, channel string, payload []byte) error {
+	return b.client.Publish(ctx, channel, payload).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, channel string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.local.subscriberCount(channel) == 0 {
+		if err := b.redisSubscribe(ctx, channel); err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := b.local.Subscribe(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	release := sub.close
+	sub.close = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		release()
+		if b.local.subscriberCount(channel) == 0 {
+			b.pubsub.Unsubscribe(context.Background(), channel)
+		}
+	}
+	return sub, nil
+}
+
+// redisSubscribe 订阅Redis频道，首次订阅时建立连接并开始转发消息，调用方需持有锁
+func (b *RedisBroker) redisSubscribe(ctx context.Context, channel string) error {
+	if b.pubsub != nil {
+		return b.pubsub.Subscribe(ctx, channel)
+	}
+
+	b.pubsub = b.client.Subscribe(ctx, channel)
+	// 等待订阅确认，确保连接可用
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		b.pubsub.Close()
+		b.pubsub = nil
+		return err
+	}
+	go b.forward(b.pubsub.Channel())
+	return nil
+}
+
+// forward 将Redis收到的消息分发给本地订阅
+func (b *RedisBroker) forward(messages <-chan *redis.Message) {
+	for msg := range messages {
+		b.local.Publish(context.Background(), msg.Channel, []byte(msg.Payload))
+	}
+}
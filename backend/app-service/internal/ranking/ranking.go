@@ -0,0 +1,126 @@
+// Package ranking 计算问题和帖子的热度。热度 = log10(互动分) + 发布时间/Gravity，
+// 互动分按点赞、回答、评论和浏览加权累加，随互动增量更新，无需定时重算
+package ranking
+
+import (
+	"math"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// 各类互动计入互动分的权重
+const (
+	WeightLike    = 1.0
+	WeightAnswer  = 3.0
+	WeightComment = 2.0
+	WeightView    = 0.1
+)
+
+// Gravity 时间衰减系数（秒）：晚发布12.5小时的内容只需十分之一的互动分即可获得相同热度
+const Gravity = 45000.0
+
+// epoch 热度的时间起点，使热度值保持在较小的范围
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Score 由互动分和发布时间计算热度
+func Score(engagement float64, createdAt time.Time) float64 {
+	return math.Log10(math.Max(engagement, 1)) + createdAt.Sub(epoch).Seconds()/Gravity
+}
+
+// Bump 内容产生互动（或取消互动，weight为负）时更新互动分和热度，只支持问题和帖子
+func Bump(db *gorm.DB, targetType string, id uint, weight float64) error {
+	var m interface{}
+	switch targetType {
+	case model.TargetTypeQuestion:
+		m = &model.Question{}
+	case model.TargetTypePost:
+		m = &model.Post{}
+	default:
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(m).Where("id = ?", id).
+			UpdateColumn("engagement", gorm.Expr("engagement + ?", weight)).Error; err != nil {
+			return err
+		}
+		var row struct {
+			Engagement float64
+			CreatedAt  time.Time
+		}
+		if err := tx.Model(m).Select("engagement, created_at").Where("id = ?", id).Scan(&row).Error; err != nil {
+			return err
+		}
+		return tx.Model(m).Where("id = ?", id).UpdateColumn("hot_score", Score(row.Engagement, row.CreatedAt)).Error
+	})
+}
+
+// Backfill 为尚未计算热度的问题和帖子按已有的点赞、回答、评论和浏览数计算热度，
+// 用于升级后首次启动
+func Backfill(db *gorm.DB) error {
+	var questions []model.Question
+	err := db.Select("id, created_at, likes, views").Where("hot_score = 0").
+		FindInBatches(&questions, 500, func(tx *gorm.DB, batch int) error {
+			ids := make([]uint, len(questions))
+			for i, q := range questions {
+				ids[i] = q.ID
+			}
+			answers := countBy(db, &model.Answer{}, "question_id", ids, "")
+			comments := countBy(db, &model.Comment{}, "target_id", ids, model.TargetTypeQuestion)
+
+			for _, q := range questions {
+				engagement := float64(q.Likes)*WeightLike + float64(q.Views)*WeightView +
+					float64(answers[q.ID])*WeightAnswer + float64(comments[q.ID])*WeightComment
+				if err := db.Model(&model.Question{}).Where("id = ?", q.ID).UpdateColumns(map[string]interface{}{
+					"engagement": engagement,
+					"hot_score":  Score(engagement, q.CreatedAt),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	var posts []model.Post
+	return db.Select("id, created_at, likes, comments").Where("hot_score = 0").
+		FindInBatches(&posts, 500, func(tx *gorm.DB, batch int) error {
+			for _, p := range posts {
+				engagement := float64(p.Likes)*WeightLike + float64(p.Comments)*WeightComment
+				if err := db.Model(&model.Post{}).Where("id = ?", p.ID).UpdateColumns(map[string]interface{}{
+					"engagement": engagement,
+					"hot_score":  Score(engagement, p.CreatedAt),
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// countBy 按column分组统计正常状态的记录数，targetType非空时只统计该类型的评论
+func countBy(db *gorm.DB, m interface{}, column string, ids []uint, targetType string) map[uint]int64 {
+	query := db.Model(m).
+		Select(column+" AS id, COUNT(*) AS count").
+		Where(column+" IN ? AND status = ?", ids, model.ContentStatusNormal)
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var rows []struct {
+		ID    uint
+		Count int64
+	}
+	query.Group(column).Scan(&rows)
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts
+}
@@ -0,0 +1,53 @@
+package ranking
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ViewWindow 同一访客在该时间内重复浏览同一内容只计入一次热度
+const ViewWindow = time.Hour
+
+// viewTracker 记录访客最近一次计入热度的浏览，仅在本实例内去重
+type viewTracker struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+var views = &viewTracker{seen: make(map[string]time.Time)}
+
+// CountView 判断访客的这次浏览是否应计入热度，visitor为用户ID或游客IP等能区分访客的标识。
+// 同一访客对同一内容在ViewWindow内只返回一次true，避免刷新页面刷高热度
+func CountView(targetType string, id uint, visitor string) bool {
+	return views.count(fmt.Sprintf("%s:%d:%s", targetType, id, visitor), time.Now())
+}
+
+// ResetViews 清除浏览去重记录，切换数据库（如测试中重建内存数据库）后调用
+func ResetViews() {
+	views.mu.Lock()
+	defer views.mu.Unlock()
+	views.seen = make(map[string]time.Time)
+}
+
+func (t *viewTracker) count(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 每个窗口清理一次过期记录
+	if now.Sub(t.lastSweep) > ViewWindow {
+		for k, at := range t.seen {
+			if now.Sub(at) > ViewWindow {
+				delete(t.seen, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	if at, ok := t.seen[key]; ok && now.Sub(at) <= ViewWindow {
+		return false
+	}
+	t.seen[key] = now
+	return true
+}
@@ -0,0 +1,24 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestViewTracker(t *testing.T) {
+	tracker := &viewTracker{seen: make(map[string]time.Time)}
+	now := time.Now()
+
+	if !tracker.count("question:1:u1", now) {
+		t.Fatalf("first view was not counted")
+	}
+	if tracker.count("question:1:u1", now.Add(time.Minute)) {
+		t.Fatalf("repeated view within window was counted")
+	}
+	if !tracker.count("question:1:u2", now.Add(time.Minute)) {
+		t.Fatalf("view from another visitor was not counted")
+	}
+	if !tracker.count("question:1:u1", now.Add(ViewWindow+time.Minute)) {
+		t.Fatalf("view after window was not counted")
+	}
+}
@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LockoutPolicy 登录失败锁定策略：Window内失败Threshold次后锁定，
+// 每次锁定时长在上一次基础上翻倍，最长MaxDuration
+type LockoutPolicy struct {
+	Threshold    int
+	Window       time.Duration
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+}
+
+// DefaultLockoutPolicy 15分钟内失败5次锁定1分钟，之后依次2、4、8分钟……最长1小时
+var DefaultLockoutPolicy = LockoutPolicy{
+	Threshold:    5,
+	Window:       15 * time.Minute,
+	BaseDuration: time.Minute,
+	MaxDuration:  time.Hour,
+}
+
+// lockoutStore 锁定计数存储
+type lockoutStore interface {
+	// Incr 计数加一，计数器在ttl后过期（仅在首次创建时设置）
+	Incr(key string, ttl time.Duration) (int64, error)
+	// Lock 锁定key一段时间
+	Lock(key string, d time.Duration) error
+	// LockedFor 返回剩余锁定时间，未锁定时返回0
+	LockedFor(key string) (time.Duration, error)
+	Delete(keys ...string) error
+}
+
+// Lockout 登录失败锁定
+type Lockout struct {
+	store  lockoutStore
+	policy LockoutPolicy
+}
+
+// NewMemoryLockout 创建基于内存的登录失败锁定
+func NewMemoryLockout(policy LockoutPolicy) *Lockout {
+	return &Lockout{store: newMemoryLockoutStore(), policy: policy}
+}
+
+// NewRedisLockout 创建基于Redis的登录失败锁定
+func NewRedisLockout(client *redis.Client, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: &redisLockoutStore{client: client}, policy: policy}
+}
+
+// Check 返回key的剩余锁定时间，未锁定时返回0
+func (l *Lockout) Check(key string) (time.Duration, error) {
+	return l.store.LockedFor("lock:" + key)
+}
+
+// Fail 记录一次失败，达到阈值时锁定并返回锁定时长，否则返回0
+func (l *Lockout) Fail(key string) (time.Duration, error) {
+	failures, err := l.store.Incr("fail:"+key, l.policy.Window)
+	if err != nil {
+		return 0, err
+	}
+	if failures < int64(l.policy.Threshold) {
+		return 0, nil
+	}
+
+	// 锁定次数的计数保留一天，期间再次被锁定时长翻倍
+	lockouts, err := l.store.Incr("count:"+key, 24*time.Hour)
+	if err != nil {
+		return 0, err
+	}
+	d := l.policy.BaseDuration
+	for i := int64(1); i < lockouts && d < l.policy.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > l.policy.MaxDuration {
+		d = l.policy.MaxDuration
+	}
+
+	if err := l.store.Delete("fail:" + key); err != nil {
+		return 0, err
+	}
+	if err := l.store.Lock("lock:"+key, d); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
+
+// Reset 登录成功后清除失败记录
+func (l *Lockout) Reset(key string) error {
+	return l.store.Delete("fail:"+key, "count:"+key, "lock:"+key)
+}
+
+type memoryLockoutEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+type memoryLockoutStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryLockoutEntry
+}
+
+func newMemoryLockoutStore() *memoryLockoutStore {
+	return &memoryLockoutStore{entries: make(map[string]*memoryLockoutEntry)}
+}
+
+// get 返回未过期的记录，调用方需持有锁
+func (s *memoryLockoutStore) get(key string, now time.Time) *memoryLockoutEntry {
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expiresAt) {
+		delete(s.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (s *memoryLockoutStore) Incr(key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e := s.get(key, now)
+	if e == nil {
+		if len(s.entries) > 10000 {
+			for k, old := range s.entries {
+				if now.After(old.expiresAt) {
+					delete(s.entries, k)
+				}
+			}
+		}
+		e = &memoryLockoutEntry{expiresAt: now.Add(ttl)}
+		s.entries[key] = e
+	}
+	e.count++
+	return e.count, nil
+}
+
+func (s *memoryLockoutStore) Lock(key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &memoryLockoutEntry{count: 1, expiresAt: time.Now().Add(d)}
+	return nil
+}
+
+func (s *memoryLockoutStore) LockedFor(key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if e := s.get(key, now); e != nil {
+		return e.expiresAt.Sub(now), nil
+	}
+	return 0, nil
+}
+
+func (s *memoryLockoutStore) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+const lockoutKeyPrefix = "lockout:"
+
+type redisLockoutStore struct {
+	client *redis.Client
+}
+
+func (s *redisLockoutStore) Incr(key string, ttl time.Duration) (int64, error) {
+	ctx := context.Background()
+	key = lockoutKeyPrefix + key
+
+	n, err := s.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := s.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (s *redisLockoutStore) Lock(key string, d time.Duration) error {
+	return s.client.Set(context.Background(), lockoutKeyPrefix+key, 1, d).Err()
+}
+
+func (s *redisLockoutStore) LockedFor(key string) (time.Duration, error) {
+	d, err := s.client.PTTL(context.Background(), lockoutKeyPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// 键不存在或未设置过期时间时返回负值
+	if d < 0 {
+		return 0, nil
+	}
+	return d, nil
+}
+
+func (s *redisLockoutStore) Delete(keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = lockoutKeyPrefix + key
+	}
+	return s.client.Del(context.Background(), prefixed...).Err()
+}
+
+var lockout *Lockout
+
+// InitLockout 设置全局登录失败锁定，传nil表示关闭
+func InitLockout(l *Lockout) {
+	lockout = l
+}
+
+// GetLockout 获取全局登录失败锁定，未启用时返回nil
+func GetLockout() *Lockout {
+	return lockout
+}
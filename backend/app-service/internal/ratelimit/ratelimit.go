@@ -0,0 +1,162 @@
+// Package ratelimit 提供令牌桶限流和登录失败锁定，支持Redis和内存两种后端
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit 令牌桶参数：桶容量为Burst，每Interval补充一个令牌
+type Limit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// PerMinute 每分钟n次，允许突发n次
+func PerMinute(n int) Limit {
+	return Limit{Burst: n, Interval: time.Minute / time.Duration(n)}
+}
+
+// PerHour 每小时n次，允许突发n次
+func PerHour(n int) Limit {
+	return Limit{Burst: n, Interval: time.Hour / time.Duration(n)}
+}
+
+// Result 限流判定结果
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // 被拒绝时距离下一个令牌可用的时间
+}
+
+// Limiter 限流器
+type Limiter interface {
+	Allow(key string, limit Limit) (Result, error)
+}
+
+// MemoryLimiter 基于内存的令牌桶限流器，仅适用于单实例部署
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	ttl    time.Duration // 桶补满所需时间，超过后可以清理
+}
+
+// NewMemoryLimiter 创建内存限流器
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket)}
+}
+
+func (l *MemoryLimiter) Allow(key string, limit Limit) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		// 顺带清理已补满的桶，避免长期运行后无限增长
+		if len(l.buckets) > 10000 {
+			for k, old := range l.buckets {
+				if now.Sub(old.last) > old.ttl {
+					delete(l.buckets, k)
+				}
+			}
+		}
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.ttl = limit.Interval * time.Duration(limit.Burst)
+
+	elapsed := now.Sub(b.last)
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+float64(elapsed)/float64(limit.Interval))
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(limit.Interval))
+		return Result{Allowed: false, Remaining: 0, RetryAfter: wait}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// RedisLimiter 基于Redis的令牌桶限流器，多实例共享，使用Lua脚本保证原子性
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter 创建Redis限流器
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// tokenBucketScript KEYS[1]=桶, ARGV: 容量, 补充间隔(ms), 当前时间(ms)
+// 返回 {是否允许, 剩余令牌, 需等待ms}
+var tokenBucketScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  last = now
+end
+
+tokens = math.min(burst, tokens + (now - last) / interval)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) * interval)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", now)
+redis.call("PEXPIRE", KEYS[1], burst * interval)
+return {allowed, math.floor(tokens), wait}
+`)
+
+const limiterKeyPrefix = "ratelimit:"
+
+func (l *RedisLimiter) Allow(key string, limit Limit) (Result, error) {
+	res, err := tokenBucketScript.Run(context.Background(), l.client,
+		[]string{limiterKeyPrefix + key},
+		limit.Burst, limit.Interval.Milliseconds(), time.Now().UnixMilli(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+var limiter Limiter
+
+// Init 设置全局限流器，传nil表示关闭限流
+func Init(l Limiter) {
+	limiter = l
+}
+
+// GetLimiter 获取全局限流器，未启用时返回nil
+func GetLimiter() Limiter {
+	return limiter
+}
+
+// RetryAfterSeconds 将等待时间转换为Retry-After头的秒数，不足1秒按1秒计
+func RetryAfterSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,79 @@
+// Package rbac 定义平台角色及其权限，角色保存在users.role字段
+package rbac
+
+import "ai-egg/app-service/internal/model"
+
+// Permission 后台操作权限
+type Permission string
+
+const (
+	PermUserView      Permission = "user:view"      // 查看用户列表和详情
+	PermUserManage    Permission = "user:manage"    // 禁用/启用账号
+	PermRoleAssign    Permission = "role:assign"    // 修改用户角色
+	PermContentRemove Permission = "content:remove" // 下架/恢复任意内容
+	PermReportHandle  Permission = "report:handle"  // 处理举报
+	PermVillageManage Permission = "village:manage" // 创建、编辑、关闭村落
+	PermStatsView     Permission = "stats:view"     // 查看平台统计
+	PermLogView       Permission = "log:view"       // 查看后台操作记录
+)
+
+// rolePermissions 各角色拥有的权限，普通用户没有任何后台权限
+var rolePermissions = map[string][]Permission{
+	model.RoleModerator: {
+		PermUserView,
+		PermUserManage,
+		PermContentRemove,
+		PermReportHandle,
+		PermLogView,
+	},
+	model.RoleAdmin: {
+		PermUserView,
+		PermUserManage,
+		PermRoleAssign,
+		PermContentRemove,
+		PermReportHandle,
+		PermVillageManage,
+		PermStatsView,
+		PermLogView,
+	},
+}
+
+// roleRank 角色等级，只能管理等级低于自己的用户
+var roleRank = map[string]int{
+	model.RoleUser:      0,
+	model.RoleModerator: 1,
+	model.RoleAdmin:     2,
+}
+
+// ValidRole 是否为已定义的角色
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Has 判断角色是否拥有指定权限
+func Has(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions 返回角色拥有的全部权限，没有权限时返回空切片
+func Permissions(role string) []Permission {
+	perms := rolePermissions[role]
+	if perms == nil {
+		return []Permission{}
+	}
+	return perms
+}
+
+// Outranks 判断operator的角色等级是否高于target，管理员之间可以互相管理
+func Outranks(operator, target string) bool {
+	if operator == model.RoleAdmin {
+		return true
+	}
+	return roleRank[operator] > roleRank[target]
+}
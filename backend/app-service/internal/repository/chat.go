@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// ChatRepository 会话、消息和群聊成员数据访问
+type ChatRepository interface {
+	FindByID(id uint) (model.Chat, error)
+	// FindByIDs 按最近更新时间倒序查询会话
+	FindByIDs(ids []uint) ([]model.Chat, error)
+	// FindDirect 查询两个用户之间的私聊会话
+	FindDirect(userID, otherID uint) (model.Chat, error)
+	// FindByVillage 查询村落聊天室
+	FindByVillage(villageID uint) (model.Chat, error)
+	// FindOrCreateVillageChat 查询村落聊天室，不存在时使用chat创建
+	FindOrCreateVillageChat(chat *model.Chat) error
+	Create(chat *model.Chat) error
+	SetLastMessage(chatID uint, content string) error
+
+	CreateMessage(message *model.Message) error
+	// ListMessages 查询会话消息，不包含被管理员删除的消息
+	ListMessages(chatID uint, p pagination.Params) ([]model.Message, pagination.Page, error)
+	// LastMessageID 会话最新一条消息的ID，没有消息时为0
+	LastMessageID(chatID uint) (uint, error)
+	// CountUnread 统计lastReadID之后他人发送的消息数
+	CountUnread(chatID, userID, lastReadID uint) (int64, error)
+
+	FindMember(chatID, userID uint) (model.ChatMember, error)
+	// Memberships 用户加入的所有群聊成员记录
+	Memberships(userID uint) ([]model.ChatMember, error)
+	// ListMembers 按加入时间查询成员，包含用户信息
+	ListMembers(chatID uint) ([]model.ChatMember, error)
+	CountMembers(chatID uint) (int64, error)
+	// NonMembers 返回ids中存在且尚未加入会话的用户ID
+	NonMembers(chatID uint, ids []uint) ([]uint, error)
+	CreateMember(member *model.ChatMember) error
+	DeleteMember(member model.ChatMember) error
+	// EarliestMember 最早加入的成员
+	EarliestMember(chatID uint) (model.ChatMember, error)
+	SetMemberRole(memberID uint, role int) error
+	SetLastRead(memberID, messageID uint) error
+}
+
+type gormChatRepository struct {
+	db *gorm.DB
+}
+
+func NewChatRepository(db *gorm.DB) ChatRepository {
+	return &gormChatRepository{db: db}
+}
+
+func (r *gormChatRepository) FindByID(id uint) (model.Chat, error) {
+	var chat model.Chat
+	err := r.db.First(&chat, id).Error
+	return chat, translate(err)
+}
+
+func (r *gormChatRepository) FindByIDs(ids []uint) ([]model.Chat, error) {
+	var chats []model.Chat
+	if len(ids) == 0 {
+		return chats, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("updated_at DESC").Find(&chats).Error
+	return chats, err
+}
+
+func (r *gormChatRepository) FindDirect(userID, otherID uint) (model.Chat, error) {
+	var chat model.Chat
+	err := r.db.Where(
+		"(user_id = ? AND receiver_id = ?) OR (user_id = ? AND receiver_id = ?)",
+		userID, otherID, otherID, userID,
+	).First(&chat).Error
+	return chat, translate(err)
+}
+
+func (r *gormChatRepository) FindByVillage(villageID uint) (model.Chat, error) {
+	var chat model.Chat
+	err := r.db.Where("village_id = ?", villageID).First(&chat).Error
+	return chat, translate(err)
+}
+
+func (r *gormChatRepository) FindOrCreateVillageChat(chat *model.Chat) error {
+	return r.db.Where(model.Chat{VillageID: chat.VillageID}).Attrs(*chat).FirstOrCreate(chat).Error
+}
+
+func (r *gormChatRepository) Create(chat *model.Chat) error {
+	return r.db.Create(chat).Error
+}
+
+func (r *gormChatRepository) SetLastMessage(chatID uint, content string) error {
+	return r.db.Model(&model.Chat{}).Where("id = ?", chatID).Update("last_message", content).Error
+}
+
+func (r *gormChatRepository) CreateMessage(message *model.Message) error {
+	return r.db.Create(message).Error
+}
+
+func (r *gormChatRepository) ListMessages(chatID uint, p pagination.Params) ([]model.Message, pagination.Page, error) {
+	query := r.db.Model(&model.Message{}).Where("chat_id = ? AND status <> ?", chatID, model.ContentStatusRemoved)
+	total := p.Total(query)
+
+	var messages []model.Message
+	if err := p.Apply(query).Find(&messages).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	messages, page := pagination.Trim(p, messages, total, messageCursor)
+	return messages, page, nil
+}
+
+func messageCursor(m model.Message) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+}
+
+func (r *gormChatRepository) LastMessageID(chatID uint) (uint, error) {
+	var lastID uint
+	err := r.db.Model(&model.Message{}).Where("chat_id = ?", chatID).Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error
+	return lastID, err
+}
+
+func (r *gormChatRepository) CountUnread(chatID, userID, lastReadID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Message{}).
+		Where("chat_id = ? AND id > ? AND sender_id <> ?", chatID, lastReadID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormChatRepository) FindMember(chatID, userID uint) (model.ChatMember, error) {
+	var member model.ChatMember
+	err := r.db.Where("chat_id = ? AND user_id = ?", chatID, userID).First(&member).Error
+	return member, translate(err)
+}
+
+func (r *gormChatRepository) Memberships(userID uint) ([]model.ChatMember, error) {
+	var members []model.ChatMember
+	err := r.db.Where("user_id = ?", userID).Find(&members).Error
+	return members, err
+}
+
+func (r *gormChatRepository) ListMembers(chatID uint) ([]model.ChatMember, error) {
+	var members []model.ChatMember
+	err := r.db.Where("chat_id = ?", chatID).Preload("User").Order("created_at ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *gormChatRepository) CountMembers(chatID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ChatMember{}).Where("chat_id = ?", chatID).Count(&count).Error
+	return count, err
+}
+
+func (r *gormChatRepository) NonMembers(chatID uint, ids []uint) ([]uint, error) {
+	var userIDs []uint
+	if len(ids) == 0 {
+		return userIDs, nil
+	}
+	err := r.db.Model(&model.User{}).
+		Where("id IN ?", ids).
+		Where("id NOT IN (?)", r.db.Model(&model.ChatMember{}).Select("user_id").Where("chat_id = ?", chatID)).
+		Pluck("id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *gormChatRepository) CreateMember(member *model.ChatMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *gormChatRepository) DeleteMember(member model.ChatMember) error {
+	return r.db.Delete(&member).Error
+}
+
+func (r *gormChatRepository) EarliestMember(chatID uint) (model.ChatMember, error) {
+	var member model.ChatMember
+	err := r.db.Where("chat_id = ?", chatID).Order("created_at ASC").First(&member).Error
+	return member, translate(err)
+}
+
+func (r *gormChatRepository) SetMemberRole(memberID uint, role int) error {
+	return r.db.Model(&model.ChatMember{}).Where("id = ?", memberID).UpdateColumn("role", role).Error
+}
+
+func (r *gormChatRepository) SetLastRead(memberID, messageID uint) error {
+	return r.db.Model(&model.ChatMember{}).Where("id = ?", memberID).UpdateColumn("last_read_message_id", messageID).Error
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// CommentFilter 评论列表的筛选条件，零值表示不筛选
+type CommentFilter struct {
+	TargetID   uint
+	TargetType string
+}
+
+// CommentRepository 评论和帖子回复数据访问
+type CommentRepository interface {
+	// List 查询已发布的评论，包含作者和提及
+	List(filter CommentFilter, p pagination.Params) ([]model.Comment, pagination.Page, error)
+	// FindByID 查询任意状态的评论
+	FindByID(id uint) (model.Comment, error)
+	// FindWithAuthor 查询评论，包含作者和提及
+	FindWithAuthor(id uint) (model.Comment, error)
+	// FindReply 查询帖子下的回复
+	FindReply(postID, replyID uint) (model.Comment, error)
+	// TargetOwner 查询被评论内容的作者，不支持的内容类型返回ErrNotFound
+	TargetOwner(targetType string, targetID uint) (uint, error)
+	Create(comment *model.Comment) error
+	UpdateContent(id uint, content string) error
+	Delete(id uint) error
+
+	HasLiked(commentID, userID uint) (bool, error)
+	// AddLike 记录点赞并增加点赞数
+	AddLike(commentID, userID uint) error
+	// RemoveLike 删除点赞记录并减少点赞数
+	RemoveLike(commentID, userID uint) error
+}
+
+type gormCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &gormCommentRepository{db: db}
+}
+
+func (r *gormCommentRepository) List(filter CommentFilter, p pagination.Params) ([]model.Comment, pagination.Page, error) {
+	query := r.db.Model(&model.Comment{}).Where("status = ?", model.ContentStatusNormal)
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+
+	total := p.Total(query)
+	var comments []model.Comment
+	if err := p.Apply(query.Preload("Author").Preload("Mentions")).Find(&comments).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	comments, page := pagination.Trim(p, comments, total, commentCursor)
+	return comments, page, nil
+}
+
+func commentCursor(cm model.Comment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: cm.CreatedAt, ID: cm.ID}
+}
+
+func (r *gormCommentRepository) FindByID(id uint) (model.Comment, error) {
+	var comment model.Comment
+	err := r.db.First(&comment, id).Error
+	return comment, translate(err)
+}
+
+func (r *gormCommentRepository) FindWithAuthor(id uint) (model.Comment, error) {
+	var comment model.Comment
+	err := r.db.Preload("Author").Preload("Mentions").First(&comment, id).Error
+	return comment, translate(err)
+}
+
+func (r *gormCommentRepository) FindReply(postID, replyID uint) (model.Comment, error) {
+	var comment model.Comment
+	err := r.db.Where("target_id = ? AND target_type = ?", postID, model.TargetTypePost).First(&comment, replyID).Error
+	return comment, translate(err)
+}
+
+func (r *gormCommentRepository) TargetOwner(targetType string, targetID uint) (uint, error) {
+	var m interface{}
+	switch targetType {
+	case model.TargetTypeQuestion:
+		m = &model.Question{}
+	case model.TargetTypeAnswer:
+		m = &model.Answer{}
+	case model.TargetTypeNote:
+		m = &model.Note{}
+	case model.TargetTypePost:
+		m = &model.Post{}
+	case model.TargetTypeComment:
+		m = &model.Comment{}
+	default:
+		return 0, ErrNotFound
+	}
+
+	var row struct{ AuthorID uint }
+	err := r.db.Model(m).Select("author_id").Where("id = ?", targetID).Take(&row).Error
+	return row.AuthorID, translate(err)
+}
+
+func (r *gormCommentRepository) Create(comment *model.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *gormCommentRepository) UpdateContent(id uint, content string) error {
+	return r.db.Model(&model.Comment{}).Where("id = ?", id).Update("content", content).Error
+}
+
+func (r *gormCommentRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Comment{}, id).Error
+}
+
+func (r *gormCommentRepository) HasLiked(commentID, userID uint) (bool, error) {
+	return exists(r.db.Model(&model.CommentLike{}).Where("comment_id = ? AND user_id = ?", commentID, userID))
+}
+
+func (r *gormCommentRepository) AddLike(commentID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.CommentLike{CommentID: commentID, UserID: userID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Comment{}).Where("id = ?", commentID).
+			UpdateColumn("likes", gorm.Expr("likes + 1")).Error
+	})
+}
+
+func (r *gormCommentRepository) RemoveLike(commentID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("comment_id = ? AND user_id = ?", commentID, userID).Delete(&model.CommentLike{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&model.Comment{}).Where("id = ? AND likes > 0", commentID).
+			UpdateColumn("likes", gorm.Expr("likes - 1")).Error
+	})
+}
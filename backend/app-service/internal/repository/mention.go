@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// MentionRepository 内容中@提及的数据访问
+type MentionRepository interface {
+	// ListBySource 查询内容当前的提及
+	ListBySource(sourceType string, sourceID uint) ([]model.Mention, error)
+	// Replace 以mentions替换内容的全部提及
+	Replace(sourceType string, sourceID uint, mentions []model.Mention) error
+}
+
+type gormMentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) MentionRepository {
+	return &gormMentionRepository{db: db}
+}
+
+func (r *gormMentionRepository) ListBySource(sourceType string, sourceID uint) ([]model.Mention, error) {
+	var mentions []model.Mention
+	err := r.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Find(&mentions).Error
+	return mentions, err
+}
+
+func (r *gormMentionRepository) Replace(sourceType string, sourceID uint, mentions []model.Mention) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&model.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		return tx.Create(&mentions).Error
+	})
+}
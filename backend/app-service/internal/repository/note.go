@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// NoteRepository 笔记数据访问
+type NoteRepository interface {
+	// List 查询已发布的笔记，包含作者，category为空时不按分类筛选
+	List(category string, p pagination.Params) ([]model.Note, pagination.Page, error)
+	// FindPublished 查询已发布的笔记，包含作者和提及
+	FindPublished(id uint) (model.Note, error)
+	// Titles 返回ids中已发布笔记的标题
+	Titles(ids []uint) (map[uint]string, error)
+	// Categories 已发布笔记使用过的分类
+	Categories() ([]string, error)
+	Create(note *model.Note) error
+	// LinkBookmark 记录收藏转成的笔记，收藏已转换过时返回ErrNotFound
+	LinkBookmark(bookmarkID, noteID uint) error
+	HasLiked(noteID, userID uint) (bool, error)
+}
+
+type gormNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewNoteRepository(db *gorm.DB) NoteRepository {
+	return &gormNoteRepository{db: db}
+}
+
+func (r *gormNoteRepository) List(category string, p pagination.Params) ([]model.Note, pagination.Page, error) {
+	query := r.db.Model(&model.Note{}).Where("status = ?", model.ContentStatusNormal)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	total := p.Total(query)
+	var notes []model.Note
+	if err := p.Apply(query.Preload("Author")).Find(&notes).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	notes, page := pagination.Trim(p, notes, total, noteCursor)
+	return notes, page, nil
+}
+
+func noteCursor(n model.Note) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+func (r *gormNoteRepository) FindPublished(id uint) (model.Note, error) {
+	var note model.Note
+	err := r.db.Preload("Author").Preload("Mentions").
+		Where("status = ?", model.ContentStatusNormal).
+		First(&note, id).Error
+	return note, translate(err)
+}
+
+func (r *gormNoteRepository) Categories() ([]string, error) {
+	var categories []string
+	err := r.db.Model(&model.Note{}).
+		Where("status = ?", model.ContentStatusNormal).
+		Distinct().Pluck("category", &categories).Error
+	return categories, err
+}
+
+func (r *gormNoteRepository) Titles(ids []uint) (map[uint]string, error) {
+	titles := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return titles, nil
+	}
+	var notes []model.Note
+	if err := r.db.Select("id, title").Where("id IN ? AND status = ?", ids, model.ContentStatusNormal).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range notes {
+		titles[item.ID] = item.Title
+	}
+	return titles, nil
+}
+
+func (r *gormNoteRepository) Create(note *model.Note) error {
+	return r.db.Create(note).Error
+}
+
+func (r *gormNoteRepository) LinkBookmark(bookmarkID, noteID uint) error {
+	result := r.db.Model(&model.Bookmark{}).Where("id = ? AND note_id IS NULL", bookmarkID).Update("note_id", noteID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormNoteRepository) HasLiked(noteID, userID uint) (bool, error) {
+	return exists(r.db.Model(&model.NoteLike{}).Where("note_id = ? AND user_id = ?", noteID, userID))
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/notify"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 站内通知数据访问
+type NotificationRepository interface {
+	// Send 创建通知并实时推送给接收人，不通知操作人本人，点赞通知按内容合并
+	Send(n model.Notification) error
+}
+
+type gormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &gormNotificationRepository{db: db}
+}
+
+func (r *gormNotificationRepository) Send(n model.Notification) error {
+	return notify.Send(r.db, n)
+}
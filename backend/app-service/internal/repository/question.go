@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// QuestionFilter 问题列表的筛选条件
+type QuestionFilter struct {
+	Category string    // 按标签筛选，为空不筛选
+	Since    time.Time // 只返回该时间之后发布的问题，零值不限制
+	Order    string    // 排序，为空时按发布时间倒序并支持游标
+}
+
+// QuestionRepository 问题、回答和点赞数据访问
+type QuestionRepository interface {
+	// List 查询已发布的问题，包含作者
+	List(filter QuestionFilter, p pagination.Params) ([]model.Question, pagination.Page, error)
+	// FindByID 查询任意状态的问题
+	FindByID(id uint) (model.Question, error)
+	// FindPublished 查询已发布的问题，包含作者和提及
+	FindPublished(id uint) (model.Question, error)
+	// Titles 返回ids中已发布问题的标题
+	Titles(ids []uint) (map[uint]string, error)
+	Create(question *model.Question) error
+	IncrementViews(id uint) error
+	CreateAnswer(answer *model.Answer) error
+
+	// LikedIDs 返回ids中用户已点赞的问题ID
+	LikedIDs(userID uint, ids []uint) ([]uint, error)
+	HasLiked(questionID, userID uint) (bool, error)
+	// AddLike 记录点赞并增加点赞数
+	AddLike(questionID, userID uint) error
+	// RemoveLike 删除点赞记录并减少点赞数
+	RemoveLike(questionID, userID uint) error
+}
+
+type gormQuestionRepository struct {
+	db *gorm.DB
+}
+
+func NewQuestionRepository(db *gorm.DB) QuestionRepository {
+	return &gormQuestionRepository{db: db}
+}
+
+func (r *gormQuestionRepository) List(filter QuestionFilter, p pagination.Params) ([]model.Question, pagination.Page, error) {
+	query := r.db.Model(&model.Question{}).Where("status = ?", model.ContentStatusNormal)
+	if filter.Category != "" {
+		query = query.Where("tags LIKE ?", "%"+filter.Category+"%")
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+
+	total := p.Total(query)
+	listQuery := query.Preload("Author")
+	cursor := questionCursor
+	if filter.Order != "" {
+		listQuery = p.ApplyOrder(listQuery, filter.Order)
+		cursor = nil
+	} else {
+		listQuery = p.Apply(listQuery)
+	}
+
+	var questions []model.Question
+	if err := listQuery.Find(&questions).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	questions, page := pagination.Trim(p, questions, total, cursor)
+	return questions, page, nil
+}
+
+func questionCursor(q model.Question) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: q.CreatedAt, ID: q.ID}
+}
+
+func (r *gormQuestionRepository) FindByID(id uint) (model.Question, error) {
+	var question model.Question
+	err := r.db.First(&question, id).Error
+	return question, translate(err)
+}
+
+func (r *gormQuestionRepository) FindPublished(id uint) (model.Question, error) {
+	var question model.Question
+	err := r.db.Preload("Author").Preload("Mentions").
+		Where("status = ?", model.ContentStatusNormal).
+		First(&question, id).Error
+	return question, translate(err)
+}
+
+func (r *gormQuestionRepository) Titles(ids []uint) (map[uint]string, error) {
+	titles := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return titles, nil
+	}
+	var questions []model.Question
+	if err := r.db.Select("id, title").Where("id IN ? AND status = ?", ids, model.ContentStatusNormal).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range questions {
+		titles[item.ID] = item.Title
+	}
+	return titles, nil
+}
+
+func (r *gormQuestionRepository) Create(question *model.Question) error {
+	return r.db.Create(question).Error
+}
+
+func (r *gormQuestionRepository) IncrementViews(id uint) error {
+	return r.db.Model(&model.Question{}).Where("id = ?", id).
+		UpdateColumn("views", gorm.Expr("views + 1")).Error
+}
+
+func (r *gormQuestionRepository) CreateAnswer(answer *model.Answer) error {
+	return r.db.Create(answer).Error
+}
+
+func (r *gormQuestionRepository) LikedIDs(userID uint, ids []uint) ([]uint, error) {
+	var liked []uint
+	if len(ids) == 0 {
+		return liked, nil
+	}
+	err := r.db.Model(&model.QuestionLike{}).
+		Where("user_id = ? AND question_id IN ?", userID, ids).
+		Pluck("question_id", &liked).Error
+	return liked, err
+}
+
+func (r *gormQuestionRepository) HasLiked(questionID, userID uint) (bool, error) {
+	return exists(r.db.Model(&model.QuestionLike{}).Where("question_id = ? AND user_id = ?", questionID, userID))
+}
+
+func (r *gormQuestionRepository) AddLike(questionID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.QuestionLike{QuestionID: questionID, UserID: userID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Question{}).Where("id = ?", questionID).
+			UpdateColumn("likes", gorm.Expr("likes + 1")).Error
+	})
+}
+
+func (r *gormQuestionRepository) RemoveLike(questionID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("question_id = ? AND user_id = ?", questionID, userID).Delete(&model.QuestionLike{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&model.Question{}).Where("id = ? AND likes > 0", questionID).
+			UpdateColumn("likes", gorm.Expr("likes - 1")).Error
+	})
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/ranking"
+
+	"gorm.io/gorm"
+)
+
+// HotScoreRepository 问题和帖子的互动分与热度
+type HotScoreRepository interface {
+	// Bump 按互动权重更新互动分和热度，weight为负表示取消互动，其他内容类型忽略
+	Bump(targetType string, id uint, weight float64) error
+}
+
+type gormHotScoreRepository struct {
+	db *gorm.DB
+}
+
+func NewHotScoreRepository(db *gorm.DB) HotScoreRepository {
+	return &gormHotScoreRepository{db: db}
+}
+
+func (r *gormHotScoreRepository) Bump(targetType string, id uint, weight float64) error {
+	return ranking.Bump(r.db, targetType, id, weight)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ReportRepository 举报和审核队列数据访问
+type ReportRepository interface {
+	Create(report *model.Report) error
+	// HasOpenFilterReport 内容是否被内容过滤拦下且尚未处理，这类内容只能由平台管理员放行
+	HasOpenFilterReport(targetType string, targetID uint) (bool, error)
+}
+
+type gormReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &gormReportRepository{db: db}
+}
+
+func (r *gormReportRepository) Create(report *model.Report) error {
+	return r.db.Create(report).Error
+}
+
+func (r *gormReportRepository) HasOpenFilterReport(targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := openFilterReports(r.db, targetType).Where("target_id = ?", targetID).Count(&count).Error
+	return count > 0, err
+}
+
+// openFilterReports 内容过滤提交且尚未处理的审核记录
+func openFilterReports(db *gorm.DB, targetType string) *gorm.DB {
+	return db.Model(&model.Report{}).
+		Where("target_type = ? AND reason = ? AND status = ?", targetType, model.ReportReasonFilter, model.ReportStatusOpen)
+}
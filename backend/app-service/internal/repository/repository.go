@@ -0,0 +1,75 @@
+// Package repository 封装用户、问答、笔记、评论、聊天、村落以及提及、通知等的数据访问。
+// 接口与具体数据库无关，GORM实现可以运行在MySQL或SQLite上，服务层只依赖接口
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// Repositories 汇总各模块的数据访问接口
+type Repositories struct {
+	Users     UserRepository
+	Questions QuestionRepository
+	Notes     NoteRepository
+	Comments  CommentRepository
+	Chats     ChatRepository
+	Villages  VillageRepository
+
+	Mentions      MentionRepository
+	Notifications NotificationRepository
+	Reports       ReportRepository
+	HotScores     HotScoreRepository
+
+	db *gorm.DB
+}
+
+// New 创建基于GORM的数据访问实现
+func New(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:     NewUserRepository(db),
+		Questions: NewQuestionRepository(db),
+		Notes:     NewNoteRepository(db),
+		Comments:  NewCommentRepository(db),
+		Chats:     NewChatRepository(db),
+		Villages:  NewVillageRepository(db),
+
+		Mentions:      NewMentionRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Reports:       NewReportRepository(db),
+		HotScores:     NewHotScoreRepository(db),
+		db:            db,
+	}
+}
+
+// Transaction 在同一个事务中执行fn，fn中应使用传入的tx访问数据。
+// 手工组装（未通过New创建）的Repositories没有数据库连接，直接执行fn
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	if r.db == nil {
+		return fn(r)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
+
+// translate 将GORM的记录不存在错误转换为ErrNotFound
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// exists 判断查询是否有匹配的记录
+func exists(query *gorm.DB) (bool, error) {
+	var count int64
+	if err := query.Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户数据访问
+type UserRepository interface {
+	FindByID(id uint) (model.User, error)
+	FindByUsername(username string) (model.User, error)
+	// FindUnscoped 查询用户，包含已注销的用户
+	FindUnscoped(id uint) (model.User, error)
+	// FindByUsernames 按用户名查询用户的ID和用户名，不存在的用户名忽略
+	FindByUsernames(usernames []string) ([]model.User, error)
+	UsernameExists(username string) (bool, error)
+	Create(user *model.User) error
+	Update(user *model.User, updates map[string]interface{}) error
+	// ExistingIDs 返回ids中存在的用户ID，排除exclude
+	ExistingIDs(ids []uint, exclude uint) ([]uint, error)
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) FindByID(id uint) (model.User, error) {
+	var user model.User
+	err := r.db.First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r *gormUserRepository) FindByUsername(username string) (model.User, error) {
+	var user model.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	return user, translate(err)
+}
+
+func (r *gormUserRepository) FindUnscoped(id uint) (model.User, error) {
+	var user model.User
+	err := r.db.Unscoped().First(&user, id).Error
+	return user, translate(err)
+}
+
+func (r *gormUserRepository) FindByUsernames(usernames []string) ([]model.User, error) {
+	var users []model.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	err := r.db.Select("id, username").Where("username IN ?", usernames).Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) UsernameExists(username string) (bool, error) {
+	return exists(r.db.Model(&model.User{}).Where("username = ?", username))
+}
+
+func (r *gormUserRepository) Create(user *model.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *gormUserRepository) Update(user *model.User, updates map[string]interface{}) error {
+	return r.db.Model(user).Updates(updates).Error
+}
+
+func (r *gormUserRepository) ExistingIDs(ids []uint, exclude uint) ([]uint, error) {
+	var existing []uint
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&model.User{}).Where("id IN ? AND id <> ?", ids, exclude).Pluck("id", &existing).Error
+	return existing, err
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"time"
+
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// PostFilter 帖子列表的筛选条件
+type PostFilter struct {
+	Since time.Time // 只返回该时间之后发布的帖子，零值不限制
+	Order string    // 排序，为空时按发布时间倒序，置顶帖子在第一页最前面
+}
+
+// VillageRepository 村落、成员、帖子和匿名化名数据访问
+type VillageRepository interface {
+	// List 分页查询开放中的村落
+	List(page, pageSize int) ([]model.Village, int64, error)
+	// FindByID 查询任意状态的村落
+	FindByID(id uint) (model.Village, error)
+	// FindOpen 查询开放中的村落
+	FindOpen(id uint) (model.Village, error)
+	Update(id uint, updates map[string]interface{}) error
+	FindMember(villageID, userID uint) (model.VillageMember, error)
+	// AddMember 添加成员并增加成员数
+	AddMember(member *model.VillageMember) error
+	// RemoveMember 删除成员并减少成员数
+	RemoveMember(member model.VillageMember) error
+	AddPostCount(villageID uint, delta int) error
+
+	// ListPosts 查询开放中村落里已发布的帖子，包含作者和提及
+	ListPosts(villageID uint, filter PostFilter, p pagination.Params) ([]model.Post, pagination.Page, error)
+	// FindPost 查询任意状态的帖子
+	FindPost(id uint) (model.Post, error)
+	// FindPublishedPost 查询开放中村落里已发布的帖子
+	FindPublishedPost(id uint) (model.Post, error)
+	// FindVillagePost 查询村落中任意状态的帖子
+	FindVillagePost(villageID, postID uint) (model.Post, error)
+	// ListPendingPosts 按提交时间先后查询村落中待审核的帖子，包含作者，不含被内容过滤拦下的帖子
+	ListPendingPosts(villageID uint, page, pageSize int) ([]model.Post, int64, error)
+	CreatePost(post *model.Post) error
+	UpdatePost(id uint, updates map[string]interface{}) error
+	DeletePost(id uint) error
+	AddReplyCount(postID uint, delta int) error
+
+	HasLikedPost(postID, userID uint) (bool, error)
+	// AddPostLike 记录点赞并增加点赞数
+	AddPostLike(postID, userID uint) error
+	// RemovePostLike 删除点赞记录并减少点赞数
+	RemovePostLike(postID, userID uint) error
+
+	// FindAlias 查询用户在帖子内的化名
+	FindAlias(postID, userID uint) (string, error)
+	// AliasNames 帖子内已使用的化名
+	AliasNames(postID uint) ([]string, error)
+	CreateAlias(alias *model.AnonymousAlias) error
+
+	// FindAnonymousPost 查询村落中的匿名帖子，包含已删除的帖子
+	FindAnonymousPost(villageID, postID uint) (model.Post, error)
+	// FindAnonymousReply 查询村落帖子下的匿名回复，包含已删除的帖子和回复
+	FindAnonymousReply(villageID, replyID uint) (model.Comment, error)
+	CreateAuditLog(log *model.AnonymousAuditLog) error
+}
+
+type gormVillageRepository struct {
+	db *gorm.DB
+}
+
+func NewVillageRepository(db *gorm.DB) VillageRepository {
+	return &gormVillageRepository{db: db}
+}
+
+func (r *gormVillageRepository) List(page, pageSize int) ([]model.Village, int64, error) {
+	query := r.db.Model(&model.Village{}).Where("status = ?", 1)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var villages []model.Village
+	err := query.Order("created_at DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&villages).Error
+	return villages, total, err
+}
+
+func (r *gormVillageRepository) FindByID(id uint) (model.Village, error) {
+	var village model.Village
+	err := r.db.First(&village, id).Error
+	return village, translate(err)
+}
+
+func (r *gormVillageRepository) FindOpen(id uint) (model.Village, error) {
+	var village model.Village
+	err := r.db.Where("status = ?", 1).First(&village, id).Error
+	return village, translate(err)
+}
+
+func (r *gormVillageRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&model.Village{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *gormVillageRepository) FindMember(villageID, userID uint) (model.VillageMember, error) {
+	var member model.VillageMember
+	err := r.db.Where("village_id = ? AND user_id = ?", villageID, userID).First(&member).Error
+	return member, translate(err)
+}
+
+func (r *gormVillageRepository) AddMember(member *model.VillageMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Village{}).Where("id = ?", member.VillageID).
+			UpdateColumn("member_count", gorm.Expr("member_count + 1")).Error
+	})
+}
+
+func (r *gormVillageRepository) RemoveMember(member model.VillageMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Village{}).Where("id = ? AND member_count > 0", member.VillageID).
+			UpdateColumn("member_count", gorm.Expr("member_count - 1")).Error
+	})
+}
+
+func (r *gormVillageRepository) AddPostCount(villageID uint, delta int) error {
+	return r.db.Model(&model.Village{}).Where("id = ? AND post_count + ? >= 0", villageID, delta).
+		UpdateColumn("post_count", gorm.Expr("post_count + ?", delta)).Error
+}
+
+func (r *gormVillageRepository) ListPosts(villageID uint, filter PostFilter, p pagination.Params) ([]model.Post, pagination.Page, error) {
+	query := r.db.Model(&model.Post{}).Where("village_id = ? AND status = ?", villageID, model.PostStatusNormal).
+		Where("village_id IN (?)", r.openVillageIDs())
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	total := p.Total(query)
+
+	listQuery := query.Session(&gorm.Session{}).Preload("Author").Preload("Mentions")
+	if filter.Order == "" {
+		return latestPosts(listQuery, p, total)
+	}
+
+	// 按热度排序时置顶帖子与其他帖子一起排序
+	var posts []model.Post
+	if err := p.ApplyOrder(listQuery, filter.Order).Find(&posts).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	posts, page := pagination.Trim(p, posts, total, nil)
+	return posts, page, nil
+}
+
+// latestPosts 按发布时间倒序查询帖子。置顶帖子在第一页最前面展示，不计入每页数量，
+// 游标只在非置顶帖子中翻页
+func latestPosts(query *gorm.DB, p pagination.Params, total *int64) ([]model.Post, pagination.Page, error) {
+	var posts []model.Post
+	if p.Cursor == nil && p.Page <= 1 {
+		if err := query.Session(&gorm.Session{}).
+			Where("is_pinned = ?", true).
+			Order("pinned_at DESC").
+			Find(&posts).Error; err != nil {
+			return nil, pagination.Page{}, err
+		}
+	}
+
+	var latest []model.Post
+	if err := p.Apply(query.Session(&gorm.Session{}).Where("is_pinned = ?", false)).Find(&latest).Error; err != nil {
+		return nil, pagination.Page{}, err
+	}
+	latest, page := pagination.Trim(p, latest, total, postCursor)
+	return append(posts, latest...), page, nil
+}
+
+func postCursor(p model.Post) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+func (r *gormVillageRepository) FindPost(id uint) (model.Post, error) {
+	var post model.Post
+	err := r.db.First(&post, id).Error
+	return post, translate(err)
+}
+
+func (r *gormVillageRepository) FindPublishedPost(id uint) (model.Post, error) {
+	var post model.Post
+	err := r.db.Where("status = ? AND village_id IN (?)", model.PostStatusNormal, r.openVillageIDs()).First(&post, id).Error
+	return post, translate(err)
+}
+
+func (r *gormVillageRepository) FindVillagePost(villageID, postID uint) (model.Post, error) {
+	var post model.Post
+	err := r.db.Where("village_id = ?", villageID).First(&post, postID).Error
+	return post, translate(err)
+}
+
+func (r *gormVillageRepository) ListPendingPosts(villageID uint, page, pageSize int) ([]model.Post, int64, error) {
+	// 被内容过滤拦下的帖子由平台管理员审核，不进入村落审核队列
+	query := r.db.Model(&model.Post{}).Where("village_id = ? AND status = ?", villageID, model.PostStatusPending).
+		Where("id NOT IN (?)", openFilterReports(r.db, model.TargetTypePost).Select("target_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var posts []model.Post
+	err := query.Preload("Author").Order("created_at ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&posts).Error
+	return posts, total, err
+}
+
+// openVillageIDs 开放中村落ID的子查询，已关闭村落的帖子不再对外展示
+func (r *gormVillageRepository) openVillageIDs() *gorm.DB {
+	return r.db.Model(&model.Village{}).Select("id").Where("status = ?", 1)
+}
+
+func (r *gormVillageRepository) CreatePost(post *model.Post) error {
+	return r.db.Create(post).Error
+}
+
+func (r *gormVillageRepository) UpdatePost(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&model.Post{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *gormVillageRepository) DeletePost(id uint) error {
+	return r.db.Delete(&model.Post{}, id).Error
+}
+
+func (r *gormVillageRepository) AddReplyCount(postID uint, delta int) error {
+	return r.db.Model(&model.Post{}).Where("id = ? AND comments + ? >= 0", postID, delta).
+		UpdateColumn("comments", gorm.Expr("comments + ?", delta)).Error
+}
+
+func (r *gormVillageRepository) HasLikedPost(postID, userID uint) (bool, error) {
+	return exists(r.db.Model(&model.PostLike{}).Where("post_id = ? AND user_id = ?", postID, userID))
+}
+
+func (r *gormVillageRepository) AddPostLike(postID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.PostLike{PostID: postID, UserID: userID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Post{}).Where("id = ?", postID).
+			UpdateColumn("likes", gorm.Expr("likes + 1")).Error
+	})
+}
+
+func (r *gormVillageRepository) RemovePostLike(postID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&model.PostLike{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&model.Post{}).Where("id = ? AND likes > 0", postID).
+			UpdateColumn("likes", gorm.Expr("likes - 1")).Error
+	})
+}
+
+func (r *gormVillageRepository) FindAlias(postID, userID uint) (string, error) {
+	var alias model.AnonymousAlias
+	err := r.db.Where("post_id = ? AND user_id = ?", postID, userID).First(&alias).Error
+	return alias.Name, translate(err)
+}
+
+func (r *gormVillageRepository) AliasNames(postID uint) ([]string, error) {
+	var names []string
+	err := r.db.Model(&model.AnonymousAlias{}).Where("post_id = ?", postID).Pluck("name", &names).Error
+	return names, err
+}
+
+func (r *gormVillageRepository) CreateAlias(alias *model.AnonymousAlias) error {
+	return r.db.Create(alias).Error
+}
+
+func (r *gormVillageRepository) FindAnonymousPost(villageID, postID uint) (model.Post, error) {
+	var post model.Post
+	err := r.db.Unscoped().Where("village_id = ? AND is_anonymous = ?", villageID, true).First(&post, postID).Error
+	return post, translate(err)
+}
+
+func (r *gormVillageRepository) FindAnonymousReply(villageID, replyID uint) (model.Comment, error) {
+	var reply model.Comment
+	err := r.db.Unscoped().
+		Where("target_type = ? AND is_anonymous = ?", model.TargetTypePost, true).
+		Where("target_id IN (?)", r.db.Unscoped().Model(&model.Post{}).Select("id").Where("village_id = ?", villageID)).
+		First(&reply, replyID).Error
+	return reply, translate(err)
+}
+
+func (r *gormVillageRepository) CreateAuditLog(log *model.AnonymousAuditLog) error {
+	return r.db.Create(log).Error
+}
@@ -0,0 +1,122 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"ai-egg/app-service/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminRequiresPermission(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	app.expect(http.StatusUnauthorized, apperr.CodeUnauthorized, http.MethodGet, path("/admin/users"), "", nil)
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/admin/users"), alice.Token, nil)
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/admin/stats"), alice.Token, nil)
+}
+
+func TestAdminUsers(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+	alice := app.register("alice")
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/admin/users"), root.Token, nil)); n != 2 {
+		t.Fatalf("got %d users, want 2", n)
+	}
+	app.ok(http.MethodGet, path("/admin/users/%d", alice.ID), root.Token, nil)
+
+	// 版主可以查看用户，但不能访问统计
+	app.ok(http.MethodPut, path("/admin/users/%d/role", alice.ID), root.Token, gin.H{"role": "moderator"})
+	app.ok(http.MethodGet, path("/admin/users"), alice.Token, nil)
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/admin/stats"), alice.Token, nil)
+	app.ok(http.MethodPut, path("/admin/users/%d/role", alice.ID), root.Token, gin.H{"role": "user"})
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPut, path("/admin/users/%d/role", alice.ID), root.Token, gin.H{"role": "owner"})
+
+	// 禁用后现有令牌失效且无法登录，启用后恢复
+	app.ok(http.MethodPost, path("/admin/users/%d/disable", alice.ID), root.Token, gin.H{"reason": "发布广告"})
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), alice.Token, nil)
+	app.expect(http.StatusForbidden, apperr.CodeAccountDisabled, http.MethodPost, path("/login"), "", gin.H{
+		"username": "alice",
+		"password": testPassword,
+	})
+	app.ok(http.MethodPost, path("/admin/users/%d/enable", alice.ID), root.Token, gin.H{"reason": "申诉通过"})
+	app.login("alice", testPassword)
+
+	var logs []struct {
+		Reason string `json:"reason"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/users/%d/status-logs", alice.ID), root.Token, nil), &logs)
+	if len(logs) != 2 {
+		t.Fatalf("got %d status logs, want 2", len(logs))
+	}
+
+	var stats struct {
+		Totals struct {
+			Users int64 `json:"users"`
+		} `json:"totals"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/stats"), root.Token, nil), &stats)
+	if stats.Totals.Users != 2 {
+		t.Fatalf("stats users = %d, want 2", stats.Totals.Users)
+	}
+	if n := listLen(t, app.ok(http.MethodGet, path("/admin/logs"), root.Token, nil)); n == 0 {
+		t.Fatalf("admin actions were not logged")
+	}
+}
+
+func TestAdminContentAndReports(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "广告", "content": "买买买"}))
+	app.ok(http.MethodPost, path("/report"), bob.Token, gin.H{"targetType": "question", "targetId": questionID, "reason": "spam"})
+
+	var reports struct {
+		List []struct {
+			ID uint `json:"id"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/reports"), root.Token, nil), &reports)
+	if len(reports.List) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports.List))
+	}
+	reportID := reports.List[0].ID
+	app.ok(http.MethodGet, path("/admin/reports/%d", reportID), root.Token, nil)
+	app.ok(http.MethodPost, path("/admin/reports/%d/resolve", reportID), root.Token, gin.H{"action": "hide"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/question/%d", questionID), "", nil)
+
+	// 隐藏的问题不能点赞和回答
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/question/%d/like", questionID), bob.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/answer"), bob.Token, gin.H{"questionId": questionID, "content": "回答"})
+
+	app.ok(http.MethodPost, path("/admin/content/question/%d/restore", questionID), root.Token, gin.H{"reason": "误判"})
+	app.ok(http.MethodGet, path("/question/%d", questionID), "", nil)
+	app.ok(http.MethodDelete, path("/admin/content/question/%d", questionID), root.Token, gin.H{"reason": "违规"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/question/%d", questionID), "", nil)
+	app.expect(http.StatusBadRequest, "", http.MethodDelete, path("/admin/content/unknown/%d", questionID), root.Token, gin.H{"reason": "违规"})
+}
+
+func TestAdminVillages(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+
+	villageID := idOf(t, app.ok(http.MethodPost, path("/admin/villages"), root.Token, gin.H{"name": "新村", "postPolicy": "members"}))
+	app.ok(http.MethodPut, path("/admin/villages/%d", villageID), root.Token, gin.H{"description": "欢迎入住"})
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-villages"), "", nil)); n != 1 {
+		t.Fatalf("got %d public villages, want 1", n)
+	}
+
+	// 关闭的村落不再对外展示，后台仍可查看
+	app.ok(http.MethodDelete, path("/admin/villages/%d", villageID), root.Token, gin.H{"reason": "长期无人维护"})
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-villages"), "", nil)); n != 0 {
+		t.Fatalf("got %d public villages after close, want 0", n)
+	}
+	if n := listLen(t, app.ok(http.MethodGet, path("/admin/villages"), root.Token, nil)); n != 1 {
+		t.Fatalf("got %d admin villages, want 1", n)
+	}
+}
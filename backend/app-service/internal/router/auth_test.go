@@ -0,0 +1,317 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/oauth"
+	"ai-egg/app-service/internal/oauth/oauthtest"
+	"ai-egg/app-service/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	app := newTestApp(t)
+
+	alice := app.register("alice")
+	if alice.ID == 0 || alice.Token == "" {
+		t.Fatalf("register returned %+v", alice)
+	}
+
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/register"), "", gin.H{
+		"username": "alice",
+		"password": testPassword,
+		"email":    "other@example.com",
+	})
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPost, path("/register"), "", gin.H{
+		"username": "al",
+		"password": testPassword,
+	})
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidCredentials, http.MethodPost, path("/login"), "", gin.H{
+		"username": "alice",
+		"password": "wrong-password",
+	})
+
+	token := app.login("alice", testPassword)
+
+	var check struct {
+		IsLoggedIn bool   `json:"isLoggedIn"`
+		UserID     uint   `json:"userId"`
+		Username   string `json:"username"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/check-login"), token, nil), &check)
+	if !check.IsLoggedIn || check.UserID != alice.ID {
+		t.Fatalf("check-login returned %+v", check)
+	}
+	decode(t, app.ok(http.MethodGet, path("/check-login"), "", nil), &check)
+	if check.IsLoggedIn {
+		t.Fatalf("guest check-login returned logged in")
+	}
+
+	app.expect(http.StatusUnauthorized, apperr.CodeUnauthorized, http.MethodGet, path("/user"), "", nil)
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), "invalid-token", nil)
+}
+
+func TestUserProfile(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	app.ok(http.MethodPut, path("/user"), alice.Token, gin.H{"bio": "hello", "email": "alice2@example.com"})
+
+	var user struct {
+		Username      string `json:"username"`
+		Email         string `json:"email"`
+		Bio           string `json:"bio"`
+		EmailVerified bool   `json:"emailVerified"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/user"), alice.Token, nil), &user)
+	if user.Bio != "hello" || user.Email != "alice2@example.com" {
+		t.Fatalf("profile not updated: %+v", user)
+	}
+
+	// 更换邮箱后旧邮箱的验证链接失效，重新发送验证邮件并完成验证
+	app.expect(http.StatusBadRequest, apperr.CodeLinkExpired, http.MethodPost, path("/email/verify"), "", gin.H{"token": app.mailToken(alice.Email)})
+	app.ok(http.MethodPost, path("/email/verify/send"), alice.Token, nil)
+	app.ok(http.MethodPost, path("/email/verify"), "", gin.H{"token": app.mailToken("alice2@example.com")})
+
+	decode(t, app.ok(http.MethodGet, path("/user"), alice.Token, nil), &user)
+	if !user.EmailVerified {
+		t.Fatalf("email not verified")
+	}
+}
+
+func TestTokenRefreshAndSessions(t *testing.T) {
+	app := newTestApp(t)
+	app.register("alice")
+
+	var pair struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/login"), "", gin.H{"username": "alice", "password": testPassword}), &pair)
+
+	var refreshed struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/token/refresh"), "", gin.H{"refreshToken": pair.RefreshToken}), &refreshed)
+	if refreshed.Token == "" || refreshed.RefreshToken == pair.RefreshToken {
+		t.Fatalf("refresh did not rotate tokens: %+v", refreshed)
+	}
+
+	var sessions []struct {
+		ID        uint `json:"id"`
+		IsCurrent bool `json:"is_current"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/sessions"), refreshed.Token, nil), &sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+
+	// 退出后该会话刷新前签发的访问令牌同样失效
+	app.ok(http.MethodPost, path("/logout"), refreshed.Token, nil)
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), refreshed.Token, nil)
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), pair.Token, nil)
+
+	// 已作废的刷新令牌被再次使用时下线整个会话
+	decode(t, app.ok(http.MethodPost, path("/login"), "", gin.H{"username": "alice", "password": testPassword}), &pair)
+	app.ok(http.MethodPost, path("/token/refresh"), "", gin.H{"refreshToken": pair.RefreshToken})
+	app.expect(http.StatusUnauthorized, "", http.MethodPost, path("/token/refresh"), "", gin.H{"refreshToken": pair.RefreshToken})
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), pair.Token, nil)
+
+	// 下线其他设备
+	first := app.login("alice", testPassword)
+	other := app.login("alice", testPassword)
+	decode(t, app.ok(http.MethodGet, path("/sessions"), other, nil), &sessions)
+	for _, s := range sessions {
+		if !s.IsCurrent {
+			app.ok(http.MethodDelete, path("/sessions/%d", s.ID), other, nil)
+		}
+	}
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), first, nil)
+
+	app.ok(http.MethodPost, path("/logout/all"), other, nil)
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/sessions"), other, nil)
+}
+
+func TestRefreshAfterDisable(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+	alice := app.register("alice")
+
+	var pair struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/login"), "", gin.H{"username": "alice", "password": testPassword}), &pair)
+	var before int64
+	app.db.Model(&model.RefreshToken{}).Where("user_id = ?", alice.ID).Count(&before)
+
+	// 禁用后刷新被拒绝，且不会签发新的刷新令牌
+	app.ok(http.MethodPost, path("/admin/users/%d/disable", alice.ID), root.Token, gin.H{"reason": "发布广告"})
+	app.expect(http.StatusForbidden, apperr.CodeAccountDisabled, http.MethodPost, path("/token/refresh"), "", gin.H{"refreshToken": pair.RefreshToken})
+
+	var after, active int64
+	app.db.Model(&model.RefreshToken{}).Where("user_id = ?", alice.ID).Count(&after)
+	app.db.Model(&model.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", alice.ID).Count(&active)
+	if after != before || active != 0 {
+		t.Fatalf("refresh tokens after disabled refresh: %d total (was %d), %d active", after, before, active)
+	}
+
+	// 账号状态在其他地方被修改、会话未被下线时同样不续期
+	bob := app.register("bob")
+	decode(t, app.ok(http.MethodPost, path("/login"), "", gin.H{"username": "bob", "password": testPassword}), &pair)
+	if err := app.db.Model(&model.User{}).Where("id = ?", bob.ID).Update("status", model.UserStatusDisabled).Error; err != nil {
+		t.Fatalf("disable bob: %v", err)
+	}
+	account.Invalidate(bob.ID)
+	app.db.Model(&model.RefreshToken{}).Where("user_id = ?", bob.ID).Count(&before)
+	app.expect(http.StatusForbidden, apperr.CodeAccountDisabled, http.MethodPost, path("/token/refresh"), "", gin.H{"refreshToken": pair.RefreshToken})
+	app.db.Model(&model.RefreshToken{}).Where("user_id = ?", bob.ID).Count(&after)
+	if after != before {
+		t.Fatalf("disabled refresh issued %d new refresh tokens", after-before)
+	}
+}
+
+func TestPasswordResetAndChange(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	// 只有已验证的邮箱会收到重置邮件
+	app.ok(http.MethodPost, path("/email/verify"), "", gin.H{"token": app.mailToken(alice.Email)})
+	app.ok(http.MethodPost, path("/password/forgot"), "", gin.H{"email": alice.Email})
+	reset := app.mailToken(alice.Email)
+	app.ok(http.MethodPost, path("/password/reset"), "", gin.H{"token": reset, "password": "newsecret"})
+	app.expect(http.StatusBadRequest, apperr.CodeLinkExpired, http.MethodPost, path("/password/reset"), "", gin.H{"token": reset, "password": "othersecret"})
+
+	app.expect(http.StatusUnauthorized, "", http.MethodGet, path("/user"), alice.Token, nil)
+	token := app.login("alice", "newsecret")
+
+	app.ok(http.MethodPut, path("/password"), token, gin.H{"oldPassword": "newsecret", "newPassword": testPassword})
+	app.login("alice", testPassword)
+}
+
+func TestOAuthLoginAndLink(t *testing.T) {
+	app := newTestApp(t)
+
+	server := oauthtest.NewServer()
+	defer server.Close()
+	provider, err := oauth.NewProvider(context.Background(), server.ProviderConfig("mock", "http://localhost:5173/oauth/callback"))
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	oauth.Init([]*oauth.Provider{provider})
+
+	var providers []string
+	decode(t, app.ok(http.MethodGet, path("/oauth/providers"), "", nil), &providers)
+	if len(providers) != 1 || providers[0] != "mock" {
+		t.Fatalf("providers = %v", providers)
+	}
+
+	authorize := func(token, query string) gin.H {
+		var auth struct {
+			URL string `json:"url"`
+		}
+		decode(t, app.ok(http.MethodGet, path("/oauth/mock/authorize%s", query), token, nil), &auth)
+		code, state, err := server.Authorize(auth.URL)
+		if err != nil {
+			t.Fatalf("authorize: %v", err)
+		}
+		return gin.H{"code": code, "state": state}
+	}
+
+	// 首次第三方登录自动创建账号
+	var login struct {
+		Token  string `json:"token"`
+		UserID uint   `json:"userId"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/oauth/mock/callback"), "", authorize("", "")), &login)
+	if login.Token == "" {
+		t.Fatalf("oauth login returned no token")
+	}
+
+	// 已登录用户绑定另一个第三方账号
+	alice := app.register("alice")
+	server.SetUser(oauthtest.User{Subject: "2002", Email: "alice@example.com", EmailVerified: true, Username: "alice_oidc"})
+	app.ok(http.MethodPost, path("/oauth/mock/callback"), "", authorize(alice.Token, "?link=true"))
+
+	var identities []json.RawMessage
+	decode(t, app.ok(http.MethodGet, path("/oauth/identities"), alice.Token, nil), &identities)
+	if len(identities) != 1 {
+		t.Fatalf("got %d identities, want 1", len(identities))
+	}
+	app.ok(http.MethodDelete, path("/oauth/identities/mock"), alice.Token, nil)
+
+	// 授权state只能使用一次
+	params := authorize("", "")
+	app.ok(http.MethodPost, path("/oauth/mock/callback"), "", params)
+	app.expect(http.StatusBadRequest, apperr.CodeLinkExpired, http.MethodPost, path("/oauth/mock/callback"), "", params)
+}
+
+func TestOAuthUsersWithoutEmail(t *testing.T) {
+	app := newTestApp(t)
+
+	server := oauthtest.NewServer()
+	defer server.Close()
+	provider, err := oauth.NewProvider(context.Background(), server.ProviderConfig("mock", "http://localhost:5173/oauth/callback"))
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	oauth.Init([]*oauth.Provider{provider})
+
+	login := func(user oauthtest.User) uint {
+		server.SetUser(user)
+		var auth struct {
+			URL string `json:"url"`
+		}
+		decode(t, app.ok(http.MethodGet, path("/oauth/mock/authorize"), "", nil), &auth)
+		code, state, err := server.Authorize(auth.URL)
+		if err != nil {
+			t.Fatalf("authorize: %v", err)
+		}
+		var result struct {
+			UserID uint `json:"userId"`
+		}
+		decode(t, app.ok(http.MethodPost, path("/oauth/mock/callback"), "", gin.H{"code": code, "state": state}), &result)
+		return result.UserID
+	}
+
+	// 第三方账号未提供邮箱时新用户不设置邮箱，多个这样的用户互不冲突
+	first := login(oauthtest.User{Subject: "3001", Username: "no_mail_1"})
+	second := login(oauthtest.User{Subject: "3002", Username: "no_mail_2"})
+	if first == 0 || second == 0 || first == second {
+		t.Fatalf("users without email = %d, %d", first, second)
+	}
+
+	// 邮箱已被其他账号占用时同样不设置
+	app.register("alice")
+	third := login(oauthtest.User{Subject: "3003", Email: "alice@example.com", Username: "alice_oidc"})
+	var user model.User
+	if err := app.db.First(&user, third).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if user.Email != nil {
+		t.Fatalf("user email = %q, want unset", *user.Email)
+	}
+
+	// 注册时不填邮箱同理
+	app.ok(http.MethodPost, path("/register"), "", gin.H{"username": "carol", "password": testPassword})
+	app.ok(http.MethodPost, path("/register"), "", gin.H{"username": "dave", "password": testPassword})
+}
+
+func TestPublicRateLimit(t *testing.T) {
+	app := newTestApp(t)
+	ratelimit.Init(ratelimit.NewMemoryLimiter())
+	defer ratelimit.Init(nil)
+
+	// 找回密码每小时限5次，超出后返回429
+	for i := 0; i < 5; i++ {
+		app.ok(http.MethodPost, path("/password/forgot"), "", gin.H{"email": "nobody@example.com"})
+	}
+	app.expect(http.StatusTooManyRequests, apperr.CodeRateLimited, http.MethodPost, path("/password/forgot"), "", gin.H{"email": "nobody@example.com"})
+}
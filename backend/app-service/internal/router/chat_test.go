@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/filter"
+	"ai-egg/app-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDirectChat(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	carol := app.register("carol")
+
+	messageID := idOf(t, app.ok(http.MethodPost, path("/chat"), alice.Token, gin.H{"receiverId": bob.ID, "content": "你好"}))
+	var message model.Message
+	if err := app.db.First(&message, messageID).Error; err != nil {
+		t.Fatalf("load message: %v", err)
+	}
+	app.ok(http.MethodPost, path("/chat"), alice.Token, gin.H{"receiverId": bob.ID, "content": "在吗"})
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/chat/%d", message.ChatID), bob.Token, nil)); n != 2 {
+		t.Fatalf("got %d messages, want 2", n)
+	}
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/chat/%d", message.ChatID), carol.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/chat/999"), bob.Token, nil)
+}
+
+func TestChatMessageFilter(t *testing.T) {
+	app := newTestApp(t)
+	filter.Init(filter.New(filter.NewWordStage([]filter.Rule{{Word: "代开发票", Action: filter.ActionReject}})))
+	defer filter.Init(nil)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	chatID := idOf(t, app.ok(http.MethodPost, path("/chat/group"), alice.Token, gin.H{"name": "学习小组", "memberIds": []uint{bob.ID}}))
+
+	// 系统消息只能由服务端生成
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPost, path("/chat"), alice.Token, gin.H{"receiverId": bob.ID, "content": "系统通知", "type": "system"})
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPost, path("/chat/%d/message", chatID), alice.Token, gin.H{"content": "系统通知", "type": "system"})
+
+	// 非文本消息同样经过内容过滤
+	for _, msgType := range []string{"text", "image", "file"} {
+		app.expect(http.StatusBadRequest, apperr.CodeContentRejected, http.MethodPost, path("/chat"), alice.Token, gin.H{"receiverId": bob.ID, "content": "代开发票", "type": msgType})
+		app.expect(http.StatusBadRequest, apperr.CodeContentRejected, http.MethodPost, path("/chat/%d/message", chatID), alice.Token, gin.H{"content": "代开发票", "type": msgType})
+	}
+	app.ok(http.MethodPost, path("/chat"), alice.Token, gin.H{"receiverId": bob.ID, "content": "https://example.com/a.png", "type": "image"})
+}
+
+func TestGroupChat(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	carol := app.register("carol")
+
+	// 不存在的用户被忽略
+	chatID := idOf(t, app.ok(http.MethodPost, path("/chat/group"), alice.Token, gin.H{"name": "学习小组", "memberIds": []uint{bob.ID, 999}}))
+	if n := listLen(t, app.ok(http.MethodGet, path("/chat/%d/members", chatID), alice.Token, nil)); n != 2 {
+		t.Fatalf("got %d members, want 2", n)
+	}
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodPost, path("/chat/%d/message", chatID), carol.Token, gin.H{"content": "我能发言吗"})
+
+	var added struct {
+		Added []uint `json:"added"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/chat/%d/members", chatID), bob.Token, gin.H{"userIds": []uint{carol.ID, alice.ID}}), &added)
+	if len(added.Added) != 1 || added.Added[0] != carol.ID {
+		t.Fatalf("added = %v, want [%d]", added.Added, carol.ID)
+	}
+
+	app.ok(http.MethodPost, path("/chat/%d/message", chatID), alice.Token, gin.H{"content": "欢迎"})
+
+	var groups struct {
+		List []struct {
+			ID          uint  `json:"id"`
+			MemberCount int64 `json:"member_count"`
+			Unread      int64 `json:"unread"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/chat/groups"), carol.Token, nil), &groups)
+	if len(groups.List) != 1 || groups.List[0].MemberCount != 3 || groups.List[0].Unread == 0 {
+		t.Fatalf("carol groups = %+v", groups.List)
+	}
+	app.ok(http.MethodPost, path("/chat/%d/read", chatID), carol.Token, nil)
+	decode(t, app.ok(http.MethodGet, path("/chat/groups"), carol.Token, nil), &groups)
+	if groups.List[0].Unread != 0 {
+		t.Fatalf("unread after read = %d, want 0", groups.List[0].Unread)
+	}
+
+	// 群主退出后由最早加入的成员接任
+	app.ok(http.MethodPost, path("/chat/%d/leave", chatID), alice.Token, nil)
+	var owner model.ChatMember
+	if err := app.db.Where("chat_id = ? AND role = ?", chatID, 1).First(&owner).Error; err != nil {
+		t.Fatalf("load owner: %v", err)
+	}
+	if owner.UserID != bob.ID {
+		t.Fatalf("owner = %d, want %d", owner.UserID, bob.ID)
+	}
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/chat/%d/members", chatID), alice.Token, nil)
+}
@@ -0,0 +1,418 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/ranking"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestQuestions(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{
+		"title":   "如何学习Go语言",
+		"content": "有什么推荐的入门资料吗 @bob",
+		"tags":    []string{"go"},
+	}))
+	app.ok(http.MethodPost, path("/answer"), bob.Token, gin.H{"questionId": questionID, "content": "先读官方教程"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/answer"), bob.Token, gin.H{"questionId": 999, "content": "不存在"})
+
+	app.ok(http.MethodPost, path("/question/%d/like", questionID), bob.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/question/%d/like", questionID), bob.Token, nil)
+
+	// 登录用户返回点赞状态，游客不返回
+	var question struct {
+		ID      uint  `json:"id"`
+		Likes   int   `json:"likes"`
+		IsLiked *bool `json:"is_liked"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/question/%d", questionID), bob.Token, nil), &question)
+	if question.Likes != 1 || question.IsLiked == nil || !*question.IsLiked {
+		t.Fatalf("question after like: %+v", question)
+	}
+	question.IsLiked = nil
+	decode(t, app.ok(http.MethodGet, path("/question/%d", questionID), "", nil), &question)
+	if question.IsLiked != nil {
+		t.Fatalf("guest got is_liked")
+	}
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/question/999"), "", nil)
+
+	app.ok(http.MethodPost, path("/question/%d/unlike", questionID), bob.Token, nil)
+	app.expect(http.StatusConflict, "", http.MethodPost, path("/question/%d/unlike", questionID), bob.Token, nil)
+
+	for _, sort := range []string{"new", "hot", "top"} {
+		if n := listLen(t, app.ok(http.MethodGet, path("/questions?sort=%s", sort), "", nil)); n != 1 {
+			t.Fatalf("sort=%s returned %d questions", sort, n)
+		}
+	}
+	app.expect(http.StatusBadRequest, "", http.MethodGet, path("/questions?sort=random"), "", nil)
+	app.ok(http.MethodGet, path("/trending"), "", nil)
+	app.ok(http.MethodGet, path("/question/%d/related", questionID), "", nil)
+	app.ok(http.MethodPost, path("/question/similar"), alice.Token, gin.H{"title": "如何学习Go语言"})
+
+	// 被@和收到回答都会产生通知
+	var notifications struct {
+		List   []json.RawMessage `json:"list"`
+		Unread int64             `json:"unread"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/notifications"), alice.Token, nil), &notifications)
+	if len(notifications.List) == 0 {
+		t.Fatalf("alice has no notifications")
+	}
+	decode(t, app.ok(http.MethodGet, path("/notifications"), bob.Token, nil), &notifications)
+	if len(notifications.List) == 0 {
+		t.Fatalf("bob was not notified of the mention")
+	}
+}
+
+func TestQuestionCursorPagination(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	for i := 0; i < 3; i++ {
+		app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": fmt.Sprintf("问题%d", i), "content": "内容"})
+	}
+
+	type listPage struct {
+		List []struct {
+			ID uint `json:"id"`
+		} `json:"list"`
+		HasMore    bool   `json:"hasMore"`
+		NextCursor string `json:"nextCursor"`
+	}
+	var first, second listPage
+	decode(t, app.ok(http.MethodGet, path("/questions?limit=2"), "", nil), &first)
+	if len(first.List) != 2 || !first.HasMore || first.NextCursor == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	decode(t, app.ok(http.MethodGet, path("/questions?limit=2&cursor=%s", first.NextCursor), "", nil), &second)
+	if len(second.List) != 1 || second.HasMore || second.NextCursor != "" {
+		t.Fatalf("second page = %+v", second)
+	}
+	if second.List[0].ID >= first.List[1].ID {
+		t.Fatalf("second page overlaps first: %+v, %+v", first.List, second.List)
+	}
+
+	// 无效或被篡改的游标返回400，热度排序不支持游标
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidCursor, http.MethodGet, path("/questions?cursor=bad!"), "", nil)
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidCursor, http.MethodGet, path("/questions?cursor=%s", first.NextCursor[1:]), "", nil)
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodGet, path("/questions?sort=hot&cursor=%s", first.NextCursor), "", nil)
+
+	var hot listPage
+	decode(t, app.ok(http.MethodGet, path("/questions?sort=hot&limit=2"), "", nil), &hot)
+	if len(hot.List) != 2 || !hot.HasMore || hot.NextCursor != "" {
+		t.Fatalf("hot page = %+v", hot)
+	}
+}
+
+func TestQuestionHotScore(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "问题", "content": "内容"}))
+
+	expectEngagement := func(want float64) {
+		t.Helper()
+		var question model.Question
+		if err := app.db.First(&question, questionID).Error; err != nil {
+			t.Fatalf("load question: %v", err)
+		}
+		if math.Abs(question.Engagement-want) > 1e-9 {
+			t.Fatalf("engagement = %v, want %v", question.Engagement, want)
+		}
+	}
+
+	// 同一访客重复浏览只计入一次
+	for i := 0; i < 3; i++ {
+		app.ok(http.MethodGet, path("/question/%d", questionID), bob.Token, nil)
+	}
+	expectEngagement(ranking.WeightView)
+
+	// 删除评论后扣除评论计入的热度
+	commentID := idOf(t, app.ok(http.MethodPost, path("/comment"), bob.Token, gin.H{"targetId": questionID, "targetType": "question", "content": "评论"}))
+	expectEngagement(ranking.WeightView + ranking.WeightComment)
+	app.ok(http.MethodDelete, path("/comment/%d", commentID), bob.Token, nil)
+	expectEngagement(ranking.WeightView)
+}
+
+func TestNotes(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	noteID := idOf(t, app.ok(http.MethodPost, path("/note"), alice.Token, gin.H{
+		"title":    "Go并发笔记",
+		"content":  "goroutine和channel",
+		"category": "go",
+	}))
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPost, path("/note"), alice.Token, gin.H{"title": "缺少正文"})
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/notes"), "", nil)); n != 1 {
+		t.Fatalf("got %d notes, want 1", n)
+	}
+	if n := listLen(t, app.ok(http.MethodGet, path("/note/category/go"), "", nil)); n != 1 {
+		t.Fatalf("got %d notes in category, want 1", n)
+	}
+	app.ok(http.MethodGet, path("/note/categories"), "", nil)
+
+	var note struct {
+		ID    uint   `json:"id"`
+		Title string `json:"title"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/note/%d", noteID), alice.Token, nil), &note)
+	if note.Title != "Go并发笔记" {
+		t.Fatalf("note title = %q", note.Title)
+	}
+}
+
+func TestComments(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "问题", "content": "内容"}))
+	commentID := idOf(t, app.ok(http.MethodPost, path("/comment"), bob.Token, gin.H{
+		"targetId":   questionID,
+		"targetType": "question",
+		"content":    "好问题",
+	}))
+	replyID := idOf(t, app.ok(http.MethodPost, path("/comment/%d/reply", commentID), alice.Token, gin.H{"content": "谢谢"}))
+
+	list := app.ok(http.MethodGet, path("/comments?targetType=question&targetId=%d", questionID), alice.Token, nil)
+	if n := listLen(t, list); n != 2 {
+		t.Fatalf("got %d comments, want 2", n)
+	}
+
+	app.ok(http.MethodPost, path("/comment/%d/like", commentID), alice.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/comment/%d/like", commentID), alice.Token, nil)
+	app.ok(http.MethodPost, path("/comment/%d/unlike", commentID), alice.Token, nil)
+
+	var comment struct {
+		ID    uint `json:"id"`
+		Likes int  `json:"likes"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/comment/%d", commentID), alice.Token, nil), &comment)
+	if comment.Likes != 0 {
+		t.Fatalf("comment likes = %d, want 0", comment.Likes)
+	}
+
+	// 只有作者可以删除评论
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodDelete, path("/comment/%d", replyID), bob.Token, nil)
+	app.ok(http.MethodDelete, path("/comment/%d", replyID), alice.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/comment/%d", replyID), alice.Token, nil)
+}
+
+func TestBookmarks(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "收藏的问题", "content": "内容"}))
+	folderID := idOf(t, app.ok(http.MethodPost, path("/bookmark/folder"), alice.Token, gin.H{"name": "学习"}))
+	app.ok(http.MethodPut, path("/bookmark/folder/%d", folderID), alice.Token, gin.H{"name": "学习资料"})
+
+	bookmarkID := idOf(t, app.ok(http.MethodPost, path("/bookmark"), alice.Token, gin.H{
+		"targetType": "question",
+		"targetId":   questionID,
+		"folderId":   folderID,
+	}))
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/bookmark"), alice.Token, gin.H{
+		"targetType": "question",
+		"targetId":   questionID,
+	})
+	app.ok(http.MethodPut, path("/bookmark/%d", bookmarkID), alice.Token, gin.H{"remark": "稍后和 @bob 一起看"})
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/bookmarks?folderId=%d", folderID), alice.Token, nil)); n != 1 {
+		t.Fatalf("got %d bookmarks in folder, want 1", n)
+	}
+	if n := listLen(t, app.ok(http.MethodGet, path("/bookmark/folders"), alice.Token, nil)); n != 1 {
+		t.Fatalf("got %d folders, want 1", n)
+	}
+
+	noteID := idOf(t, app.ok(http.MethodPost, path("/bookmark/%d/note", bookmarkID), alice.Token, gin.H{}))
+	app.ok(http.MethodGet, path("/note/%d", noteID), alice.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeConflict, http.MethodPost, path("/bookmark/%d/note", bookmarkID), alice.Token, gin.H{})
+
+	// 转成的笔记与直接发布的笔记一样通知被提及的用户
+	if n := listLen(t, app.ok(http.MethodGet, path("/notifications"), bob.Token, nil)); n != 1 {
+		t.Fatalf("got %d notifications for mentioned user, want 1", n)
+	}
+
+	// 删除收藏夹后收藏移到未分类
+	app.ok(http.MethodDelete, path("/bookmark/folder/%d", folderID), alice.Token, nil)
+	if n := listLen(t, app.ok(http.MethodGet, path("/bookmarks?folderId=0"), alice.Token, nil)); n != 1 {
+		t.Fatalf("got %d uncategorized bookmarks, want 1", n)
+	}
+	app.ok(http.MethodDelete, path("/bookmark/%d", bookmarkID), alice.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodDelete, path("/bookmark/%d", bookmarkID), alice.Token, nil)
+
+	// 原内容下架后不能再转为笔记
+	hiddenID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "将被下架的问题", "content": "内容"}))
+	hiddenBookmarkID := idOf(t, app.ok(http.MethodPost, path("/bookmark"), alice.Token, gin.H{"targetType": "question", "targetId": hiddenID}))
+	if err := app.db.Model(&model.Question{}).Where("id = ?", hiddenID).Update("status", model.ContentStatusRemoved).Error; err != nil {
+		t.Fatalf("hide question: %v", err)
+	}
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/bookmark/%d/note", hiddenBookmarkID), alice.Token, gin.H{})
+}
+
+func TestNotifications(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "问题", "content": "内容"}))
+	app.ok(http.MethodPost, path("/question/%d/like", questionID), bob.Token, nil)
+
+	var count struct {
+		Total int64 `json:"total"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/notifications/unread-count"), alice.Token, nil), &count)
+	if count.Total != 1 {
+		t.Fatalf("unread = %d, want 1", count.Total)
+	}
+
+	var list struct {
+		List []struct {
+			ID uint `json:"id"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/notifications"), alice.Token, nil), &list)
+	if len(list.List) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(list.List))
+	}
+	app.ok(http.MethodPost, path("/notifications/%d/read", list.List[0].ID), alice.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/notifications/%d/read", list.List[0].ID), bob.Token, nil)
+	app.ok(http.MethodPost, path("/notifications/read-all"), alice.Token, nil)
+
+	decode(t, app.ok(http.MethodGet, path("/notifications/unread-count"), alice.Token, nil), &count)
+	if count.Total != 0 {
+		t.Fatalf("unread after read-all = %d, want 0", count.Total)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+
+	app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "golang channel", "content": "buffered channel"})
+	app.ok(http.MethodPost, path("/note"), alice.Token, gin.H{"title": "golang notes", "content": "channel basics"})
+
+	var result struct {
+		Total int64 `json:"total"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/search?keyword=golang"), alice.Token, nil), &result)
+	if result.Total != 2 {
+		t.Fatalf("search total = %d, want 2", result.Total)
+	}
+	app.expect(http.StatusBadRequest, "", http.MethodGet, path("/search"), alice.Token, nil)
+	app.expect(http.StatusBadRequest, "", http.MethodGet, path("/search?keyword=go&type=unknown"), alice.Token, nil)
+
+	app.ok(http.MethodGet, path("/search/questions?keyword=golang"), alice.Token, nil)
+	app.ok(http.MethodGet, path("/search/notes?keyword=golang"), alice.Token, nil)
+	app.ok(http.MethodGet, path("/search/suggest?prefix=go"), alice.Token, nil)
+	app.ok(http.MethodGet, path("/search/trending"), alice.Token, nil)
+
+	var history struct {
+		List []struct {
+			ID uint `json:"id"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/search/history"), alice.Token, nil), &history)
+	if len(history.List) != 1 {
+		t.Fatalf("got %d history entries, want 1", len(history.List))
+	}
+	app.ok(http.MethodDelete, path("/search/history/%d", history.List[0].ID), alice.Token, nil)
+	app.ok(http.MethodDelete, path("/search/history"), alice.Token, nil)
+}
+
+func TestReports(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "问题", "content": "内容"}))
+	report := gin.H{"targetType": "question", "targetId": questionID, "reason": "spam"}
+	app.ok(http.MethodPost, path("/report"), bob.Token, report)
+	app.expect(http.StatusConflict, "", http.MethodPost, path("/report"), bob.Token, report)
+	app.expect(http.StatusBadRequest, apperr.CodeInvalidRequest, http.MethodPost, path("/report"), bob.Token, gin.H{
+		"targetType": "question",
+		"targetId":   questionID,
+		"reason":     "boring",
+	})
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/reports"), bob.Token, nil)); n != 1 {
+		t.Fatalf("got %d reports, want 1", n)
+	}
+}
+
+func TestNotificationStream(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+
+	app.expect(http.StatusUnauthorized, apperr.CodeUnauthorized, http.MethodGet, path("/notifications/stream"), "", nil)
+	app.expect(http.StatusUnauthorized, apperr.CodeUnauthorized, http.MethodGet, path("/notifications/stream?token=%s", alice.Token), "", nil)
+
+	// 访问令牌不出现在URL中，浏览器先换取一次性推送票据
+	var ticket struct {
+		Ticket string `json:"ticket"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/notifications/stream/ticket"), alice.Token, nil), &ticket)
+	app.expect(http.StatusUnauthorized, apperr.CodeTokenInvalid, http.MethodGet, path("/user"), ticket.Ticket, nil)
+
+	server := httptest.NewServer(app.router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path("/notifications/stream?ticket=%s", ticket.Ticket), nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stream status %d", resp.StatusCode)
+	}
+
+	// 连接建立后先推送未读数，之后推送新通知
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				events <- event
+			}
+		}
+	}()
+	if event := <-events; event != "unread" {
+		t.Fatalf("first event = %q, want unread", event)
+	}
+
+	// 票据只能使用一次
+	app.expect(http.StatusUnauthorized, apperr.CodeTokenInvalid, http.MethodGet, path("/notifications/stream?ticket=%s", ticket.Ticket), "", nil)
+
+	questionID := idOf(t, app.ok(http.MethodPost, path("/question"), alice.Token, gin.H{"title": "问题", "content": "内容"}))
+	app.ok(http.MethodPost, path("/question/%d/like", questionID), bob.Token, nil)
+	if event := <-events; event != "notification" {
+		t.Fatalf("event = %q, want notification", event)
+	}
+}
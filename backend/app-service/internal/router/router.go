@@ -3,12 +3,26 @@ package router
 import (
 	"ai-egg/app-service/internal/handler"
 	"ai-egg/app-service/internal/middleware"
+	"ai-egg/app-service/internal/ratelimit"
+	"ai-egg/app-service/internal/rbac"
+	"ai-egg/app-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+// SetupRouter 注册路由，问答、笔记、评论、聊天、村落和用户模块的处理器通过services注入业务服务
+func SetupRouter(services *service.Services) *gin.Engine {
+	users := handler.NewUserHandler(services.Users)
+	questions := handler.NewQuestionHandler(services.Questions)
+	notes := handler.NewNoteHandler(services.Notes)
+	comments := handler.NewCommentHandler(services.Comments)
+	chats := handler.NewChatHandler(services.Chats)
+	villages := handler.NewVillageHandler(services.Villages)
+
 	r := gin.Default()
+	// 统一输出错误响应，需在其他中间件之前注册
+	r.Use(middleware.Errors())
+	r.NoRoute(middleware.NoRoute)
 
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
@@ -16,67 +30,177 @@ func SetupRouter() *gin.Engine {
 	})
 
 	// 公开路由
+	// 公开路由按IP限流，注册和找回密码额外限制
 	public := r.Group("/api/v1")
+	public.Use(middleware.RateLimit("public", ratelimit.PerMinute(30), middleware.KeyByIP))
 	{
-		public.POST("/register", handler.Register)
-		public.POST("/login", handler.Login)
-		public.GET("/check-login", handler.CheckLogin)
+		public.POST("/register", middleware.RateLimit("register", ratelimit.PerHour(10), middleware.KeyByIP), users.Register)
+		public.POST("/login", users.Login)
+		public.POST("/token/refresh", handler.RefreshToken)
+		public.POST("/email/verify", handler.VerifyEmail)
+		public.POST("/password/forgot", middleware.RateLimit("password-forgot", ratelimit.PerHour(5), middleware.KeyByIP), handler.ForgotPassword)
+		public.POST("/password/reset", handler.ResetPassword)
+		public.GET("/oauth/providers", handler.GetOAuthProviders)
+		public.POST("/oauth/:provider/callback", handler.OAuthCallback)
+	}
+
+	// 游客可访问的只读路由，登录用户会额外返回点赞状态等个人字段
+	optional := r.Group("/api/v1")
+	optional.Use(middleware.OptionalAuth(), middleware.RateLimit("api", ratelimit.PerMinute(300), middleware.KeyByUser))
+	{
+		optional.GET("/check-login", handler.CheckLogin)
+		optional.GET("/oauth/:provider/authorize", handler.OAuthAuthorize)
+		optional.GET("/questions", questions.GetQuestions)
+		optional.GET("/question/:id", questions.GetQuestion)
+		optional.GET("/question/:id/related", questions.GetRelatedQuestions)
+		optional.GET("/trending", handler.GetTrending)
+		optional.GET("/notes", notes.GetNotes)
+		optional.GET("/note/:id", notes.GetNote)
+		optional.GET("/note/categories", notes.GetNoteCategories)
+		optional.GET("/note/category/:id", notes.GetNotesByCategory)
+		optional.GET("/earth-villages", villages.GetVillages)
+		optional.GET("/earth-village/:id", villages.GetVillage)
+	}
+
+	// 通知实时推送，浏览器EventSource无法设置请求头，通过ticket参数传递一次性推送票据
+	stream := r.Group("/api/v1")
+	stream.Use(middleware.StreamAuth(), middleware.RateLimit("stream", ratelimit.PerMinute(30), middleware.KeyByUser))
+	{
+		stream.GET("/notifications/stream", handler.StreamNotifications)
 	}
 
 	// 需要认证的路由
 	authorized := r.Group("/api/v1")
-	authorized.Use(middleware.Auth())
+	authorized.Use(middleware.Auth(), middleware.RateLimit("api", ratelimit.PerMinute(300), middleware.KeyByUser))
 	{
 		authorized.POST("/logout", handler.Logout)
+		authorized.POST("/logout/all", handler.LogoutAll)
+		authorized.GET("/sessions", handler.GetSessions)
+		authorized.DELETE("/sessions/:id", handler.RevokeSession)
 
 		// 用户模块
-		authorized.GET("/user", handler.GetUser)
-		authorized.PUT("/user", handler.UpdateUser)
+		authorized.GET("/user", users.GetUser)
+		authorized.PUT("/user", users.UpdateUser)
+		authorized.PUT("/password", handler.ChangePassword)
+		authorized.POST("/email/verify/send", handler.SendVerificationEmail)
+		authorized.GET("/oauth/identities", handler.GetIdentities)
+		authorized.DELETE("/oauth/identities/:provider", handler.UnlinkIdentity)
+
+		// 举报
+		authorized.POST("/report", middleware.RateLimit("report", ratelimit.PerHour(20), middleware.KeyByUser), handler.CreateReport)
+		authorized.GET("/reports", handler.GetMyReports)
+
+		// 通知
+		authorized.GET("/notifications", handler.GetNotifications)
+		authorized.POST("/notifications/stream/ticket", handler.CreateStreamTicket)
+		authorized.GET("/notifications/unread-count", handler.GetUnreadNotificationCount)
+		authorized.POST("/notifications/:id/read", handler.MarkNotificationRead)
+		authorized.POST("/notifications/read-all", handler.MarkAllNotificationsRead)
+
+		// 收藏
+		authorized.POST("/bookmark", handler.CreateBookmark)
+		authorized.GET("/bookmarks", handler.GetBookmarks)
+		authorized.PUT("/bookmark/:id", handler.UpdateBookmark)
+		authorized.DELETE("/bookmark/:id", handler.DeleteBookmark)
+		authorized.POST("/bookmark/:id/note", notes.ConvertBookmarkToNote)
+		authorized.GET("/bookmark/folders", handler.GetBookmarkFolders)
+		authorized.POST("/bookmark/folder", handler.CreateBookmarkFolder)
+		authorized.PUT("/bookmark/folder/:id", handler.RenameBookmarkFolder)
+		authorized.DELETE("/bookmark/folder/:id", handler.DeleteBookmarkFolder)
 
 		// 问答模块
-		authorized.POST("/question", handler.CreateQuestion)
-		authorized.POST("/answer", handler.CreateAnswer)
-		authorized.GET("/questions", handler.GetQuestions)
-		authorized.GET("/question/:id", handler.GetQuestion)
-		authorized.POST("/question/:id/like", handler.LikeQuestion)
-		authorized.POST("/question/:id/unlike", handler.UnlikeQuestion)
+		authorized.POST("/question", questions.CreateQuestion)
+		authorized.POST("/answer", questions.CreateAnswer)
+		authorized.POST("/question/similar", questions.CheckSimilarQuestions)
+		authorized.POST("/question/:id/like", questions.LikeQuestion)
+		authorized.POST("/question/:id/unlike", questions.UnlikeQuestion)
 
 		// 评论模块
-		authorized.POST("/comment", handler.CreateComment)
-		authorized.GET("/comments", handler.GetComments)
-		authorized.GET("/comment/:id", handler.GetComment)
-		authorized.POST("/comment/:id/like", handler.LikeComment)
-		authorized.POST("/comment/:id/unlike", handler.UnlikeComment)
-		authorized.DELETE("/comment/:id", handler.DeleteComment)
-		authorized.POST("/comment/:id/reply", handler.ReplyComment)
+		authorized.POST("/comment", comments.CreateComment)
+		authorized.GET("/comments", comments.GetComments)
+		authorized.GET("/comment/:id", comments.GetComment)
+		authorized.POST("/comment/:id/like", comments.LikeComment)
+		authorized.POST("/comment/:id/unlike", comments.UnlikeComment)
+		authorized.DELETE("/comment/:id", comments.DeleteComment)
+		authorized.POST("/comment/:id/reply", comments.ReplyComment)
 
 		// 笔记模块
-		authorized.POST("/note", handler.CreateNote)
-		authorized.GET("/notes", handler.GetNotes)
-		authorized.GET("/note/:id", handler.GetNote)
-		authorized.GET("/note/categories", handler.GetNoteCategories)
-		authorized.GET("/note/category/:id", handler.GetNotesByCategory)
+		authorized.POST("/note", notes.CreateNote)
 
 		// 聊天模块
-		authorized.POST("/chat", handler.SendMessage)
-		authorized.GET("/chat/:id", handler.GetChatHistory)
+		authorized.POST("/chat", chats.SendMessage)
+		authorized.GET("/chat/:id", chats.GetChatHistory)
+		authorized.POST("/chat/group", chats.CreateGroupChat)
+		authorized.GET("/chat/groups", chats.GetGroupChats)
+		authorized.GET("/chat/:id/members", chats.GetChatMembers)
+		authorized.POST("/chat/:id/members", chats.AddChatMembers)
+		authorized.POST("/chat/:id/leave", chats.LeaveChat)
+		authorized.POST("/chat/:id/message", chats.SendGroupMessage)
+		authorized.POST("/chat/:id/read", chats.MarkChatRead)
 
 		// 地球村模块
-		authorized.POST("/earth-village/join", handler.JoinVillage)
-		authorized.POST("/earth-village/leave", handler.LeaveVillage)
-		authorized.GET("/earth-villages", handler.GetVillages)
-		authorized.GET("/earth-village/:id", handler.GetVillage)
-		authorized.POST("/earth-village/:id/post", handler.CreatePost)
-		authorized.GET("/earth-village/:id/posts", handler.GetPosts)
-		authorized.POST("/earth-village/:id/post/:postId/like", handler.LikePost)
-		authorized.POST("/earth-village/:id/post/:postId/unlike", handler.UnlikePost)
-		authorized.DELETE("/earth-village/:id/post/:postId", handler.DeletePost)
-		authorized.POST("/earth-village/:id/post/:postId/reply", handler.ReplyPost)
-		authorized.GET("/earth-village/:id/post/:postId/replies", handler.GetReplies)
+		authorized.POST("/earth-village/:id/join", villages.JoinVillage)
+		authorized.POST("/earth-village/:id/leave", villages.LeaveVillage)
+		authorized.POST("/earth-village/:id/post", villages.CreatePost)
+		authorized.GET("/earth-village/:id/posts", villages.GetPosts)
+		authorized.POST("/earth-village/:id/post/:postId/like", villages.LikePost)
+		authorized.POST("/earth-village/:id/post/:postId/unlike", villages.UnlikePost)
+		authorized.PUT("/earth-village/:id/post/:postId", villages.EditPost)
+		authorized.DELETE("/earth-village/:id/post/:postId", villages.DeletePost)
+		authorized.POST("/earth-village/:id/post/:postId/reply", villages.ReplyPost)
+		authorized.GET("/earth-village/:id/post/:postId/replies", villages.GetReplies)
+		authorized.PUT("/earth-village/:id/post/:postId/reply/:replyId", villages.EditReply)
+		authorized.POST("/earth-village/:id/chat/join", chats.JoinVillageChat)
+
+		// 村落管理
+		authorized.PUT("/earth-village/:id/policy", villages.UpdateVillagePolicy)
+		authorized.GET("/earth-village/:id/posts/pending", villages.GetPendingPosts)
+		authorized.POST("/earth-village/:id/post/:postId/approve", villages.ApprovePost)
+		authorized.POST("/earth-village/:id/post/:postId/reject", villages.RejectPost)
+		authorized.POST("/earth-village/:id/post/:postId/pin", villages.PinPost)
+		authorized.POST("/earth-village/:id/post/:postId/unpin", villages.UnpinPost)
+		authorized.POST("/earth-village/:id/anonymous/trace", villages.TraceAnonymousAuthor)
 
 		// 搜索模块
+		authorized.GET("/search", handler.Search)
 		authorized.GET("/search/questions", handler.SearchQuestions)
 		authorized.GET("/search/notes", handler.SearchNotes)
+		authorized.GET("/search/suggest", handler.SearchSuggest)
+		authorized.GET("/search/trending", handler.GetTrendingKeywords)
+		authorized.GET("/search/history", handler.GetSearchHistory)
+		authorized.DELETE("/search/history", handler.ClearSearchHistory)
+		authorized.DELETE("/search/history/:id", handler.DeleteSearchHistory)
+	}
+
+	// 管理后台
+	admin := r.Group("/api/v1/admin")
+	admin.Use(middleware.Auth())
+	{
+		// 用户管理
+		admin.GET("/users", middleware.RequirePermission(rbac.PermUserView), handler.GetAdminUsers)
+		admin.GET("/users/:id", middleware.RequirePermission(rbac.PermUserView), handler.GetAdminUser)
+		admin.PUT("/users/:id/role", middleware.RequirePermission(rbac.PermRoleAssign), handler.UpdateUserRole)
+		admin.POST("/users/:id/disable", middleware.RequirePermission(rbac.PermUserManage), handler.DisableUser)
+		admin.POST("/users/:id/enable", middleware.RequirePermission(rbac.PermUserManage), handler.EnableUser)
+		admin.GET("/users/:id/status-logs", middleware.RequirePermission(rbac.PermUserView), handler.GetUserStatusLogs)
+
+		// 内容管理：type为question/answer/note/post/comment/message
+		admin.DELETE("/content/:type/:id", middleware.RequirePermission(rbac.PermContentRemove), handler.RemoveContent)
+		admin.POST("/content/:type/:id/restore", middleware.RequirePermission(rbac.PermContentRemove), handler.RestoreContent)
+
+		// 举报审核
+		admin.GET("/reports", middleware.RequirePermission(rbac.PermReportHandle), handler.GetReports)
+		admin.GET("/reports/:id", middleware.RequirePermission(rbac.PermReportHandle), handler.GetReport)
+		admin.POST("/reports/:id/resolve", middleware.RequirePermission(rbac.PermReportHandle), handler.ResolveReport)
+
+		// 村落管理
+		admin.GET("/villages", middleware.RequirePermission(rbac.PermVillageManage), handler.GetAdminVillages)
+		admin.POST("/villages", middleware.RequirePermission(rbac.PermVillageManage), handler.CreateVillage)
+		admin.PUT("/villages/:id", middleware.RequirePermission(rbac.PermVillageManage), handler.UpdateVillage)
+		admin.DELETE("/villages/:id", middleware.RequirePermission(rbac.PermVillageManage), handler.CloseVillage)
+
+		admin.GET("/stats", middleware.RequirePermission(rbac.PermStatsView), handler.GetPlatformStats)
+		admin.GET("/logs", middleware.RequirePermission(rbac.PermLogView), handler.GetAdminLogs)
 	}
 
 	return r
@@ -0,0 +1,297 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-egg/app-service/internal/account"
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/embedding"
+	"ai-egg/app-service/internal/filter"
+	"ai-egg/app-service/internal/mail"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/oauth"
+	"ai-egg/app-service/internal/pubsub"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/ratelimit"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+	"ai-egg/app-service/internal/service"
+	"ai-egg/app-service/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testPassword = "secret123"
+
+// testApp 基于内存SQLite启动的完整路由，全局依赖均替换为内存实现
+type testApp struct {
+	t      *testing.T
+	db     *gorm.DB
+	router *gin.Engine
+	mailer *mail.MemoryMailer
+}
+
+// testUser 通过注册接口创建的用户
+type testUser struct {
+	ID       uint
+	Username string
+	Email    string
+	Token    string
+}
+
+// testResponse 统一响应格式，错误响应额外包含error字段
+type testResponse struct {
+	Status  int             `json:"-"`
+	Code    int             `json:"code"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newTestApp 创建测试应用，数据库使用与main.go相同的迁移列表。
+// 全局依赖每次重新初始化，使用该函数的测试不能并行执行
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	// 单连接保证所有查询共享同一个内存数据库
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	// search_documents的FULLTEXT索引只有MySQL支持，测试使用内存检索引擎，不需要该表
+	var models []interface{}
+	for _, m := range model.All() {
+		if _, ok := m.(*model.SearchDocument); !ok {
+			models = append(models, m)
+		}
+	}
+	config.SetDB(db)
+	if err := config.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	account.Reset()
+	ranking.ResetViews()
+	apperr.SetLegacyStatus(false)
+	token.Init(token.NewService(config.JWTConfig{
+		Secret:     "test-secret",
+		KeyID:      "test",
+		Issuer:     "ai-egg",
+		Audience:   "ai-egg-app",
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	}, db, token.NewMemoryRevocationStore()))
+	ratelimit.Init(nil)
+	ratelimit.InitLockout(nil)
+	pubsub.Init(pubsub.NewMemoryBroker())
+	oauth.Init(nil)
+	oauth.InitStateStore(oauth.NewMemoryStateStore())
+	filter.Init(nil)
+	search.Init(search.NewMemoryIndexer())
+	embedding.Init(embedding.NewStore(db, embedding.NewHashingProvider(256)))
+
+	mailer := mail.NewMemoryMailer()
+	mail.Init(mailer, "http://localhost:5173")
+
+	return &testApp{
+		t:      t,
+		db:     db,
+		router: SetupRouter(service.New(repository.New(db))),
+		mailer: mailer,
+	}
+}
+
+// do 发送JSON请求，body为nil时不带请求体，token为空时不带Authorization头
+func (a *testApp) do(method, path, token string, body interface{}) testResponse {
+	a.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("marshal %s %s: %v", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+
+	res := testResponse{Status: w.Code}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		a.t.Fatalf("%s %s: invalid response %q: %v", method, path, w.Body.String(), err)
+	}
+	res.Status = w.Code
+	return res
+}
+
+// ok 发送请求并要求返回200，返回data字段
+func (a *testApp) ok(method, path, token string, body interface{}) json.RawMessage {
+	a.t.Helper()
+	res := a.do(method, path, token, body)
+	if res.Status != http.StatusOK {
+		a.t.Fatalf("%s %s: status %d, error %s: %s", method, path, res.Status, res.Error, res.Message)
+	}
+	return res.Data
+}
+
+// expect 发送请求并校验状态码和错误码，code为空时只校验状态码
+func (a *testApp) expect(status int, code apperr.Code, method, path, token string, body interface{}) testResponse {
+	a.t.Helper()
+	res := a.do(method, path, token, body)
+	if res.Status != status {
+		a.t.Fatalf("%s %s: status %d, want %d (%s: %s)", method, path, res.Status, status, res.Error, res.Message)
+	}
+	if code != "" && res.Error != string(code) {
+		a.t.Fatalf("%s %s: error %q, want %q", method, path, res.Error, code)
+	}
+	return res
+}
+
+// register 通过注册接口创建用户，邮箱按用户名生成
+func (a *testApp) register(username string) testUser {
+	a.t.Helper()
+	email := username + "@example.com"
+	var data struct {
+		UserID uint   `json:"userId"`
+		Token  string `json:"token"`
+	}
+	decode(a.t, a.ok(http.MethodPost, "/api/v1/register", "", gin.H{
+		"username": username,
+		"password": testPassword,
+		"email":    email,
+	}), &data)
+	return testUser{ID: data.UserID, Username: username, Email: email, Token: data.Token}
+}
+
+// login 登录并返回访问令牌
+func (a *testApp) login(username, password string) string {
+	a.t.Helper()
+	var data struct {
+		Token string `json:"token"`
+	}
+	decode(a.t, a.ok(http.MethodPost, "/api/v1/login", "", gin.H{
+		"username": username,
+		"password": password,
+	}), &data)
+	return data.Token
+}
+
+// admin 注册用户并授予管理员角色
+func (a *testApp) admin(username string) testUser {
+	a.t.Helper()
+	user := a.register(username)
+	if err := a.db.Model(&model.User{}).Where("id = ?", user.ID).Update("role", model.RoleAdmin).Error; err != nil {
+		a.t.Fatalf("grant admin: %v", err)
+	}
+	account.Invalidate(user.ID)
+	return user
+}
+
+// village 直接在数据库中创建开放发帖的村落
+func (a *testApp) village(name string) model.Village {
+	a.t.Helper()
+	village := model.Village{Name: name, Status: 1, PostPolicy: model.PostPolicyOpen, AllowAnon: true}
+	if err := a.db.Create(&village).Error; err != nil {
+		a.t.Fatalf("create village: %v", err)
+	}
+	return village
+}
+
+// decode 解析data字段
+func decode(t *testing.T, data json.RawMessage, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+}
+
+// idOf 解析data中的id字段
+func idOf(t *testing.T, data json.RawMessage) uint {
+	t.Helper()
+	var v struct {
+		ID uint `json:"id"`
+	}
+	decode(t, data, &v)
+	if v.ID == 0 {
+		t.Fatalf("missing id in %s", data)
+	}
+	return v.ID
+}
+
+// listLen 解析列表响应中list的长度
+func listLen(t *testing.T, data json.RawMessage) int {
+	t.Helper()
+	var v struct {
+		List []json.RawMessage `json:"list"`
+	}
+	decode(t, data, &v)
+	return len(v.List)
+}
+
+// mailToken 从最近发给to的邮件链接中取出token参数
+func (a *testApp) mailToken(to string) string {
+	a.t.Helper()
+	msg, ok := a.mailer.Last(to)
+	if !ok {
+		a.t.Fatalf("no mail sent to %s", to)
+	}
+	_, rest, found := strings.Cut(msg.Body, "?token=")
+	if !found {
+		a.t.Fatalf("no token in mail %q", msg.Body)
+	}
+	if end := strings.IndexAny(rest, " \n"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+func path(format string, args ...interface{}) string {
+	return "/api/v1" + fmt.Sprintf(format, args...)
+}
+
+func TestHealth(t *testing.T) {
+	app := newTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("health status %d", w.Code)
+	}
+}
+
+func TestNoRoute(t *testing.T) {
+	app := newTestApp(t)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, "/api/v1/missing", "", nil)
+}
@@ -0,0 +1,278 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/filter"
+	"ai-egg/app-service/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVillageMembership(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	village := app.village("程序员村")
+
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-villages"), "", nil)); n != 1 {
+		t.Fatalf("got %d villages, want 1", n)
+	}
+
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/999/join"), alice.Token, nil)
+
+	var detail struct {
+		MemberCount int `json:"member_count"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d", village.ID), alice.Token, nil), &detail)
+	if detail.MemberCount != 1 {
+		t.Fatalf("member_count = %d, want 1", detail.MemberCount)
+	}
+
+	// 村落聊天室仅村落成员可加入，退出村落时同时退出聊天室
+	chatID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/chat/join", village.ID), alice.Token, nil))
+	app.ok(http.MethodPost, path("/chat/%d/message", chatID), alice.Token, gin.H{"content": "大家好"})
+
+	app.ok(http.MethodPost, path("/earth-village/%d/leave", village.ID), alice.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeConflict, http.MethodPost, path("/earth-village/%d/leave", village.ID), alice.Token, nil)
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodGet, path("/chat/%d/members", chatID), alice.Token, nil)
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodPost, path("/earth-village/%d/chat/join", village.ID), alice.Token, nil)
+
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d", village.ID), alice.Token, nil), &detail)
+	if detail.MemberCount != 0 {
+		t.Fatalf("member_count after leave = %d, want 0", detail.MemberCount)
+	}
+}
+
+func TestClosedVillage(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+	alice := app.register("alice")
+	bob := app.register("bob")
+	village := app.village("程序员村")
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+	postID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "关闭前的帖子"}))
+
+	// 关闭后不能加入、发帖、回复或浏览帖子
+	app.ok(http.MethodDelete, path("/admin/villages/%d", village.ID), root.Token, gin.H{"reason": "长期无人维护"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/%d/join", village.ID), bob.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "关闭后的帖子"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodGet, path("/earth-village/%d/posts", village.ID), alice.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/%d/post/%d/reply", village.ID, postID), alice.Token, gin.H{"content": "回复"})
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/%d/post/%d/like", village.ID, postID), bob.Token, nil)
+	app.expect(http.StatusNotFound, apperr.CodeNotFound, http.MethodPost, path("/earth-village/%d/chat/join", village.ID), alice.Token, nil)
+
+	// 成员仍可退出已关闭的村落
+	app.ok(http.MethodPost, path("/earth-village/%d/leave", village.ID), alice.Token, nil)
+}
+
+func TestVillagePosts(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	village := app.village("程序员村")
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+
+	postID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "第一篇帖子"}))
+	app.ok(http.MethodPut, path("/earth-village/%d/post/%d", village.ID, postID), alice.Token, gin.H{"content": "修改后的帖子"})
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodPut, path("/earth-village/%d/post/%d", village.ID, postID), bob.Token, gin.H{"content": "不是我的帖子"})
+
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/like", village.ID, postID), bob.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeAlreadyExists, http.MethodPost, path("/earth-village/%d/post/%d/like", village.ID, postID), bob.Token, nil)
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/unlike", village.ID, postID), bob.Token, nil)
+
+	replyID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post/%d/reply", village.ID, postID), bob.Token, gin.H{"content": "沙发"}))
+	app.ok(http.MethodPut, path("/earth-village/%d/post/%d/reply/%d", village.ID, postID, replyID), bob.Token, gin.H{"content": "板凳"})
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/post/%d/replies", village.ID, postID), alice.Token, nil)); n != 1 {
+		t.Fatalf("got %d replies, want 1", n)
+	}
+
+	var posts struct {
+		List []struct {
+			ID       uint   `json:"id"`
+			Content  string `json:"content"`
+			Comments int    `json:"comments"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), bob.Token, nil), &posts)
+	if len(posts.List) != 1 || posts.List[0].Content != "修改后的帖子" || posts.List[0].Comments != 1 {
+		t.Fatalf("posts = %+v", posts.List)
+	}
+
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodDelete, path("/earth-village/%d/post/%d", village.ID, postID), bob.Token, nil)
+	app.ok(http.MethodDelete, path("/earth-village/%d/post/%d", village.ID, postID), alice.Token, nil)
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), bob.Token, nil)); n != 0 {
+		t.Fatalf("got %d posts after delete, want 0", n)
+	}
+}
+
+func TestVillageAnonymousPosts(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	village := app.village("树洞")
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), bob.Token, nil)
+	if err := app.db.Model(&model.VillageMember{}).Where("village_id = ? AND user_id = ?", village.ID, bob.ID).
+		Update("role", model.VillageRoleAdmin).Error; err != nil {
+		t.Fatalf("grant village admin: %v", err)
+	}
+
+	postID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "心里话", "anonymous": true}))
+
+	var posts struct {
+		List []struct {
+			AuthorID uint   `json:"author_id"`
+			AnonName string `json:"anon_name"`
+			IsMine   bool   `json:"is_mine"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), bob.Token, nil), &posts)
+	if len(posts.List) != 1 || posts.List[0].AuthorID != 0 || posts.List[0].AnonName == "" || posts.List[0].IsMine {
+		t.Fatalf("anonymous post leaked author: %+v", posts.List)
+	}
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), alice.Token, nil), &posts)
+	if !posts.List[0].IsMine {
+		t.Fatalf("author cannot recognise own anonymous post")
+	}
+
+	// 只有村落管理员可以追溯匿名作者
+	trace := gin.H{"targetType": "post", "targetId": postID, "reason": "违规内容"}
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodPost, path("/earth-village/%d/anonymous/trace", village.ID), alice.Token, trace)
+	var traced struct {
+		Author struct {
+			ID uint `json:"id"`
+		} `json:"author"`
+	}
+	decode(t, app.ok(http.MethodPost, path("/earth-village/%d/anonymous/trace", village.ID), bob.Token, trace), &traced)
+	if traced.Author.ID != alice.ID {
+		t.Fatalf("traced author = %d, want %d", traced.Author.ID, alice.ID)
+	}
+}
+
+func TestVillageModeration(t *testing.T) {
+	app := newTestApp(t)
+	alice := app.register("alice")
+	bob := app.register("bob")
+	village := app.village("审核村")
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+	if err := app.db.Model(&model.VillageMember{}).Where("village_id = ? AND user_id = ?", village.ID, alice.ID).
+		Update("role", model.VillageRoleAdmin).Error; err != nil {
+		t.Fatalf("grant village admin: %v", err)
+	}
+
+	app.expect(http.StatusForbidden, apperr.CodeForbidden, http.MethodPut, path("/earth-village/%d/policy", village.ID), bob.Token, gin.H{"postPolicy": "approval"})
+	app.ok(http.MethodPut, path("/earth-village/%d/policy", village.ID), alice.Token, gin.H{"postPolicy": "approval"})
+
+	// 审核制村落的新帖进入待审核列表，通过后才对外展示
+	approved := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), bob.Token, gin.H{"content": "待审核的帖子"}))
+	rejected := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), bob.Token, gin.H{"content": "会被驳回的帖子"}))
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), bob.Token, nil)); n != 0 {
+		t.Fatalf("pending posts are visible: %d", n)
+	}
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts/pending", village.ID), alice.Token, nil)); n != 2 {
+		t.Fatalf("got %d pending posts, want 2", n)
+	}
+
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/approve", village.ID, approved), alice.Token, nil)
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/reject", village.ID, rejected), alice.Token, gin.H{"reason": "与主题无关"})
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/pin", village.ID, approved), alice.Token, nil)
+
+	var posts struct {
+		List []struct {
+			ID       uint `json:"id"`
+			IsPinned bool `json:"is_pinned"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), bob.Token, nil), &posts)
+	if len(posts.List) != 1 || posts.List[0].ID != approved || !posts.List[0].IsPinned {
+		t.Fatalf("posts after moderation = %+v", posts.List)
+	}
+	app.ok(http.MethodPost, path("/earth-village/%d/post/%d/unpin", village.ID, approved), alice.Token, nil)
+}
+
+func TestVillageAdminCannotApproveFilterHeldPost(t *testing.T) {
+	app := newTestApp(t)
+	filter.Init(filter.New(filter.NewWordStage([]filter.Rule{{Word: "加微信", Action: filter.ActionHold}})))
+	defer filter.Init(nil)
+	root := app.admin("root")
+	alice := app.register("alice")
+	village := app.village("审核村")
+	app.ok(http.MethodPost, path("/earth-village/%d/join", village.ID), alice.Token, nil)
+	if err := app.db.Model(&model.VillageMember{}).Where("village_id = ? AND user_id = ?", village.ID, alice.ID).
+		Update("role", model.VillageRoleAdmin).Error; err != nil {
+		t.Fatalf("grant village admin: %v", err)
+	}
+
+	// 被内容过滤拦下的帖子不进入村落审核队列，村落管理员也不能自行放行
+	held := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "有事加微信"}))
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts/pending", village.ID), alice.Token, nil)); n != 0 {
+		t.Fatalf("filter-held post is in the village queue: %d", n)
+	}
+	app.expect(http.StatusConflict, apperr.CodeConflict, http.MethodPost, path("/earth-village/%d/post/%d/approve", village.ID, held), alice.Token, nil)
+	app.expect(http.StatusConflict, apperr.CodeConflict, http.MethodPost, path("/earth-village/%d/post/%d/reject", village.ID, held), alice.Token, gin.H{})
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), alice.Token, nil)); n != 0 {
+		t.Fatalf("filter-held post is visible: %d", n)
+	}
+
+	// 平台管理员审核通过后正常展示
+	var reports struct {
+		List []struct {
+			ID uint `json:"id"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/reports"), root.Token, nil), &reports)
+	if len(reports.List) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports.List))
+	}
+	app.ok(http.MethodPost, path("/admin/reports/%d/resolve", reports.List[0].ID), root.Token, gin.H{"action": "dismiss"})
+	if n := listLen(t, app.ok(http.MethodGet, path("/earth-village/%d/posts", village.ID), alice.Token, nil)); n != 1 {
+		t.Fatalf("released post is not visible: %d", n)
+	}
+}
+
+func TestReportAnonymousPostHidesAuthor(t *testing.T) {
+	app := newTestApp(t)
+	root := app.admin("root")
+	alice := app.register("alice")
+	bob := app.register("bob")
+	village := app.village("树洞")
+
+	postID := idOf(t, app.ok(http.MethodPost, path("/earth-village/%d/post", village.ID), alice.Token, gin.H{"content": "心里话", "anonymous": true}))
+	app.ok(http.MethodPost, path("/report"), bob.Token, gin.H{"targetType": "post", "targetId": postID, "reason": "abuse"})
+
+	// 举报人查看自己的举报时不能得知匿名帖子的作者
+	var mine struct {
+		List []map[string]interface{} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/reports"), bob.Token, nil), &mine)
+	if len(mine.List) != 1 {
+		t.Fatalf("got %d reports, want 1", len(mine.List))
+	}
+	if _, ok := mine.List[0]["target_owner_id"]; ok {
+		t.Fatalf("report leaked anonymous author: %v", mine.List[0])
+	}
+
+	// 管理后台仍可看到被举报内容的作者
+	var queue struct {
+		List []struct {
+			ID            uint `json:"id"`
+			TargetOwnerID uint `json:"target_owner_id"`
+		} `json:"list"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/reports"), root.Token, nil), &queue)
+	if len(queue.List) != 1 || queue.List[0].TargetOwnerID != alice.ID {
+		t.Fatalf("admin reports = %+v, want owner %d", queue.List, alice.ID)
+	}
+	var detail struct {
+		Report struct {
+			TargetOwnerID uint `json:"target_owner_id"`
+		} `json:"report"`
+	}
+	decode(t, app.ok(http.MethodGet, path("/admin/reports/%d", queue.List[0].ID), root.Token, nil), &detail)
+	if detail.Report.TargetOwnerID != alice.ID {
+		t.Fatalf("admin report owner = %d, want %d", detail.Report.TargetOwnerID, alice.ID)
+	}
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"strings"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// QuestionDocument 由问题生成检索文档
+func QuestionDocument(q model.Question) Document {
+	return Document{
+		Type:        TypeQuestion,
+		ID:          q.ID,
+		Title:       q.Title,
+		Content:     q.Content + " " + strings.ReplaceAll(q.Tags, ",", " "),
+		PublishedAt: q.CreatedAt,
+	}
+}
+
+// AnswerDocument 由回答生成检索文档，questionTitle为所属问题标题
+func AnswerDocument(a model.Answer, questionTitle string) Document {
+	return Document{
+		Type:        TypeAnswer,
+		ID:          a.ID,
+		Title:       questionTitle,
+		Content:     a.Content,
+		PublishedAt: a.CreatedAt,
+	}
+}
+
+// NoteDocument 由笔记生成检索文档
+func NoteDocument(n model.Note) Document {
+	return Document{
+		Type:        TypeNote,
+		ID:          n.ID,
+		Title:       n.Title,
+		Content:     n.Content + " " + n.Category + " " + strings.ReplaceAll(n.Tags, ",", " "),
+		PublishedAt: n.CreatedAt,
+	}
+}
+
+// PostDocument 由帖子生成检索文档，文档中不包含作者信息，匿名帖子同样可以被检索
+func PostDocument(p model.Post) Document {
+	return Document{
+		Type:        TypePost,
+		ID:          p.ID,
+		Content:     p.Content,
+		PublishedAt: p.CreatedAt,
+	}
+}
+
+// UserDocument 由用户生成检索文档
+func UserDocument(u model.User) Document {
+	return Document{
+		Type:        TypeUser,
+		ID:          u.ID,
+		Title:       u.Username,
+		Content:     u.Bio,
+		PublishedAt: u.CreatedAt,
+	}
+}
+
+// VillageDocument 由村落生成检索文档
+func VillageDocument(v model.Village) Document {
+	return Document{
+		Type:        TypeVillage,
+		ID:          v.ID,
+		Title:       v.Name,
+		Content:     v.Description + " " + v.Category,
+		PublishedAt: v.CreatedAt,
+	}
+}
+
+// Rebuild 从数据库重建全部检索文档
+func Rebuild(db *gorm.DB, idx Indexer) error {
+	const batchSize = 200
+
+	var questions []model.Question
+	titles := make(map[uint]string)
+	if err := db.Where("status = ?", 1).FindInBatches(&questions, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, q := range questions {
+			titles[q.ID] = q.Title
+			if err := idx.Index(QuestionDocument(q)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var answers []model.Answer
+	if err := db.Where("status = ?", 1).FindInBatches(&answers, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, a := range answers {
+			if err := idx.Index(AnswerDocument(a, titles[a.QuestionID])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var notes []model.Note
+	if err := db.Where("status = ?", 1).FindInBatches(&notes, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, n := range notes {
+			if err := idx.Index(NoteDocument(n)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var posts []model.Post
+	if err := db.Where("status = ?", model.PostStatusNormal).FindInBatches(&posts, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, p := range posts {
+			if err := idx.Index(PostDocument(p)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var users []model.User
+	if err := db.Where("status = ?", 1).FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, u := range users {
+			if err := idx.Index(UserDocument(u)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		return err
+	}
+
+	var villages []model.Village
+	return db.Where("status = ?", 1).FindInBatches(&villages, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, v := range villages {
+			if err := idx.Index(VillageDocument(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
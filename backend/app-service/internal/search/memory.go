@@ -0,0 +1,180 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25参数及标题权重
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	titleWeight = 2
+)
+
+type docKey struct {
+	docType string
+	id      uint
+}
+
+type memoryDoc struct {
+	doc    Document
+	terms  map[string]int // 词频，标题中的词按titleWeight计
+	length int
+}
+
+// MemoryIndexer 基于倒排索引的内存检索引擎，用于单机部署和测试
+type MemoryIndexer struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*memoryDoc
+	postings map[string]map[docKey]struct{}
+	totalLen int
+}
+
+// NewMemoryIndexer 创建内存检索引擎
+func NewMemoryIndexer() *MemoryIndexer {
+	return &MemoryIndexer{
+		docs:     make(map[docKey]*memoryDoc),
+		postings: make(map[string]map[docKey]struct{}),
+	}
+}
+
+func (m *MemoryIndexer) Index(doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := docKey{doc.Type, doc.ID}
+	m.remove(key)
+
+	d := &memoryDoc{doc: doc, terms: make(map[string]int)}
+	for _, t := range Tokenize(doc.Title) {
+		d.terms[t] += titleWeight
+		d.length += titleWeight
+	}
+	for _, t := range Tokenize(doc.Content) {
+		d.terms[t]++
+		d.length++
+	}
+
+	m.docs[key] = d
+	m.totalLen += d.length
+	for t := range d.terms {
+		if m.postings[t] == nil {
+			m.postings[t] = make(map[docKey]struct{})
+		}
+		m.postings[t][key] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryIndexer) Delete(docType string, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(docKey{docType, id})
+	return nil
+}
+
+// remove 删除文档，调用方需持有写锁
+func (m *MemoryIndexer) remove(key docKey) {
+	d, ok := m.docs[key]
+	if !ok {
+		return
+	}
+	for t := range d.terms {
+		delete(m.postings[t], key)
+		if len(m.postings[t]) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	m.totalLen -= d.length
+	delete(m.docs, key)
+}
+
+func (m *MemoryIndexer) Search(q Query) (*Result, error) {
+	q = q.normalize()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := &Result{Hits: []Hit{}, Facets: make(map[string]int64)}
+	if len(m.docs) == 0 {
+		return result, nil
+	}
+
+	// 查询词去重，任一词命中即视为匹配
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, t := range Tokenize(q.Keyword) {
+		if !seen[t] {
+			seen[t] = true
+			tokens = append(tokens, t)
+		}
+	}
+
+	n := float64(len(m.docs))
+	avgLen := float64(m.totalLen) / n
+	scores := make(map[docKey]float64)
+	for _, t := range tokens {
+		postings := m.postings[t]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for key := range postings {
+			d := m.docs[key]
+			tf := float64(d.terms[t])
+			scores[key] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(d.length)/avgLen))
+		}
+	}
+
+	allowed := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		allowed[t] = true
+	}
+
+	var matched []docKey
+	for key := range scores {
+		result.Facets[key.docType]++
+		if len(allowed) == 0 || allowed[key.docType] {
+			matched = append(matched, key)
+		}
+	}
+	result.Total = int64(len(matched))
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		da, db := m.docs[a].doc, m.docs[b].doc
+		if !da.PublishedAt.Equal(db.PublishedAt) {
+			return da.PublishedAt.After(db.PublishedAt)
+		}
+		if a.docType != b.docType {
+			return a.docType < b.docType
+		}
+		return a.id > b.id
+	})
+
+	offset := (q.Page - 1) * q.PageSize
+	if offset >= len(matched) {
+		return result, nil
+	}
+	end := min(offset+q.PageSize, len(matched))
+
+	terms := Terms(q.Keyword)
+	for _, key := range matched[offset:end] {
+		result.Hits = append(result.Hits, newHit(m.docs[key].doc, scores[key], terms))
+	}
+	return result, nil
+}
+
+func (m *MemoryIndexer) Count() (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return int64(len(m.docs)), nil
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLIndexer 基于MySQL FULLTEXT索引（ngram解析器）的检索引擎，
+// 文档统一存储在search_documents表中
+type MySQLIndexer struct {
+	db *gorm.DB
+}
+
+// NewMySQLIndexer 创建MySQL检索引擎
+func NewMySQLIndexer(db *gorm.DB) *MySQLIndexer {
+	return &MySQLIndexer{db: db}
+}
+
+func (m *MySQLIndexer) Index(doc Document) error {
+	row := model.SearchDocument{
+		DocType:     doc.Type,
+		DocID:       doc.ID,
+		Title:       doc.Title,
+		Content:     doc.Content,
+		PublishedAt: doc.PublishedAt,
+	}
+	return m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "doc_type"}, {Name: "doc_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "content", "published_at", "updated_at"}),
+	}).Create(&row).Error
+}
+
+func (m *MySQLIndexer) Delete(docType string, id uint) error {
+	return m.db.Where("doc_type = ? AND doc_id = ?", docType, id).Delete(&model.SearchDocument{}).Error
+}
+
+func (m *MySQLIndexer) Search(q Query) (*Result, error) {
+	q = q.normalize()
+	result := &Result{Hits: []Hit{}, Facets: make(map[string]int64)}
+
+	const match = "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	// 标题命中额外加权
+	const score = "MATCH(title) AGAINST (? IN NATURAL LANGUAGE MODE) * 2 + " + match
+
+	var facets []struct {
+		DocType string
+		Total   int64
+	}
+	if err := m.db.Model(&model.SearchDocument{}).
+		Select("doc_type, COUNT(*) AS total").
+		Where(match, q.Keyword).
+		Group("doc_type").
+		Scan(&facets).Error; err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		allowed[t] = true
+	}
+	for _, f := range facets {
+		result.Facets[f.DocType] = f.Total
+		if len(allowed) == 0 || allowed[f.DocType] {
+			result.Total += f.Total
+		}
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	query := m.db.Model(&model.SearchDocument{}).
+		Select("doc_type, doc_id, title, content, published_at, "+score+" AS score", q.Keyword, q.Keyword).
+		Where(match, q.Keyword)
+	if len(q.Types) > 0 {
+		query = query.Where("doc_type IN ?", q.Types)
+	}
+
+	var rows []struct {
+		model.SearchDocument
+		Score float64
+	}
+	if err := query.Order("score DESC, published_at DESC, id DESC").
+		Limit(q.PageSize).Offset((q.Page - 1) * q.PageSize).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	terms := Terms(q.Keyword)
+	for _, row := range rows {
+		doc := Document{
+			Type:        row.DocType,
+			ID:          row.DocID,
+			Title:       row.Title,
+			Content:     row.Content,
+			PublishedAt: row.PublishedAt,
+		}
+		result.Hits = append(result.Hits, newHit(doc, row.Score, terms))
+	}
+	return result, nil
+}
+
+func (m *MySQLIndexer) Count() (int64, error) {
+	var count int64
+	err := m.db.Model(&model.SearchDocument{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,116 @@
+// Package search 提供跨内容类型的全文检索能力
+package search
+
+import (
+	"time"
+)
+
+// 可检索的内容类型
+const (
+	TypeQuestion = "question"
+	TypeAnswer   = "answer"
+	TypeNote     = "note"
+	TypePost     = "post"
+	TypeUser     = "user"
+	TypeVillage  = "village"
+)
+
+// Types 全部可检索的内容类型
+var Types = []string{TypeQuestion, TypeAnswer, TypeNote, TypePost, TypeUser, TypeVillage}
+
+// Document 待索引的文档
+type Document struct {
+	Type        string
+	ID          uint
+	Title       string
+	Content     string
+	PublishedAt time.Time
+}
+
+// Query 检索条件，Types为空表示检索全部类型
+type Query struct {
+	Keyword  string
+	Types    []string
+	Page     int
+	PageSize int
+}
+
+// Hit 单条检索结果，Title和Content为已转义并标记高亮的文本
+type Hit struct {
+	Type        string    `json:"type"`
+	ID          uint      `json:"id"`
+	Score       float64   `json:"score"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Result 检索结果，Facets为不受类型筛选影响的各类型命中数
+type Result struct {
+	Hits   []Hit            `json:"list"`
+	Total  int64            `json:"total"`
+	Facets map[string]int64 `json:"facets"`
+}
+
+// Indexer 检索引擎接口
+type Indexer interface {
+	// Index 新增或更新文档
+	Index(doc Document) error
+	// Delete 删除文档，文档不存在时不报错
+	Delete(docType string, id uint) error
+	// Search 按相关度排序检索文档
+	Search(q Query) (*Result, error)
+	// Count 返回已索引的文档数
+	Count() (int64, error)
+}
+
+// snippetLength 检索结果中正文摘要的最大长度（字符数）
+const snippetLength = 120
+
+var indexer Indexer = NewMemoryIndexer()
+
+// Init 设置全局检索引擎
+func Init(idx Indexer) {
+	indexer = idx
+}
+
+// GetIndexer 获取全局检索引擎，未初始化时使用内存索引
+func GetIndexer() Indexer {
+	return indexer
+}
+
+// IsValidType 判断是否为可检索的内容类型
+func IsValidType(docType string) bool {
+	for _, t := range Types {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize 修正分页参数
+func (q Query) normalize() Query {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = 10
+	}
+	if q.PageSize > 50 {
+		q.PageSize = 50
+	}
+	return q
+}
+
+// newHit 生成带高亮的检索结果
+func newHit(doc Document, score float64, terms []string) Hit {
+	return Hit{
+		Type:        doc.Type,
+		ID:          doc.ID,
+		Score:       score,
+		Title:       Highlight(doc.Title, terms, 0),
+		Content:     Highlight(doc.Content, terms, snippetLength),
+		PublishedAt: doc.PublishedAt,
+	}
+}
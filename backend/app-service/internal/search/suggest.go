@@ -0,0 +1,81 @@
+package search
+
+import (
+	"strings"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Suggestion 搜索联想词
+type Suggestion struct {
+	Text string `json:"text"`
+	Type string `json:"type"`         // question/tag/user
+	ID   uint   `json:"id,omitempty"` // 问题或用户ID
+}
+
+// Suggest 根据前缀返回问题标题、标签和用户名的联想词
+func Suggest(db *gorm.DB, prefix string, limit int) ([]Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	suggestions := []Suggestion{}
+	if prefix == "" {
+		return suggestions, nil
+	}
+	pattern := escapeLike(prefix) + "%"
+
+	// 问题标题，热门问题优先
+	var questions []model.Question
+	if err := db.Select("id, title").
+		Where("status = ? AND title LIKE ?", 1, pattern).
+		Order("likes DESC, views DESC").
+		Limit(limit).
+		Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	for _, q := range questions {
+		suggestions = append(suggestions, Suggestion{Text: q.Title, Type: "question", ID: q.ID})
+	}
+
+	// 标签以逗号分隔存储，取出后再按前缀过滤
+	var tagRows []string
+	if err := db.Model(&model.Question{}).
+		Where("status = ? AND tags LIKE ?", 1, "%"+escapeLike(prefix)+"%").
+		Limit(limit*5).
+		Pluck("tags", &tagRows).Error; err != nil {
+		return nil, err
+	}
+	lowerPrefix := strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	tags := 0
+	for _, row := range tagRows {
+		for _, tag := range strings.Split(row, ",") {
+			tag = strings.TrimSpace(tag)
+			if tags >= limit || tag == "" || seen[tag] || !strings.HasPrefix(strings.ToLower(tag), lowerPrefix) {
+				continue
+			}
+			seen[tag] = true
+			tags++
+			suggestions = append(suggestions, Suggestion{Text: tag, Type: "tag"})
+		}
+	}
+
+	var users []model.User
+	if err := db.Select("id, username").
+		Where("status = ? AND username LIKE ?", 1, pattern).
+		Order("username ASC").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		suggestions = append(suggestions, Suggestion{Text: u.Username, Type: "user", ID: u.ID})
+	}
+
+	return suggestions, nil
+}
+
+// escapeLike 转义LIKE通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
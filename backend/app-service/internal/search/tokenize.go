@@ -0,0 +1,139 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tokenize 分词：拉丁字母和数字按单词切分并转小写，中日韩文字按二元组（bigram）切分，
+// 与MySQL ngram解析器默认的ngram_token_size=2保持一致
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// Terms 将搜索关键词按空白切分为用于高亮的词
+func Terms(keyword string) []string {
+	var terms []string
+	for _, f := range strings.Fields(keyword) {
+		terms = append(terms, strings.ToLower(f))
+	}
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// Highlight 对文本做HTML转义并用<em>标记命中的关键词。
+// maxRunes大于0时截取以首个命中位置为中心的摘要
+func Highlight(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 找出所有命中区间并合并重叠部分
+	type span struct{ start, end int }
+	var spans []span
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				spans = append(spans, span{i, i + len(t)})
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	from, to := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if len(merged) > 0 {
+			from = merged[0].start - maxRunes/4
+		}
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxRunes
+		if to > len(runes) {
+			to = len(runes)
+			from = to - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("...")
+	}
+	pos := from
+	for _, s := range merged {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</em>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-egg/app-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Trend 热搜关键词，Count为窗口内搜索该词的人数，Previous为上一个等长窗口内的人数
+type Trend struct {
+	Keyword  string  `json:"keyword"`
+	Count    int64   `json:"count"`
+	Previous int64   `json:"previous"`
+	Growth   float64 `json:"growth"`
+}
+
+// TrendWindows 支持的热搜统计窗口
+var TrendWindows = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// trendingTTL 热搜结果缓存时间
+const trendingTTL = time.Minute
+
+type trendingEntry struct {
+	trends    []Trend
+	expiresAt time.Time
+}
+
+var (
+	trendingMu    sync.Mutex
+	trendingCache = make(map[string]trendingEntry)
+)
+
+// NormalizeKeyword 归一化搜索关键词：去首尾空白、合并连续空白、转小写并限制长度
+func NormalizeKeyword(keyword string) string {
+	keyword = strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+	if runes := []rune(keyword); len(runes) > 100 {
+		keyword = string(runes[:100])
+	}
+	return keyword
+}
+
+// Trending 统计滑动窗口内的热搜关键词，按搜索人数排序，结果缓存trendingTTL
+func Trending(db *gorm.DB, window time.Duration, limit int) ([]Trend, error) {
+	key := fmt.Sprintf("%d:%d", window, limit)
+
+	trendingMu.Lock()
+	if entry, ok := trendingCache[key]; ok && time.Now().Before(entry.expiresAt) {
+		trendingMu.Unlock()
+		return entry.trends, nil
+	}
+	trendingMu.Unlock()
+
+	now := time.Now()
+	start := now.Add(-window)
+
+	// 按人数而不是次数统计，避免单个用户刷词
+	var current []struct {
+		Keyword string
+		Count   int64
+	}
+	if err := db.Model(&model.SearchLog{}).
+		Select("keyword, COUNT(DISTINCT user_id) AS count").
+		Where("created_at >= ?", start).
+		Group("keyword").
+		Order("count DESC, keyword ASC").
+		Limit(limit).
+		Scan(&current).Error; err != nil {
+		return nil, err
+	}
+
+	trends := make([]Trend, 0, len(current))
+	if len(current) > 0 {
+		keywords := make([]string, 0, len(current))
+		for _, row := range current {
+			keywords = append(keywords, row.Keyword)
+		}
+
+		var previous []struct {
+			Keyword string
+			Count   int64
+		}
+		if err := db.Model(&model.SearchLog{}).
+			Select("keyword, COUNT(DISTINCT user_id) AS count").
+			Where("created_at >= ? AND created_at < ? AND keyword IN ?", start.Add(-window), start, keywords).
+			Group("keyword").
+			Scan(&previous).Error; err != nil {
+			return nil, err
+		}
+		prev := make(map[string]int64, len(previous))
+		for _, row := range previous {
+			prev[row.Keyword] = row.Count
+		}
+
+		for _, row := range current {
+			base := prev[row.Keyword]
+			trends = append(trends, Trend{
+				Keyword:  row.Keyword,
+				Count:    row.Count,
+				Previous: base,
+				Growth:   float64(row.Count-base) / float64(max(base, 1)),
+			})
+		}
+	}
+
+	trendingMu.Lock()
+	trendingCache[key] = trendingEntry{trends: trends, expiresAt: now.Add(trendingTTL)}
+	trendingMu.Unlock()
+
+	return trends, nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/repository"
+)
+
+// GroupChat 群聊列表项
+type GroupChat struct {
+	model.Chat
+	MemberCount int64 `json:"member_count"`
+	Unread      int64 `json:"unread"`
+}
+
+// ChatService 私聊、群聊和村落聊天室
+type ChatService struct {
+	repos *repository.Repositories
+}
+
+func NewChatService(repos *repository.Repositories) *ChatService {
+	return &ChatService{repos: repos}
+}
+
+// IsGroupChat 判断会话是否为多人会话
+func IsGroupChat(chat model.Chat) bool {
+	return chat.Type == model.ChatTypeGroup || chat.Type == model.ChatTypeVillage
+}
+
+// Send 发送私聊消息，会话不存在时创建
+func (s *ChatService) Send(senderID, receiverID uint, content, msgType string) (model.Message, error) {
+	chat, err := s.repos.Chats.FindDirect(senderID, receiverID)
+	if errors.Is(err, repository.ErrNotFound) {
+		chat = model.Chat{
+			UserID:     senderID,
+			ReceiverID: receiverID,
+			Type:       "user",
+		}
+		err = s.repos.Chats.Create(&chat)
+	}
+	if err != nil {
+		return model.Message{}, err
+	}
+
+	message := model.Message{
+		ChatID:   chat.ID,
+		SenderID: senderID,
+		Content:  content,
+		Type:     msgType,
+		Status:   1,
+	}
+	if err := s.repos.Chats.CreateMessage(&message); err != nil {
+		return message, err
+	}
+
+	// 更新会话最后消息，失败不影响消息发送
+	s.repos.Chats.SetLastMessage(chat.ID, content)
+	return message, nil
+}
+
+// History 查询会话消息，用户须为会话参与者，群聊以成员表为准
+func (s *ChatService) History(chatID, userID uint, p pagination.Params) ([]model.Message, pagination.Page, error) {
+	chat, err := s.repos.Chats.FindByID(chatID)
+	if err != nil {
+		return nil, pagination.Page{}, notFound(err, "聊天不存在")
+	}
+
+	isParticipant := chat.UserID == userID || chat.ReceiverID == userID
+	if IsGroupChat(chat) {
+		_, err := s.repos.Chats.FindMember(chat.ID, userID)
+		isParticipant = err == nil
+	}
+	if !isParticipant {
+		return nil, pagination.Page{}, apperr.Forbidden("无权查看此聊天")
+	}
+
+	return s.repos.Chats.ListMessages(chat.ID, p)
+}
+
+// CreateGroup 创建群聊，创建者为群主，不存在的用户被忽略
+func (s *ChatService) CreateGroup(ownerID uint, name string, memberIDs []uint) (model.Chat, error) {
+	memberIDs, err := s.repos.Users.ExistingIDs(memberIDs, ownerID)
+	if err != nil {
+		return model.Chat{}, err
+	}
+
+	chat := model.Chat{
+		UserID: ownerID,
+		Type:   model.ChatTypeGroup,
+		Name:   name,
+	}
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Chats.Create(&chat); err != nil {
+			return err
+		}
+		if err := addChatMember(tx, &chat, ownerID, 1); err != nil {
+			return err
+		}
+		for _, memberID := range memberIDs {
+			if err := addChatMember(tx, &chat, memberID, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return chat, err
+}
+
+// Groups 用户加入的群聊，按最近更新时间倒序，包含成员数和未读数
+func (s *ChatService) Groups(userID uint) ([]GroupChat, error) {
+	members, err := s.repos.Chats.Memberships(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastRead := make(map[uint]uint, len(members))
+	chatIDs := make([]uint, 0, len(members))
+	for _, m := range members {
+		lastRead[m.ChatID] = m.LastReadMessageID
+		chatIDs = append(chatIDs, m.ChatID)
+	}
+
+	chats, err := s.repos.Chats.FindByIDs(chatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]GroupChat, 0, len(chats))
+	for _, chat := range chats {
+		info := GroupChat{Chat: chat}
+		if info.MemberCount, err = s.repos.Chats.CountMembers(chat.ID); err != nil {
+			return nil, err
+		}
+		if info.Unread, err = s.repos.Chats.CountUnread(chat.ID, userID, lastRead[chat.ID]); err != nil {
+			return nil, err
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// Membership 校验用户是否为群聊成员，返回群聊和成员记录
+func (s *ChatService) Membership(chatID, userID uint) (model.Chat, model.ChatMember, error) {
+	chat, err := s.repos.Chats.FindByID(chatID)
+	if err == nil && !IsGroupChat(chat) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return chat, model.ChatMember{}, notFound(err, "群聊不存在")
+	}
+
+	member, err := s.repos.Chats.FindMember(chat.ID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return chat, member, apperr.Forbidden("不是群聊成员")
+	}
+	return chat, member, err
+}
+
+// Members 按加入时间查询群聊成员
+func (s *ChatService) Members(chatID uint) ([]model.ChatMember, error) {
+	return s.repos.Chats.ListMembers(chatID)
+}
+
+// Invite 邀请用户加入群聊，返回实际加入的用户ID。
+// 村落聊天室的成员与村落成员保持一致，不支持邀请
+func (s *ChatService) Invite(chat model.Chat, userIDs []uint) ([]uint, error) {
+	if chat.Type != model.ChatTypeGroup {
+		return nil, apperr.Forbidden("该聊天室不支持邀请成员")
+	}
+
+	userIDs, err := s.repos.Chats.NonMembers(chat.ID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		for _, id := range userIDs {
+			if err := addChatMember(tx, &chat, id, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return userIDs, err
+}
+
+// Leave 退出群聊
+func (s *ChatService) Leave(chat model.Chat, member model.ChatMember) error {
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		return removeChatMember(tx, &chat, member)
+	})
+}
+
+// SendToGroup 发送群聊消息，自己发送的消息视为已读
+func (s *ChatService) SendToGroup(chat model.Chat, member model.ChatMember, content, msgType string) (model.Message, error) {
+	message := model.Message{
+		ChatID:   chat.ID,
+		SenderID: member.UserID,
+		Content:  content,
+		Type:     msgType,
+		Status:   1,
+	}
+
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Chats.CreateMessage(&message); err != nil {
+			return err
+		}
+		if err := tx.Chats.SetLastMessage(chat.ID, content); err != nil {
+			return err
+		}
+		return tx.Chats.SetLastRead(member.ID, message.ID)
+	})
+	return message, err
+}
+
+// MarkRead 将群聊消息全部标记为已读
+func (s *ChatService) MarkRead(chat model.Chat, member model.ChatMember) error {
+	lastID, err := s.repos.Chats.LastMessageID(chat.ID)
+	if err != nil {
+		return err
+	}
+	if lastID <= member.LastReadMessageID {
+		return nil
+	}
+	return s.repos.Chats.SetLastRead(member.ID, lastID)
+}
+
+// JoinVillageChat 加入村落聊天室，聊天室在首次加入时创建，仅村落成员可加入
+func (s *ChatService) JoinVillageChat(villageID, userID uint) (model.Chat, error) {
+	village, err := s.repos.Villages.FindOpen(villageID)
+	if err != nil {
+		return model.Chat{}, notFound(err, "村落不存在")
+	}
+
+	if _, err := s.repos.Villages.FindMember(village.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Chat{}, apperr.Forbidden("请先加入村落")
+		}
+		return model.Chat{}, err
+	}
+
+	chat := model.Chat{
+		UserID:    userID,
+		Type:      model.ChatTypeVillage,
+		Name:      village.Name,
+		VillageID: &village.ID,
+	}
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Chats.FindOrCreateVillageChat(&chat); err != nil {
+			return err
+		}
+		_, err := tx.Chats.FindMember(chat.ID, userID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return addChatMember(tx, &chat, userID, 0)
+	})
+	return chat, err
+}
+
+// addChatMember 添加群聊成员并发送系统消息。
+// 新成员可以查看历史消息，但加入前的消息不计入未读
+func addChatMember(tx *repository.Repositories, chat *model.Chat, userID uint, role int) error {
+	lastID, err := tx.Chats.LastMessageID(chat.ID)
+	if err != nil {
+		return err
+	}
+
+	member := model.ChatMember{
+		ChatID:            chat.ID,
+		UserID:            userID,
+		Role:              role,
+		LastReadMessageID: lastID,
+	}
+	if err := tx.Chats.CreateMember(&member); err != nil {
+		return err
+	}
+
+	return createSystemMessage(tx, chat, userID, "加入了群聊")
+}
+
+// removeChatMember 移除群聊成员并发送系统消息，群主退出时由最早加入的成员接任
+func removeChatMember(tx *repository.Repositories, chat *model.Chat, member model.ChatMember) error {
+	if err := tx.Chats.DeleteMember(member); err != nil {
+		return err
+	}
+
+	if member.Role == 1 {
+		next, err := tx.Chats.EarliestMember(chat.ID)
+		if err == nil {
+			err = tx.Chats.SetMemberRole(next.ID, 1)
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+
+	return createSystemMessage(tx, chat, member.UserID, "退出了群聊")
+}
+
+// createSystemMessage 生成成员变动等系统消息
+func createSystemMessage(tx *repository.Repositories, chat *model.Chat, userID uint, action string) error {
+	user, _ := tx.Users.FindByID(userID)
+
+	message := model.Message{
+		ChatID:   chat.ID,
+		SenderID: userID,
+		Content:  user.Username + " " + action,
+		Type:     "system",
+		Status:   1,
+	}
+	if err := tx.Chats.CreateMessage(&message); err != nil {
+		return err
+	}
+	chat.LastMessage = message.Content
+	return tx.Chats.SetLastMessage(chat.ID, message.Content)
+}
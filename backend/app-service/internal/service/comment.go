@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/repository"
+)
+
+// CommentService 评论、回复和点赞
+type CommentService struct {
+	repos *repository.Repositories
+}
+
+func NewCommentService(repos *repository.Repositories) *CommentService {
+	return &CommentService{repos: repos}
+}
+
+// List 查询已发布的评论
+func (s *CommentService) List(filter repository.CommentFilter, p pagination.Params) ([]model.Comment, pagination.Page, error) {
+	return s.repos.Comments.List(filter, p)
+}
+
+// Find 查询任意状态的评论
+func (s *CommentService) Find(id uint) (model.Comment, error) {
+	comment, err := s.repos.Comments.FindByID(id)
+	return comment, notFound(err, "评论不存在")
+}
+
+// View 查询评论，包含作者和提及
+func (s *CommentService) View(id uint) (model.Comment, error) {
+	comment, err := s.repos.Comments.FindWithAuthor(id)
+	return comment, notFound(err, "评论不存在")
+}
+
+// Create 发布评论，创建后重新加载作者信息。待审核的评论提交到审核队列，
+// 直接发布的评论通知相关用户并计入被评论内容的热度
+func (s *CommentService) Create(comment *model.Comment, review Review) error {
+	mentions, err := resolveMentions(s.repos, comment.AuthorID, comment.Content)
+	if err != nil {
+		return err
+	}
+	comment.Mentions = mentions
+	comment.Status = review.status()
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Comments.Create(comment); err != nil {
+			return err
+		}
+		return holdForReview(tx, review, model.TargetTypeComment, comment.ID, comment.AuthorID, comment.Content)
+	})
+	if err != nil {
+		return err
+	}
+
+	if created, err := s.repos.Comments.FindWithAuthor(comment.ID); err == nil {
+		*comment = created
+	}
+	if !review.Hold {
+		notifyComment(s.repos, *comment)
+		bumpHotScore(s.repos, comment.TargetType, comment.TargetID, ranking.WeightComment)
+	}
+	return nil
+}
+
+// Reply 回复评论，回复与父评论属于同一目标
+func (s *CommentService) Reply(parent model.Comment, reply *model.Comment, review Review) error {
+	parentID := parent.ID
+	reply.TargetID = parent.TargetID
+	reply.TargetType = parent.TargetType
+	reply.ParentID = &parentID
+	return s.Create(reply, review)
+}
+
+// Like 点赞评论并通知评论作者
+func (s *CommentService) Like(id, userID uint) error {
+	comment, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+
+	liked, err := s.repos.Comments.HasLiked(comment.ID, userID)
+	if err != nil {
+		return err
+	}
+	if liked {
+		return apperr.Conflict("已经点赞过了").WithCode(apperr.CodeAlreadyExists)
+	}
+
+	if err := s.repos.Comments.AddLike(comment.ID, userID); err != nil {
+		return err
+	}
+
+	notify(s.repos, model.Notification{
+		UserID:     comment.AuthorID,
+		ActorID:    userID,
+		Type:       model.NotifyLike,
+		TargetType: model.TargetTypeComment,
+		TargetID:   comment.ID,
+		Content:    comment.Content,
+	})
+	return nil
+}
+
+// Unlike 取消点赞评论
+func (s *CommentService) Unlike(id, userID uint) error {
+	comment, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repos.Comments.RemoveLike(comment.ID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.Conflict("还没有点赞")
+	}
+	return err
+}
+
+// Delete 删除评论，仅作者本人可操作。已发布的评论曾计入热度，删除时扣除
+func (s *CommentService) Delete(id, userID uint) error {
+	comment, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID {
+		return apperr.Forbidden("无权删除此评论")
+	}
+	if err := s.repos.Comments.Delete(comment.ID); err != nil {
+		return err
+	}
+
+	if comment.Status == model.ContentStatusNormal {
+		bumpHotScore(s.repos, comment.TargetType, comment.TargetID, -ranking.WeightComment)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"log"
+
+	"ai-egg/app-service/internal/embedding"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+)
+
+// bumpHotScore 内容产生互动时更新热度，失败只记录日志
+func bumpHotScore(repos *repository.Repositories, targetType string, id uint, weight float64) {
+	if err := repos.HotScores.Bump(targetType, id, weight); err != nil {
+		log.Printf("Failed to update hot score of %s %d: %v", targetType, id, err)
+	}
+}
+
+// indexDocument 同步更新检索索引，失败时只记录日志不影响主流程
+func indexDocument(doc search.Document) {
+	if err := search.GetIndexer().Index(doc); err != nil {
+		log.Printf("Failed to index %s %d: %v", doc.Type, doc.ID, err)
+	}
+}
+
+// removeDocument 从检索索引中删除文档
+func removeDocument(docType string, id uint) {
+	if err := search.GetIndexer().Delete(docType, id); err != nil {
+		log.Printf("Failed to remove %s %d from index: %v", docType, id, err)
+	}
+}
+
+// embed 生成文本的语义向量，向量服务未启用或失败时返回nil
+func embed(text string) []float32 {
+	store := embedding.GetStore()
+	if store == nil {
+		return nil
+	}
+	vec, err := store.Embed(text)
+	if err != nil {
+		log.Printf("Failed to embed text: %v", err)
+		return nil
+	}
+	return vec
+}
+
+// saveEmbedding 保存内容向量，失败时只记录日志
+func saveEmbedding(targetType string, id uint, vec []float32) {
+	store := embedding.GetStore()
+	if store == nil || vec == nil {
+		return
+	}
+	if err := store.Save(targetType, id, vec); err != nil {
+		log.Printf("Failed to save embedding for %s %d: %v", targetType, id, err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+
+	"ai-egg/app-service/internal/mention"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+)
+
+// resolveMentions 解析文本中的@用户名并匹配用户，不存在的用户名忽略。
+// 返回的记录赋值给内容的Mentions，随内容一起创建
+func resolveMentions(repos *repository.Repositories, authorID uint, text string) ([]model.Mention, error) {
+	spans := mention.Parse(text)
+	if len(spans) == 0 {
+		return nil, nil
+	}
+
+	users, err := repos.Users.FindByUsernames(mention.Usernames(spans))
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make(map[string]uint, len(users))
+	for _, u := range users {
+		userIDs[strings.ToLower(u.Username)] = u.ID
+	}
+
+	var mentions []model.Mention
+	for _, s := range spans {
+		userID, ok := userIDs[strings.ToLower(s.Username)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, model.Mention{
+			UserID:   userID,
+			AuthorID: authorID,
+			Username: s.Username,
+			Start:    s.Start,
+			End:      s.End,
+		})
+	}
+	return mentions, nil
+}
+
+// replaceMentions 编辑内容后重建提及记录，返回全部提及和本次新增的被提及用户的提及
+func replaceMentions(tx *repository.Repositories, sourceType string, sourceID, authorID uint, text string) (mentions, added []model.Mention, err error) {
+	previous, err := tx.Mentions.ListBySource(sourceType, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mentions, err = resolveMentions(tx, authorID, text)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range mentions {
+		mentions[i].SourceType = sourceType
+		mentions[i].SourceID = sourceID
+	}
+	if err := tx.Mentions.Replace(sourceType, sourceID, mentions); err != nil {
+		return nil, nil, err
+	}
+
+	mentioned := make(map[uint]bool, len(previous))
+	for _, m := range previous {
+		mentioned[m.UserID] = true
+	}
+	for _, m := range mentions {
+		if !mentioned[m.UserID] {
+			added = append(added, m)
+		}
+	}
+	return mentions, added, nil
+}
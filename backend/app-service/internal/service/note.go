@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/embedding"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+)
+
+// NoteService 笔记
+type NoteService struct {
+	repos *repository.Repositories
+}
+
+func NewNoteService(repos *repository.Repositories) *NoteService {
+	return &NoteService{repos: repos}
+}
+
+// List 查询已发布的笔记，category为空时不按分类筛选
+func (s *NoteService) List(category string, p pagination.Params) ([]model.Note, pagination.Page, error) {
+	return s.repos.Notes.List(category, p)
+}
+
+// View 查看已发布的笔记，viewerID不为0时填充点赞状态
+func (s *NoteService) View(id, viewerID uint) (model.Note, error) {
+	note, err := s.repos.Notes.FindPublished(id)
+	if err != nil {
+		return note, notFound(err, "笔记不存在")
+	}
+
+	if viewerID != 0 {
+		isLiked, err := s.repos.Notes.HasLiked(note.ID, viewerID)
+		if err != nil {
+			return note, err
+		}
+		note.IsLiked = &isLiked
+	}
+	return note, nil
+}
+
+// Categories 已发布笔记使用过的分类
+func (s *NoteService) Categories() ([]string, error) {
+	return s.repos.Notes.Categories()
+}
+
+// Create 发布笔记。待审核的笔记提交到审核队列，审核通过前不进入检索索引，也不通知被提及的用户
+func (s *NoteService) Create(note *model.Note, review Review) error {
+	return s.create(note, review, nil)
+}
+
+// CreateFromBookmark 将收藏转为笔记，笔记与收藏的关联在同一事务中写入，同一收藏只能转换一次
+func (s *NoteService) CreateFromBookmark(bookmarkID uint, note *model.Note, review Review) error {
+	return s.create(note, review, func(tx *repository.Repositories) error {
+		err := tx.Notes.LinkBookmark(bookmarkID, note.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperr.Conflict("该收藏已转为笔记")
+		}
+		return err
+	})
+}
+
+// create 发布笔记，link不为nil时在创建笔记的事务中执行
+func (s *NoteService) create(note *model.Note, review Review, link func(tx *repository.Repositories) error) error {
+	mentions, err := resolveMentions(s.repos, note.AuthorID, note.Content)
+	if err != nil {
+		return err
+	}
+	note.Mentions = mentions
+	note.Status = review.status()
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Notes.Create(note); err != nil {
+			return err
+		}
+		if link != nil {
+			if err := link(tx); err != nil {
+				return err
+			}
+		}
+		return holdForReview(tx, review, model.TargetTypeNote, note.ID, note.AuthorID, note.Title+"\n"+note.Content)
+	})
+	if err != nil {
+		return err
+	}
+
+	saveEmbedding(embedding.TypeNote, note.ID, embed(embedding.NoteText(note.Title, note.Content)))
+	if !review.Hold {
+		indexDocument(search.NoteDocument(*note))
+		notifyMentions(s.repos, note.Mentions, model.Notification{
+			ActorID:    note.AuthorID,
+			TargetType: model.TargetTypeNote,
+			TargetID:   note.ID,
+			Content:    note.Content,
+		})
+	}
+	return nil
+}
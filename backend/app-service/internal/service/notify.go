@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+)
+
+// notify 发送站内通知，失败只记录日志不影响主流程
+func notify(repos *repository.Repositories, n model.Notification) {
+	if err := repos.Notifications.Send(n); err != nil {
+		log.Printf("Failed to create notification for user %d: %v", n.UserID, err)
+	}
+}
+
+// notifyMentions 通知被提及的用户，同一用户只通知一次，不通知提及者本人。
+// n为通知模板，需设置ActorID（匿名内容为0并设置ActorName）、目标和内容
+func notifyMentions(repos *repository.Repositories, mentions []model.Mention, n model.Notification) {
+	notified := make(map[uint]bool, len(mentions))
+	for _, m := range mentions {
+		if notified[m.UserID] || m.UserID == m.AuthorID {
+			continue
+		}
+		notified[m.UserID] = true
+		n.UserID = m.UserID
+		n.Type = model.NotifyMention
+		notify(repos, n)
+	}
+}
+
+// notifyPostMentions 通知帖子中提及的用户
+func notifyPostMentions(repos *repository.Repositories, post model.Post, mentions []model.Mention) {
+	actorID, actorName := contentActor(post.AuthorID, post.IsAnonymous, post.AnonName)
+	notifyMentions(repos, mentions, model.Notification{
+		ActorID:    actorID,
+		ActorName:  actorName,
+		TargetType: model.TargetTypePost,
+		TargetID:   post.ID,
+		Content:    post.Content,
+	})
+}
+
+// notifyComment 通知被评论内容的作者、被回复评论的作者和评论中提及的用户，
+// 同一用户只通知一次，匿名评论不透露评论人
+func notifyComment(repos *repository.Repositories, comment model.Comment) {
+	actorID, actorName := contentActor(comment.AuthorID, comment.IsAnonymous, comment.AnonName)
+
+	notified := map[uint]bool{comment.AuthorID: true}
+	if comment.ParentID != nil {
+		if parent, err := repos.Comments.FindByID(*comment.ParentID); err == nil && !notified[parent.AuthorID] {
+			notified[parent.AuthorID] = true
+			notify(repos, model.Notification{
+				UserID:     parent.AuthorID,
+				ActorID:    actorID,
+				ActorName:  actorName,
+				Type:       model.NotifyReply,
+				TargetType: model.TargetTypeComment,
+				TargetID:   parent.ID,
+				RefID:      comment.ID,
+				Content:    comment.Content,
+			})
+		}
+	}
+
+	if ownerID, err := repos.Comments.TargetOwner(comment.TargetType, comment.TargetID); err == nil && !notified[ownerID] {
+		notify(repos, model.Notification{
+			UserID:     ownerID,
+			ActorID:    actorID,
+			ActorName:  actorName,
+			Type:       model.NotifyComment,
+			TargetType: comment.TargetType,
+			TargetID:   comment.TargetID,
+			RefID:      comment.ID,
+			Content:    comment.Content,
+		})
+		notified[ownerID] = true
+	}
+
+	var mentions []model.Mention
+	for _, m := range comment.Mentions {
+		if !notified[m.UserID] {
+			mentions = append(mentions, m)
+		}
+	}
+	notifyMentions(repos, mentions, model.Notification{
+		ActorID:    actorID,
+		ActorName:  actorName,
+		TargetType: comment.TargetType,
+		TargetID:   comment.TargetID,
+		RefID:      comment.ID,
+		Content:    comment.Content,
+	})
+}
+
+// notifyVillagePost 通知帖子作者村落管理员对帖子的操作
+func notifyVillagePost(repos *repository.Repositories, post model.Post, operatorID uint, action string) {
+	village, _ := repos.Villages.FindByID(post.VillageID)
+	notify(repos, model.Notification{
+		UserID:     post.AuthorID,
+		ActorID:    operatorID,
+		Type:       model.NotifyVillage,
+		TargetType: model.TargetTypePost,
+		TargetID:   post.ID,
+		Content:    fmt.Sprintf("你在「%s」的帖子%s", village.Name, action),
+	})
+}
+
+// contentActor 返回通知中展示的操作人，匿名内容只展示化名
+func contentActor(authorID uint, anonymous bool, anonName string) (uint, string) {
+	if anonymous {
+		return 0, anonName
+	}
+	return authorID, ""
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/embedding"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+)
+
+// QuestionService 问题、回答和点赞
+type QuestionService struct {
+	repos *repository.Repositories
+}
+
+func NewQuestionService(repos *repository.Repositories) *QuestionService {
+	return &QuestionService{repos: repos}
+}
+
+// List 查询已发布的问题，viewerID不为0时填充点赞状态
+func (s *QuestionService) List(filter repository.QuestionFilter, p pagination.Params, viewerID uint) ([]model.Question, pagination.Page, error) {
+	questions, page, err := s.repos.Questions.List(filter, p)
+	if err != nil || viewerID == 0 {
+		return questions, page, err
+	}
+
+	ids := make([]uint, len(questions))
+	for i, q := range questions {
+		ids[i] = q.ID
+	}
+	likedIDs, err := s.repos.Questions.LikedIDs(viewerID, ids)
+	if err != nil {
+		return nil, page, err
+	}
+	liked := make(map[uint]bool, len(likedIDs))
+	for _, id := range likedIDs {
+		liked[id] = true
+	}
+	for i := range questions {
+		isLiked := liked[questions[i].ID]
+		questions[i].IsLiked = &isLiked
+	}
+	return questions, page, nil
+}
+
+// Find 查询已发布的问题，被隐藏或待审核的问题不能点赞和回答
+func (s *QuestionService) Find(id uint) (model.Question, error) {
+	question, err := s.repos.Questions.FindPublished(id)
+	return question, notFound(err, "问题不存在")
+}
+
+// View 查看已发布的问题并增加浏览量，viewerID不为0时填充点赞状态。
+// visitor标识访客，同一访客在统计窗口内重复浏览不再增加热度
+func (s *QuestionService) View(id, viewerID uint, visitor string) (model.Question, error) {
+	question, err := s.repos.Questions.FindPublished(id)
+	if err != nil {
+		return question, notFound(err, "问题不存在")
+	}
+
+	if err := s.repos.Questions.IncrementViews(question.ID); err != nil {
+		return question, err
+	}
+	question.Views++
+	if ranking.CountView(model.TargetTypeQuestion, question.ID, visitor) {
+		bumpHotScore(s.repos, model.TargetTypeQuestion, question.ID, ranking.WeightView)
+	}
+
+	if viewerID != 0 {
+		isLiked, err := s.repos.Questions.HasLiked(question.ID, viewerID)
+		if err != nil {
+			return question, err
+		}
+		question.IsLiked = &isLiked
+	}
+	return question, nil
+}
+
+// Create 发布问题并返回疑似重复的问题，重复检测仅作为提示不阻止发布。
+// 待审核的问题提交到审核队列，审核通过前不进入检索索引，也不通知被提及的用户
+func (s *QuestionService) Create(question *model.Question, review Review) ([]SimilarItem, error) {
+	duplicates, vec := s.duplicates(question.Title, question.Content)
+
+	mentions, err := resolveMentions(s.repos, question.AuthorID, question.Content)
+	if err != nil {
+		return nil, err
+	}
+	question.Mentions = mentions
+	question.Status = review.status()
+	question.HotScore = ranking.Score(0, time.Now())
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Questions.Create(question); err != nil {
+			return err
+		}
+		return holdForReview(tx, review, model.TargetTypeQuestion, question.ID, question.AuthorID, question.Title+"\n"+question.Content)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saveEmbedding(embedding.TypeQuestion, question.ID, vec)
+	if !review.Hold {
+		indexDocument(search.QuestionDocument(*question))
+		notifyMentions(s.repos, question.Mentions, model.Notification{
+			ActorID:    question.AuthorID,
+			TargetType: model.TargetTypeQuestion,
+			TargetID:   question.ID,
+			Content:    question.Content,
+		})
+	}
+	return duplicates, nil
+}
+
+// CreateAnswer 回答已发布的问题。待审核的回答提交到审核队列，
+// 直接发布的回答计入问题热度并通知提问者和被提及的用户
+func (s *QuestionService) CreateAnswer(question model.Question, answer *model.Answer, review Review) error {
+	mentions, err := resolveMentions(s.repos, answer.AuthorID, answer.Content)
+	if err != nil {
+		return err
+	}
+	answer.QuestionID = question.ID
+	answer.Mentions = mentions
+	answer.Status = review.status()
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Questions.CreateAnswer(answer); err != nil {
+			return err
+		}
+		return holdForReview(tx, review, model.TargetTypeAnswer, answer.ID, answer.AuthorID, answer.Content)
+	})
+	if err != nil || review.Hold {
+		return err
+	}
+
+	indexDocument(search.AnswerDocument(*answer, question.Title))
+	bumpHotScore(s.repos, model.TargetTypeQuestion, question.ID, ranking.WeightAnswer)
+	notify(s.repos, model.Notification{
+		UserID:     question.AuthorID,
+		ActorID:    answer.AuthorID,
+		Type:       model.NotifyAnswer,
+		TargetType: model.TargetTypeQuestion,
+		TargetID:   question.ID,
+		RefID:      answer.ID,
+		Content:    answer.Content,
+	})
+	notifyMentions(s.repos, answer.Mentions, model.Notification{
+		ActorID:    answer.AuthorID,
+		TargetType: model.TargetTypeQuestion,
+		TargetID:   question.ID,
+		RefID:      answer.ID,
+		Content:    answer.Content,
+	})
+	return nil
+}
+
+// Like 点赞问题，增加热度并通知提问者
+func (s *QuestionService) Like(id, userID uint) error {
+	question, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+
+	liked, err := s.repos.Questions.HasLiked(question.ID, userID)
+	if err != nil {
+		return err
+	}
+	if liked {
+		return apperr.Conflict("已经点赞过了").WithCode(apperr.CodeAlreadyExists)
+	}
+
+	if err := s.repos.Questions.AddLike(question.ID, userID); err != nil {
+		return err
+	}
+
+	bumpHotScore(s.repos, model.TargetTypeQuestion, question.ID, ranking.WeightLike)
+	notify(s.repos, model.Notification{
+		UserID:     question.AuthorID,
+		ActorID:    userID,
+		Type:       model.NotifyLike,
+		TargetType: model.TargetTypeQuestion,
+		TargetID:   question.ID,
+		Content:    question.Title,
+	})
+	return nil
+}
+
+// Unlike 取消点赞问题并扣除热度
+func (s *QuestionService) Unlike(id, userID uint) error {
+	question, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repos.Questions.RemoveLike(question.ID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.Conflict("还没有点赞")
+	}
+	if err != nil {
+		return err
+	}
+	bumpHotScore(s.repos, model.TargetTypeQuestion, question.ID, -ranking.WeightLike)
+	return nil
+}
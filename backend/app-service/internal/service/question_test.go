@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/repository"
+)
+
+// fakeQuestions 内存中的问题数据，未用到的方法由嵌入的接口提供（调用即panic）
+type fakeQuestions struct {
+	repository.QuestionRepository
+	questions map[uint]model.Question
+	likes     map[uint]map[uint]bool
+}
+
+func (f *fakeQuestions) FindPublished(id uint) (model.Question, error) {
+	q, ok := f.questions[id]
+	if !ok || q.Status != model.ContentStatusNormal {
+		return model.Question{}, repository.ErrNotFound
+	}
+	return q, nil
+}
+
+func (f *fakeQuestions) Create(question *model.Question) error {
+	question.ID = uint(len(f.questions) + 1)
+	f.questions[question.ID] = *question
+	return nil
+}
+
+func (f *fakeQuestions) HasLiked(questionID, userID uint) (bool, error) {
+	return f.likes[questionID][userID], nil
+}
+
+func (f *fakeQuestions) AddLike(questionID, userID uint) error {
+	if f.likes[questionID] == nil {
+		f.likes[questionID] = make(map[uint]bool)
+	}
+	f.likes[questionID][userID] = true
+	return nil
+}
+
+type fakeNotifications struct {
+	sent []model.Notification
+}
+
+func (f *fakeNotifications) Send(n model.Notification) error {
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+type fakeReports struct {
+	repository.ReportRepository
+	created []model.Report
+}
+
+func (f *fakeReports) Create(report *model.Report) error {
+	f.created = append(f.created, *report)
+	return nil
+}
+
+type fakeHotScores struct {
+	weights map[uint]float64
+}
+
+func (f *fakeHotScores) Bump(targetType string, id uint, weight float64) error {
+	f.weights[id] += weight
+	return nil
+}
+
+type questionFixture struct {
+	service       *QuestionService
+	questions     *fakeQuestions
+	notifications *fakeNotifications
+	reports       *fakeReports
+	hotScores     *fakeHotScores
+}
+
+func newQuestionFixture() questionFixture {
+	f := questionFixture{
+		questions: &fakeQuestions{
+			questions: map[uint]model.Question{},
+			likes:     map[uint]map[uint]bool{},
+		},
+		notifications: &fakeNotifications{},
+		reports:       &fakeReports{},
+		hotScores:     &fakeHotScores{weights: map[uint]float64{}},
+	}
+	f.service = NewQuestionService(&repository.Repositories{
+		Questions:     f.questions,
+		Notifications: f.notifications,
+		Reports:       f.reports,
+		HotScores:     f.hotScores,
+	})
+	return f
+}
+
+func TestQuestionLike(t *testing.T) {
+	f := newQuestionFixture()
+	f.questions.questions[1] = model.Question{ID: 1, Title: "问题", AuthorID: 10, Status: model.ContentStatusNormal}
+
+	if err := f.service.Like(1, 20); err != nil {
+		t.Fatalf("like: %v", err)
+	}
+	if got := f.hotScores.weights[1]; got != ranking.WeightLike {
+		t.Fatalf("hot score bumped by %v, want %v", got, ranking.WeightLike)
+	}
+	if len(f.notifications.sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(f.notifications.sent))
+	}
+	if n := f.notifications.sent[0]; n.UserID != 10 || n.ActorID != 20 || n.Type != model.NotifyLike {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+
+	err := f.service.Like(1, 20)
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusConflict {
+		t.Fatalf("repeated like returned %v, want conflict", err)
+	}
+	if len(f.notifications.sent) != 1 {
+		t.Fatalf("repeated like sent another notification")
+	}
+
+	if err := f.service.Like(2, 20); !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+		t.Fatalf("liking a missing question returned %v, want not found", err)
+	}
+}
+
+func TestQuestionCreateHeldForReview(t *testing.T) {
+	f := newQuestionFixture()
+
+	question := model.Question{Title: "标题", Content: "内容", AuthorID: 10}
+	review := Review{Hold: true, Reasons: []string{"敏感词"}}
+	if _, err := f.service.Create(&question, review); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if question.Status != model.ContentStatusPending {
+		t.Fatalf("status = %d, want pending", question.Status)
+	}
+	if len(f.reports.created) != 1 {
+		t.Fatalf("created %d reports, want 1", len(f.reports.created))
+	}
+	report := f.reports.created[0]
+	if report.TargetType != model.TargetTypeQuestion || report.TargetID != question.ID || report.Detail != "敏感词" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(f.notifications.sent) != 0 {
+		t.Fatalf("held question sent %d notifications", len(f.notifications.sent))
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"log"
+
+	"ai-egg/app-service/internal/embedding"
+)
+
+// relatedMinScore 相关内容的最低相似度，低于该值的结果不返回
+const relatedMinScore = 0.2
+
+// SimilarItem 语义相似的问题或笔记
+type SimilarItem struct {
+	ID    uint    `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// Related 查询与已发布问题语义相关的问题和笔记，向量服务未启用时返回空结果
+func (s *QuestionService) Related(id uint, limit int) (questions, notes []SimilarItem, err error) {
+	question, err := s.repos.Questions.FindPublished(id)
+	if err != nil {
+		return nil, nil, notFound(err, "问题不存在")
+	}
+
+	store := embedding.GetStore()
+	if store == nil {
+		return []SimilarItem{}, []SimilarItem{}, nil
+	}
+
+	// 向量尚未生成时（如后台补齐未完成）即时生成
+	vec, ok := store.Vector(embedding.TypeQuestion, question.ID)
+	if !ok {
+		vec, err = store.Embed(embedding.QuestionText(question.Title, question.Content))
+		if err != nil {
+			return nil, nil, err
+		}
+		saveEmbedding(embedding.TypeQuestion, question.ID, vec)
+	}
+
+	questions, err = similarItems(store.Nearest(embedding.TypeQuestion, vec, limit, relatedMinScore, question.ID), s.repos.Questions.Titles)
+	if err != nil {
+		return nil, nil, err
+	}
+	notes, err = similarItems(store.Nearest(embedding.TypeNote, vec, limit, relatedMinScore), s.repos.Notes.Titles)
+	return questions, notes, err
+}
+
+// Duplicates 查找与待发布内容高度相似的问题，向量服务不可用时返回空结果
+func (s *QuestionService) Duplicates(title, content string) []SimilarItem {
+	duplicates, _ := s.duplicates(title, content)
+	return duplicates
+}
+
+// duplicates 查找与待发布内容高度相似的问题，同时返回待发布内容的向量以便发布后保存
+func (s *QuestionService) duplicates(title, content string) ([]SimilarItem, []float32) {
+	store := embedding.GetStore()
+	vec := embed(embedding.QuestionText(title, content))
+	if vec == nil {
+		return []SimilarItem{}, nil
+	}
+
+	items, err := similarItems(store.Nearest(embedding.TypeQuestion, vec, 5, store.DuplicateThreshold), s.repos.Questions.Titles)
+	if err != nil {
+		log.Printf("Failed to load duplicate questions: %v", err)
+		return []SimilarItem{}, vec
+	}
+	return items, vec
+}
+
+// similarItems 按相似度顺序加载标题，已删除或未发布的内容会被过滤
+func similarItems(matches []embedding.Match, titles func(ids []uint) (map[uint]string, error)) ([]SimilarItem, error) {
+	items := []SimilarItem{}
+	if len(matches) == 0 {
+		return items, nil
+	}
+
+	ids := make([]uint, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.TargetID)
+	}
+	byID, err := titles(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		if title, ok := byID[m.TargetID]; ok {
+			items = append(items, SimilarItem{ID: m.TargetID, Title: title, Score: m.Score})
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"strings"
+
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+)
+
+// Review 内容过滤的结果。Hold为true时内容待人工审核，Reasons为命中的过滤规则，随内容提交到审核队列
+type Review struct {
+	Hold    bool
+	Reasons []string
+}
+
+// status 内容发布时的状态
+func (r Review) status() int {
+	if r.Hold {
+		return model.ContentStatusPending
+	}
+	return model.ContentStatusNormal
+}
+
+// reviewReport 生成被内容过滤拦下的内容在审核队列中的记录，审核驳回后内容正常展示
+func reviewReport(targetType string, targetID, ownerID uint, text string, reasons []string) model.Report {
+	detail := strings.Join(reasons, ", ")
+	if runes := []rune(detail); len(runes) > 500 {
+		detail = string(runes[:500])
+	}
+	return model.Report{
+		TargetType:    targetType,
+		TargetID:      targetID,
+		TargetOwnerID: ownerID,
+		Reason:        model.ReportReasonFilter,
+		Detail:        detail,
+		Snapshot:      text,
+		Status:        model.ReportStatusOpen,
+	}
+}
+
+// holdForReview 内容待人工审核时提交到审核队列，应与内容在同一事务中调用
+func holdForReview(tx *repository.Repositories, review Review, targetType string, targetID, ownerID uint, text string) error {
+	if !review.Hold {
+		return nil
+	}
+	report := reviewReport(targetType, targetID, ownerID, text, review.Reasons)
+	return tx.Reports.Create(&report)
+}
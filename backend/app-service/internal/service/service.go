@@ -0,0 +1,41 @@
+// Package service 实现用户、问答、笔记、评论、聊天和村落的业务逻辑。
+// 服务只依赖repository接口，不依赖gin和全局数据库连接，可在处理器、初始化脚本和测试中复用。
+// 业务错误（不存在、冲突、无权限等）以*apperr.Error返回，其余错误为数据访问失败
+package service
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/repository"
+)
+
+// Services 汇总各模块的业务服务
+type Services struct {
+	Users     *UserService
+	Questions *QuestionService
+	Notes     *NoteService
+	Comments  *CommentService
+	Chats     *ChatService
+	Villages  *VillageService
+}
+
+// New 基于数据访问层创建全部服务
+func New(repos *repository.Repositories) *Services {
+	return &Services{
+		Users:     NewUserService(repos),
+		Questions: NewQuestionService(repos),
+		Notes:     NewNoteService(repos),
+		Comments:  NewCommentService(repos),
+		Chats:     NewChatService(repos),
+		Villages:  NewVillageService(repos),
+	}
+}
+
+// notFound 将记录不存在转换为带提示信息的业务错误
+func notFound(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.NotFound(message)
+	}
+	return err
+}
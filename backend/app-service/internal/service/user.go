@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials 用户名或密码错误
+var ErrInvalidCredentials = apperr.BadRequest("用户名或密码错误").WithCode(apperr.CodeInvalidCredentials)
+
+// Registration 注册信息
+type Registration struct {
+	Username string
+	Password string
+	Email    string
+}
+
+// ProfileUpdate 个人资料修改，空字段表示不修改
+type ProfileUpdate struct {
+	Email  string
+	Avatar string
+	Bio    string
+}
+
+// UserService 用户注册、认证和资料
+type UserService struct {
+	repos *repository.Repositories
+}
+
+func NewUserService(repos *repository.Repositories) *UserService {
+	return &UserService{repos: repos}
+}
+
+// Get 查询用户
+func (s *UserService) Get(id uint) (model.User, error) {
+	user, err := s.repos.Users.FindByID(id)
+	return user, notFound(err, "用户不存在")
+}
+
+// Register 创建用户，用户名已存在时返回冲突错误
+func (s *UserService) Register(reg Registration) (model.User, error) {
+	exists, err := s.repos.Users.UsernameExists(reg.Username)
+	if err != nil {
+		return model.User{}, err
+	}
+	if exists {
+		return model.User{}, apperr.Conflict("用户名已存在").WithCode(apperr.CodeAlreadyExists)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user := model.User{
+		Username:     reg.Username,
+		PasswordHash: string(hashedPassword),
+		Email:        model.OptionalEmail(reg.Email),
+		Status:       1,
+	}
+	if err := s.repos.Users.Create(&user); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// Authenticate 校验用户名和密码，凭据错误时返回ErrInvalidCredentials。
+// 账号状态由调用方检查，以便先处理登录失败计数
+func (s *UserService) Authenticate(username, password string) (model.User, error) {
+	user, err := s.repos.Users.FindByUsername(username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return model.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// UpdateProfile 修改个人资料，更换邮箱后需要重新验证。
+// 返回更新后的用户以及邮箱是否变更
+func (s *UserService) UpdateProfile(id uint, update ProfileUpdate) (model.User, bool, error) {
+	user, err := s.Get(id)
+	if err != nil {
+		return user, false, err
+	}
+
+	updates := make(map[string]interface{})
+	emailChanged := update.Email != "" && update.Email != user.EmailAddress()
+	if emailChanged {
+		updates["email"] = update.Email
+		updates["email_verified"] = false
+		updates["email_verified_at"] = nil
+	}
+	if update.Avatar != "" {
+		updates["avatar"] = update.Avatar
+	}
+	if update.Bio != "" {
+		updates["bio"] = update.Bio
+	}
+	if len(updates) == 0 {
+		return user, false, nil
+	}
+
+	if err := s.repos.Users.Update(&user, updates); err != nil {
+		return user, false, err
+	}
+	return user, emailChanged, nil
+}
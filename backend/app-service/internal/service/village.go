@@ -0,0 +1,469 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/pagination"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+)
+
+var (
+	anonAdjectives = []string{"安静的", "勇敢的", "快乐的", "温柔的", "好奇的", "害羞的", "自由的", "聪明的",
+		"迷糊的", "认真的", "慵懒的", "热情的", "淡定的", "调皮的", "孤独的", "沉默的"}
+	anonAnimals = []string{"海豚", "狐狸", "熊猫", "猫头鹰", "鲸鱼", "刺猬", "松鼠", "企鹅",
+		"考拉", "水獭", "小鹿", "海鸥", "兔子", "浣熊", "树懒", "萤火虫"}
+)
+
+// VillageService 村落、成员、帖子和帖子回复
+type VillageService struct {
+	repos *repository.Repositories
+}
+
+func NewVillageService(repos *repository.Repositories) *VillageService {
+	return &VillageService{repos: repos}
+}
+
+// List 分页查询开放中的村落
+func (s *VillageService) List(page, pageSize int) ([]model.Village, int64, error) {
+	return s.repos.Villages.List(page, pageSize)
+}
+
+// Get 查询开放中的村落
+func (s *VillageService) Get(id uint) (model.Village, error) {
+	village, err := s.repos.Villages.FindOpen(id)
+	return village, notFound(err, "村落不存在")
+}
+
+// Role 查询用户在村落中的角色，第二个返回值表示是否为村落成员
+func (s *VillageService) Role(villageID, userID uint) (int, bool, error) {
+	member, err := s.repos.Villages.FindMember(villageID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return member.Role, true, nil
+}
+
+// Join 加入开放中的村落
+func (s *VillageService) Join(villageID, userID uint) error {
+	village, err := s.repos.Villages.FindOpen(villageID)
+	if err != nil {
+		return notFound(err, "村落不存在")
+	}
+
+	if _, isMember, err := s.Role(village.ID, userID); err != nil {
+		return err
+	} else if isMember {
+		return apperr.Conflict("已加入该村落").WithCode(apperr.CodeAlreadyExists)
+	}
+
+	return s.repos.Villages.AddMember(&model.VillageMember{
+		VillageID: village.ID,
+		UserID:    userID,
+		Role:      model.VillageRoleMember,
+	})
+}
+
+// Leave 退出村落，同时退出村落聊天室
+func (s *VillageService) Leave(villageID, userID uint) error {
+	village, err := s.repos.Villages.FindByID(villageID)
+	if err != nil {
+		return notFound(err, "村落不存在")
+	}
+
+	member, err := s.repos.Villages.FindMember(village.ID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.Conflict("未加入该村落")
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Villages.RemoveMember(member); err != nil {
+			return err
+		}
+
+		chat, err := tx.Chats.FindByVillage(village.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		chatMember, err := tx.Chats.FindMember(chat.ID, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return removeChatMember(tx, &chat, chatMember)
+	})
+}
+
+// CheckPost 按村落发帖策略校验用户能否发帖，返回目标村落
+func (s *VillageService) CheckPost(villageID, userID uint, anonymous bool) (model.Village, error) {
+	village, err := s.repos.Villages.FindOpen(villageID)
+	if err != nil {
+		return village, notFound(err, "村落不存在")
+	}
+
+	_, isMember, err := s.Role(village.ID, userID)
+	if err != nil {
+		return village, err
+	}
+	if village.PostPolicy == model.PostPolicyMembers && !isMember {
+		return village, apperr.Forbidden("仅村落成员可发帖")
+	}
+	if anonymous && !village.AllowAnon {
+		return village, apperr.Forbidden("该村落不允许匿名发帖")
+	}
+	return village, nil
+}
+
+// CreatePost 在村落中发帖。被内容过滤拦下或村落要求审核且作者不是管理员时帖子待审核，
+// 被内容过滤拦下的帖子同时提交到审核队列。直接发布的帖子计入村落帖子数并通知被提及的用户。
+// 匿名帖子在创建后生成帖子内化名
+func (s *VillageService) CreatePost(village model.Village, post *model.Post, review Review) error {
+	role, _, err := s.Role(village.ID, post.AuthorID)
+	if err != nil {
+		return err
+	}
+	mentions, err := resolveMentions(s.repos, post.AuthorID, post.Content)
+	if err != nil {
+		return err
+	}
+
+	post.VillageID = village.ID
+	post.Mentions = mentions
+	post.Status = model.PostStatusNormal
+	if review.Hold || village.PostPolicy == model.PostPolicyApproval && role < model.VillageRoleAdmin {
+		post.Status = model.PostStatusPending
+	}
+	post.HotScore = ranking.Score(0, time.Now())
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Villages.CreatePost(post); err != nil {
+			return err
+		}
+		if err := holdForReview(tx, review, model.TargetTypePost, post.ID, post.AuthorID, post.Content); err != nil {
+			return err
+		}
+		if !post.IsAnonymous {
+			return nil
+		}
+		// 化名与帖子ID绑定，需在帖子创建后生成
+		name, err := anonymousAlias(tx, post.ID, post.AuthorID)
+		if err != nil {
+			return err
+		}
+		post.AnonName = name
+		return tx.Villages.UpdatePost(post.ID, map[string]interface{}{"anon_name": name})
+	})
+	if err != nil || post.Status != model.PostStatusNormal {
+		return err
+	}
+
+	if err := s.repos.Villages.AddPostCount(village.ID, 1); err != nil {
+		return err
+	}
+	indexDocument(search.PostDocument(*post))
+	notifyPostMentions(s.repos, *post, post.Mentions)
+	return nil
+}
+
+// Posts 查询开放中村落里已发布的帖子
+func (s *VillageService) Posts(villageID uint, filter repository.PostFilter, p pagination.Params) ([]model.Post, pagination.Page, error) {
+	if _, err := s.repos.Villages.FindOpen(villageID); err != nil {
+		return nil, pagination.Page{}, notFound(err, "村落不存在")
+	}
+	return s.repos.Villages.ListPosts(villageID, filter, p)
+}
+
+// LikePost 点赞已发布的帖子，增加热度并通知帖子作者
+func (s *VillageService) LikePost(postID, userID uint) error {
+	post, err := s.repos.Villages.FindPublishedPost(postID)
+	if err != nil {
+		return notFound(err, "帖子不存在")
+	}
+
+	liked, err := s.repos.Villages.HasLikedPost(post.ID, userID)
+	if err != nil {
+		return err
+	}
+	if liked {
+		return apperr.Conflict("已经点赞过了").WithCode(apperr.CodeAlreadyExists)
+	}
+
+	if err := s.repos.Villages.AddPostLike(post.ID, userID); err != nil {
+		return err
+	}
+
+	bumpHotScore(s.repos, model.TargetTypePost, post.ID, ranking.WeightLike)
+	notify(s.repos, model.Notification{
+		UserID:     post.AuthorID,
+		ActorID:    userID,
+		Type:       model.NotifyLike,
+		TargetType: model.TargetTypePost,
+		TargetID:   post.ID,
+		Content:    post.Content,
+	})
+	return nil
+}
+
+// UnlikePost 取消点赞帖子并扣除热度
+func (s *VillageService) UnlikePost(postID, userID uint) error {
+	post, err := s.repos.Villages.FindPost(postID)
+	if err != nil {
+		return notFound(err, "帖子不存在")
+	}
+
+	err = s.repos.Villages.RemovePostLike(post.ID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.Conflict("还没有点赞")
+	}
+	if err != nil {
+		return err
+	}
+	if post.Likes > 0 {
+		bumpHotScore(s.repos, model.TargetTypePost, post.ID, -ranking.WeightLike)
+	}
+	return nil
+}
+
+// OwnPost 查询用户本人的帖子（匿名帖子同样适用），用于编辑前校验
+func (s *VillageService) OwnPost(postID, userID uint) (model.Post, error) {
+	post, err := s.repos.Villages.FindPost(postID)
+	if err != nil {
+		return post, notFound(err, "帖子不存在")
+	}
+	if post.AuthorID != userID {
+		return post, apperr.Forbidden("无权编辑此帖子")
+	}
+	return post, nil
+}
+
+// EditPost 修改帖子内容并重建提及，images为nil时不修改图片，返回修改后的全部提及。
+// 只有已发布的帖子在检索索引中，新提及的用户也在帖子发布后才通知
+func (s *VillageService) EditPost(post *model.Post, content string, images *string) ([]model.Mention, error) {
+	updates := map[string]interface{}{
+		"content": content,
+	}
+	if images != nil {
+		updates["images"] = *images
+	}
+
+	var mentions, added []model.Mention
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Villages.UpdatePost(post.ID, updates); err != nil {
+			return err
+		}
+		var err error
+		mentions, added, err = replaceMentions(tx, model.TargetTypePost, post.ID, post.AuthorID, content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	post.Content = content
+	if images != nil {
+		post.Images = *images
+	}
+	if post.Status == model.PostStatusNormal {
+		indexDocument(search.PostDocument(*post))
+		notifyPostMentions(s.repos, *post, added)
+	}
+	return mentions, nil
+}
+
+// DeletePost 删除帖子，帖子作者或村落管理员可以操作。管理员删除他人帖子时通知作者
+func (s *VillageService) DeletePost(postID, userID uint) error {
+	post, err := s.repos.Villages.FindPost(postID)
+	if err != nil {
+		return notFound(err, "帖子不存在")
+	}
+
+	if post.AuthorID != userID {
+		role, isMember, err := s.Role(post.VillageID, userID)
+		if err != nil {
+			return err
+		}
+		if !isMember || role < model.VillageRoleAdmin {
+			return apperr.Forbidden("无权删除此帖子")
+		}
+	}
+
+	if err := s.repos.Villages.DeletePost(post.ID); err != nil {
+		return err
+	}
+	removeDocument(search.TypePost, post.ID)
+
+	// 待审核和已驳回的帖子不计入村落帖子数
+	if post.Status == model.PostStatusNormal {
+		if err := s.repos.Villages.AddPostCount(post.VillageID, -1); err != nil {
+			return err
+		}
+	}
+	if post.AuthorID != userID {
+		notifyVillagePost(s.repos, post, userID, "已被管理员删除")
+	}
+	return nil
+}
+
+// CheckReply 校验帖子能否回复，返回目标帖子
+func (s *VillageService) CheckReply(postID uint, anonymous bool) (model.Post, error) {
+	post, err := s.repos.Villages.FindPublishedPost(postID)
+	if err != nil {
+		return post, notFound(err, "帖子不存在")
+	}
+
+	if anonymous {
+		village, err := s.repos.Villages.FindOpen(post.VillageID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return post, err
+		}
+		if err != nil || !village.AllowAnon {
+			return post, apperr.Forbidden("该村落不允许匿名回复")
+		}
+	}
+	return post, nil
+}
+
+// Reply 回复帖子，匿名回复沿用作者在该帖子中的化名。待审核的回复提交到审核队列，
+// 审核通过后再计入评论数；直接发布的回复计入帖子热度并通知相关用户
+func (s *VillageService) Reply(post model.Post, reply *model.Comment, review Review) error {
+	mentions, err := resolveMentions(s.repos, reply.AuthorID, reply.Content)
+	if err != nil {
+		return err
+	}
+	reply.TargetID = post.ID
+	reply.TargetType = model.TargetTypePost
+	reply.Mentions = mentions
+	reply.Status = review.status()
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if reply.IsAnonymous {
+			name, err := anonymousAlias(tx, post.ID, reply.AuthorID)
+			if err != nil {
+				return err
+			}
+			reply.AnonName = name
+		}
+		if err := tx.Comments.Create(reply); err != nil {
+			return err
+		}
+		return holdForReview(tx, review, model.TargetTypeComment, reply.ID, reply.AuthorID, reply.Content)
+	})
+	if err != nil || review.Hold {
+		return err
+	}
+
+	if err := s.repos.Villages.AddReplyCount(post.ID, 1); err != nil {
+		return err
+	}
+	bumpHotScore(s.repos, model.TargetTypePost, post.ID, ranking.WeightComment)
+	notifyComment(s.repos, *reply)
+	return nil
+}
+
+// Replies 查询帖子下已发布的回复
+func (s *VillageService) Replies(postID uint, p pagination.Params) ([]model.Comment, pagination.Page, error) {
+	return s.repos.Comments.List(repository.CommentFilter{TargetID: postID, TargetType: model.TargetTypePost}, p)
+}
+
+// OwnReply 查询用户本人在帖子下的回复，用于编辑前校验
+func (s *VillageService) OwnReply(postID, replyID, userID uint) (model.Comment, error) {
+	reply, err := s.repos.Comments.FindReply(postID, replyID)
+	if err != nil {
+		return reply, notFound(err, "回复不存在")
+	}
+	if reply.AuthorID != userID {
+		return reply, apperr.Forbidden("无权编辑此回复")
+	}
+	return reply, nil
+}
+
+// EditReply 修改回复内容并重建提及，返回修改后的全部提及。新提及的用户在回复发布后才通知
+func (s *VillageService) EditReply(reply *model.Comment, content string) ([]model.Mention, error) {
+	var mentions, added []model.Mention
+	err := s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Comments.UpdateContent(reply.ID, content); err != nil {
+			return err
+		}
+		var err error
+		mentions, added, err = replaceMentions(tx, model.TargetTypeComment, reply.ID, reply.AuthorID, content)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply.Content = content
+	if reply.Status == model.ContentStatusNormal {
+		actorID, actorName := contentActor(reply.AuthorID, reply.IsAnonymous, reply.AnonName)
+		notifyMentions(s.repos, added, model.Notification{
+			ActorID:    actorID,
+			ActorName:  actorName,
+			TargetType: reply.TargetType,
+			TargetID:   reply.TargetID,
+			RefID:      reply.ID,
+			Content:    content,
+		})
+	}
+	return mentions, nil
+}
+
+// anonymousAlias 获取用户在帖子内的化名，不存在时随机生成一个帖子内唯一的化名
+func anonymousAlias(tx *repository.Repositories, postID, userID uint) (string, error) {
+	name, err := tx.Villages.FindAlias(postID, userID)
+	if err == nil {
+		return name, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+
+	used, err := tx.Villages.AliasNames(postID)
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(used))
+	for _, name := range used {
+		taken[name] = true
+	}
+
+	name = ""
+	for i := 0; i < 10; i++ {
+		candidate := anonAdjectives[rand.Intn(len(anonAdjectives))] + anonAnimals[rand.Intn(len(anonAnimals))]
+		if !taken[candidate] {
+			name = candidate
+			break
+		}
+	}
+	// 化名组合用尽时追加序号保证唯一
+	if name == "" {
+		name = fmt.Sprintf("%s%s#%d", anonAdjectives[rand.Intn(len(anonAdjectives))],
+			anonAnimals[rand.Intn(len(anonAnimals))], len(used)+1)
+	}
+
+	alias := model.AnonymousAlias{
+		PostID: postID,
+		UserID: userID,
+		Name:   name,
+	}
+	if err := tx.Villages.CreateAlias(&alias); err != nil {
+		return "", err
+	}
+	return alias.Name, nil
+}
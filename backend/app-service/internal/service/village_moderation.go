@@ -0,0 +1,225 @@
+package service
+
+import (
+	"time"
+
+	"ai-egg/app-service/internal/apperr"
+	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/search"
+)
+
+// PolicyUpdate 村落发帖策略修改，零值表示不修改
+type PolicyUpdate struct {
+	PostPolicy string
+	AllowAnon  *bool
+}
+
+// AnonymousTrace 匿名内容溯源请求，TargetType为post或reply
+type AnonymousTrace struct {
+	TargetType string
+	TargetID   uint
+	Reason     string
+}
+
+// UpdatePolicy 村落管理员修改发帖策略
+func (s *VillageService) UpdatePolicy(villageID, adminID uint, update PolicyUpdate) error {
+	village, err := s.requireAdmin(villageID, adminID)
+	if err != nil {
+		return err
+	}
+
+	updates := make(map[string]interface{})
+	if update.PostPolicy != "" {
+		updates["post_policy"] = update.PostPolicy
+	}
+	if update.AllowAnon != nil {
+		updates["allow_anon"] = *update.AllowAnon
+	}
+	if len(updates) == 0 {
+		return apperr.BadRequest("没有需要修改的内容")
+	}
+	return s.repos.Villages.Update(village.ID, updates)
+}
+
+// PendingPosts 村落管理员按提交时间先后查询待审核的帖子
+func (s *VillageService) PendingPosts(villageID, adminID uint, page, pageSize int) ([]model.Post, int64, error) {
+	village, err := s.requireAdmin(villageID, adminID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repos.Villages.ListPendingPosts(village.ID, page, pageSize)
+}
+
+// ApprovePost 审核通过帖子，通过后才计入村落帖子数并通知作者和帖子中提及的用户
+func (s *VillageService) ApprovePost(villageID, postID, adminID uint) error {
+	post, err := s.pendingPost(villageID, postID, adminID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Villages.UpdatePost(post.ID, map[string]interface{}{
+			"status":      model.PostStatusNormal,
+			"reviewed_by": adminID,
+			"reviewed_at": time.Now(),
+		}); err != nil {
+			return err
+		}
+		return tx.Villages.AddPostCount(post.VillageID, 1)
+	})
+	if err != nil {
+		return err
+	}
+	post.Status = model.PostStatusNormal
+
+	indexDocument(search.PostDocument(post))
+	notifyVillagePost(s.repos, post, adminID, "已通过审核")
+
+	mentions, err := s.repos.Mentions.ListBySource(model.TargetTypePost, post.ID)
+	if err != nil {
+		return err
+	}
+	notifyPostMentions(s.repos, post, mentions)
+	return nil
+}
+
+// RejectPost 驳回帖子并将原因通知作者
+func (s *VillageService) RejectPost(villageID, postID, adminID uint, reason string) error {
+	post, err := s.pendingPost(villageID, postID, adminID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repos.Villages.UpdatePost(post.ID, map[string]interface{}{
+		"status":        model.PostStatusRejected,
+		"reviewed_by":   adminID,
+		"reviewed_at":   time.Now(),
+		"reject_reason": reason,
+	}); err != nil {
+		return err
+	}
+
+	action := "未通过审核"
+	if reason != "" {
+		action += "：" + reason
+	}
+	notifyVillagePost(s.repos, post, adminID, action)
+	return nil
+}
+
+// SetPostPinned 置顶或取消置顶已发布的帖子，置顶时通知作者
+func (s *VillageService) SetPostPinned(villageID, postID, adminID uint, pinned bool) error {
+	village, err := s.requireAdmin(villageID, adminID)
+	if err != nil {
+		return err
+	}
+	post, err := s.repos.Villages.FindVillagePost(village.ID, postID)
+	if err != nil {
+		return notFound(err, "帖子不存在")
+	}
+	if post.Status != model.PostStatusNormal {
+		return apperr.Conflict("只能置顶已发布的帖子")
+	}
+
+	updates := map[string]interface{}{
+		"is_pinned": pinned,
+		"pinned_at": nil,
+	}
+	if pinned {
+		updates["pinned_at"] = time.Now()
+	}
+	if err := s.repos.Villages.UpdatePost(post.ID, updates); err != nil {
+		return err
+	}
+
+	if pinned {
+		notifyVillagePost(s.repos, post, adminID, "已被置顶")
+	}
+	return nil
+}
+
+// TraceAnonymous 村落管理员追溯匿名帖子或回复的真实作者。先写审计日志，写入失败则不返回作者
+func (s *VillageService) TraceAnonymous(villageID, operatorID uint, trace AnonymousTrace) (model.AnonymousAuditLog, model.User, error) {
+	village, err := s.requireAdmin(villageID, operatorID)
+	if err != nil {
+		return model.AnonymousAuditLog{}, model.User{}, err
+	}
+
+	var authorID uint
+	switch trace.TargetType {
+	case "post":
+		post, err := s.repos.Villages.FindAnonymousPost(village.ID, trace.TargetID)
+		if err != nil {
+			return model.AnonymousAuditLog{}, model.User{}, notFound(err, "匿名帖子不存在")
+		}
+		authorID = post.AuthorID
+	case "reply":
+		reply, err := s.repos.Villages.FindAnonymousReply(village.ID, trace.TargetID)
+		if err != nil {
+			return model.AnonymousAuditLog{}, model.User{}, notFound(err, "匿名回复不存在")
+		}
+		authorID = reply.AuthorID
+	default:
+		return model.AnonymousAuditLog{}, model.User{}, apperr.BadRequest("不支持的内容类型")
+	}
+
+	author, err := s.repos.Users.FindUnscoped(authorID)
+	if err != nil {
+		return model.AnonymousAuditLog{}, author, notFound(err, "用户不存在")
+	}
+
+	audit := model.AnonymousAuditLog{
+		OperatorID: operatorID,
+		VillageID:  village.ID,
+		TargetType: trace.TargetType,
+		TargetID:   trace.TargetID,
+		AuthorID:   author.ID,
+		Reason:     trace.Reason,
+	}
+	if err := s.repos.Villages.CreateAuditLog(&audit); err != nil {
+		return audit, model.User{}, err
+	}
+	return audit, author, nil
+}
+
+// requireAdmin 校验用户是否为村落管理员，返回目标村落
+func (s *VillageService) requireAdmin(villageID, userID uint) (model.Village, error) {
+	village, err := s.repos.Villages.FindByID(villageID)
+	if err != nil {
+		return village, notFound(err, "村落不存在")
+	}
+
+	role, isMember, err := s.Role(village.ID, userID)
+	if err != nil {
+		return village, err
+	}
+	if !isMember || role < model.VillageRoleAdmin {
+		return village, apperr.Forbidden("仅村落管理员可执行此操作")
+	}
+	return village, nil
+}
+
+// pendingPost 校验管理员权限并查询村落中待审核的帖子
+func (s *VillageService) pendingPost(villageID, postID, adminID uint) (model.Post, error) {
+	village, err := s.requireAdmin(villageID, adminID)
+	if err != nil {
+		return model.Post{}, err
+	}
+	post, err := s.repos.Villages.FindVillagePost(village.ID, postID)
+	if err != nil {
+		return post, notFound(err, "帖子不存在")
+	}
+	if post.Status != model.PostStatusPending {
+		return post, apperr.Conflict("帖子不在待审核状态")
+	}
+	// 被内容过滤拦下的帖子只能通过平台审核队列放行
+	held, err := s.repos.Reports.HasOpenFilterReport(model.TargetTypePost, post.ID)
+	if err != nil {
+		return post, err
+	}
+	if held {
+		return post, apperr.Conflict("帖子正在等待平台审核")
+	}
+	return post, nil
+}
@@ -0,0 +1,77 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationStore 已吊销访问令牌的存储，以令牌ID（jti）或会话键为键，
+// 记录只需保留到令牌自然过期
+type RevocationStore interface {
+	Revoke(jti string, ttl time.Duration) error
+	IsRevoked(jti string) (bool, error)
+}
+
+// MemoryRevocationStore 基于内存的吊销存储，仅适用于单实例部署
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryRevocationStore 创建内存吊销存储
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{entries: make(map[string]time.Time)}
+}
+
+func (s *MemoryRevocationStore) Revoke(jti string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	// 写入时顺带清理已过期的记录
+	for key, expiresAt := range s.entries {
+		if now.After(expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.entries[jti] = now.Add(ttl)
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.entries[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// RedisRevocationStore 基于Redis的吊销存储，多实例共享
+type RedisRevocationStore struct {
+	client *redis.Client
+}
+
+// NewRedisRevocationStore 创建Redis吊销存储
+func NewRedisRevocationStore(client *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+const revokedKeyPrefix = "token:revoked:"
+
+func (s *RedisRevocationStore) Revoke(jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(context.Background(), revokedKeyPrefix+jti, 1, ttl).Err()
+}
+
+func (s *RedisRevocationStore) IsRevoked(jti string) (bool, error) {
+	n, err := s.client.Exists(context.Background(), revokedKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,494 @@
+// Package token 负责签发和校验访问令牌（JWT）及刷新令牌
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenReused 已作废的刷新令牌被再次使用，可能已泄露
+	ErrTokenReused     = errors.New("refresh token reused")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// StreamTicketTTL 推送票据签发后须在该时间内使用
+const StreamTicketTTL = 30 * time.Second
+
+// Claims 访问令牌载荷
+type Claims struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid,omitempty"` // 所属会话，即刷新令牌的FamilyID
+	jwt.RegisteredClaims
+}
+
+// Pair 登录或刷新后返回给客户端的令牌
+type Pair struct {
+	UserID           uint   `json:"-"`
+	AccessToken      string `json:"token"`
+	RefreshToken     string `json:"refreshToken"`
+	ExpiresIn        int64  `json:"expiresIn"`        // 访问令牌有效期（秒）
+	RefreshExpiresIn int64  `json:"refreshExpiresIn"` // 刷新令牌有效期（秒）
+}
+
+// ClientInfo 登录或刷新时的客户端信息，记录在会话中
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Service 令牌服务
+type Service struct {
+	db          *gorm.DB
+	revocations RevocationStore
+	keyID       string
+	keys        map[string][]byte
+	issuer      string
+	audience    string
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+// NewService 根据配置创建令牌服务。未配置密钥时生成随机密钥，重启后已签发的令牌全部失效
+func NewService(cfg config.JWTConfig, db *gorm.DB, revocations RevocationStore) *Service {
+	secret := cfg.Secret
+	if secret == "" {
+		log.Println("JWT_SECRET is not set, using a random secret; tokens will not survive restarts")
+		secret = randomString(32)
+	}
+
+	keys := map[string][]byte{cfg.KeyID: []byte(secret)}
+	for kid, key := range cfg.PreviousKeys {
+		if kid != cfg.KeyID {
+			keys[kid] = []byte(key)
+		}
+	}
+
+	return &Service{
+		db:          db,
+		revocations: revocations,
+		keyID:       cfg.KeyID,
+		keys:        keys,
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		accessTTL:   cfg.AccessTTL,
+		refreshTTL:  cfg.RefreshTTL,
+	}
+}
+
+var service *Service
+
+// Init 设置全局令牌服务
+func Init(s *Service) {
+	service = s
+}
+
+// GetService 获取全局令牌服务
+func GetService() *Service {
+	if service == nil {
+		log.Fatal("Token service not initialized")
+	}
+	return service
+}
+
+// GenerateAccessToken 签发访问令牌
+func (s *Service) GenerateAccessToken(userID uint, sessionID string) (string, *Claims, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomString(16),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{s.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+		},
+	}
+
+	signed, err := s.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// ParseAccessToken 校验访问令牌的签名、签发方、受众和有效期
+func (s *Service) ParseAccessToken(tokenString string) (*Claims, error) {
+	return s.parse(tokenString, s.audience)
+}
+
+// IssueStreamTicket 为访问令牌签发建立推送连接用的一次性票据。
+// 浏览器EventSource只能通过URL传递凭据，票据代替访问令牌出现在URL中，
+// 须在StreamTicketTTL内使用且只能使用一次，不能当作访问令牌调用其他接口。
+// 票据的过期时间与访问令牌一致，推送连接据此在访问令牌过期时断开
+func (s *Service) IssueStreamTicket(access *Claims) (string, error) {
+	now := time.Now()
+	return s.sign(&Claims{
+		UserID:    access.UserID,
+		SessionID: access.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomString(16),
+			Subject:   access.Subject,
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{s.streamAudience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: access.ExpiresAt,
+		},
+	})
+}
+
+// RedeemStreamTicket 校验并使用推送票据，同一票据只能成功使用一次。
+// 票据所属会话已下线时同样视为无效
+func (s *Service) RedeemStreamTicket(ticket string) (*Claims, error) {
+	claims, err := s.parse(ticket, s.streamAudience())
+	if err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > StreamTicketTTL {
+		return nil, ErrTokenExpired
+	}
+	if s.IsRevoked(claims) {
+		return nil, ErrInvalidToken
+	}
+	if s.revocations != nil {
+		// 已使用的票据单独记录，不影响推送连接存续期间按jti校验吊销状态
+		key := "ticket:" + claims.ID
+		used, err := s.revocations.IsRevoked(key)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, ErrTokenReused
+		}
+		if err := s.revocations.Revoke(key, StreamTicketTTL); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// streamAudience 推送票据的受众，与访问令牌区分
+func (s *Service) streamAudience() string {
+	return s.audience + ":stream"
+}
+
+func (s *Service) sign(claims *Claims) (string, error) {
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t.Header["kid"] = s.keyID
+	return t.SignedString(s.keys[s.keyID])
+}
+
+func (s *Service) parse(tokenString, audience string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+	if claims.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// IsRevoked 判断访问令牌是否已被吊销：令牌本身被吊销，或所属会话已下线。
+// 吊销存储不可用时记录日志并放行，避免整站无法访问
+func (s *Service) IsRevoked(claims *Claims) bool {
+	if s.revocations == nil {
+		return false
+	}
+	keys := []string{claims.ID}
+	if claims.SessionID != "" {
+		keys = append(keys, sessionRevocationKey(claims.SessionID))
+	}
+	for _, key := range keys {
+		revoked, err := s.revocations.IsRevoked(key)
+		if err != nil {
+			log.Printf("Failed to check token revocation: %v", err)
+			return false
+		}
+		if revoked {
+			return true
+		}
+	}
+	return false
+}
+
+// IssuePair 登录时创建会话，签发访问令牌和新的刷新令牌
+func (s *Service) IssuePair(userID uint, client ClientInfo) (*Pair, error) {
+	var pair *Pair
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		session := model.Session{
+			UserID:       userID,
+			FamilyID:     randomString(16),
+			UserAgent:    truncate(client.UserAgent, 255),
+			IP:           client.IP,
+			LastActiveAt: now,
+			ExpiresAt:    now.Add(s.refreshTTL),
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = s.issuePair(tx, &session, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshTokenOwner 查询刷新令牌所属的用户，不校验令牌状态，也不作废令牌。
+// 用于在换取新令牌前检查账号状态
+func (s *Service) RefreshTokenOwner(refreshToken string) (uint, error) {
+	var current model.RefreshToken
+	if result := s.db.Select("id, user_id").Where("token_hash = ?", hashToken(refreshToken)).First(&current); result.Error != nil {
+		return 0, ErrInvalidToken
+	}
+	return current.UserID, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧刷新令牌随即作废。
+// 已作废的刷新令牌再次出现时视为泄露，同一登录下的全部刷新令牌都会被作废
+func (s *Service) Refresh(refreshToken string, client ClientInfo) (*Pair, error) {
+	var current model.RefreshToken
+	if result := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&current); result.Error != nil {
+		return nil, ErrInvalidToken
+	}
+	if current.RevokedAt != nil {
+		s.revokeFamily(current.FamilyID)
+		return nil, ErrTokenReused
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	var pair *Pair
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 条件更新保证并发请求中只有一个能使用该刷新令牌
+		now := time.Now()
+		result := tx.Model(&current).Where("revoked_at IS NULL").Update("revoked_at", &now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenReused
+		}
+
+		var session model.Session
+		if err := tx.Where("family_id = ? AND revoked_at IS NULL", current.FamilyID).First(&session).Error; err != nil {
+			return ErrInvalidToken
+		}
+		session.UserAgent = truncate(client.UserAgent, 255)
+		session.IP = client.IP
+		session.LastActiveAt = now
+		session.ExpiresAt = now.Add(s.refreshTTL)
+
+		var err error
+		pair, err = s.issuePair(tx, &session, &current)
+		return err
+	})
+	if errors.Is(err, ErrTokenReused) {
+		s.revokeFamily(current.FamilyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RevokeFamily 作废刷新令牌所属登录下的全部刷新令牌
+func (s *Service) RevokeFamily(refreshToken string) error {
+	var current model.RefreshToken
+	if result := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&current); result.Error != nil {
+		return ErrInvalidToken
+	}
+	return s.revokeFamily(current.FamilyID)
+}
+
+// Logout 退出当前会话：吊销当前访问令牌并作废会话的刷新令牌
+func (s *Service) Logout(claims *Claims) error {
+	if s.revocations != nil && claims.ExpiresAt != nil {
+		if err := s.revocations.Revoke(claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+			return err
+		}
+	}
+	if claims.SessionID == "" {
+		return nil
+	}
+	return s.revokeFamily(claims.SessionID)
+}
+
+// Sessions 获取用户当前有效的会话，最近活跃的在前
+func (s *Service) Sessions(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_active_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession 下线用户的指定会话
+func (s *Service) RevokeSession(userID, sessionID uint) error {
+	var session model.Session
+	if result := s.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session); result.Error != nil {
+		return ErrSessionNotFound
+	}
+	return s.revokeFamily(session.FamilyID)
+}
+
+// RevokeAllSessions 下线用户的全部会话
+func (s *Service) RevokeAllSessions(userID uint) error {
+	var familyIDs []string
+	if err := s.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("family_id", &familyIDs).Error; err != nil {
+		return err
+	}
+	for _, familyID := range familyIDs {
+		if err := s.revokeFamily(familyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeOtherSessions 下线用户除keepSessionID外的全部会话，用于修改密码后保留当前设备
+func (s *Service) RevokeOtherSessions(userID uint, keepSessionID string) error {
+	var familyIDs []string
+	if err := s.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND family_id <> ?", userID, keepSessionID).
+		Pluck("family_id", &familyIDs).Error; err != nil {
+		return err
+	}
+	for _, familyID := range familyIDs {
+		if err := s.revokeFamily(familyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeFamily 作废会话：刷新令牌全部失效，会话签发过的访问令牌同时吊销
+func (s *Service) revokeFamily(familyID string) error {
+	now := time.Now()
+	if err := s.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", &now).Error; err != nil {
+		return err
+	}
+
+	var session model.Session
+	if result := s.db.Where("family_id = ?", familyID).First(&session); result.Error != nil {
+		return nil
+	}
+	if session.RevokedAt == nil {
+		if err := s.db.Model(&session).Update("revoked_at", &now).Error; err != nil {
+			return err
+		}
+	}
+	if s.revocations != nil {
+		// 按会话吊销，覆盖该会话此前刷新得到的所有访问令牌。
+		// 访问令牌最长存活accessTTL，吊销记录保留同样时长即可
+		return s.revocations.Revoke(sessionRevocationKey(familyID), s.accessTTL)
+	}
+	return nil
+}
+
+// sessionRevocationKey 会话吊销记录的键，与jti共用吊销存储
+func sessionRevocationKey(familyID string) string {
+	return "sid:" + familyID
+}
+
+func (s *Service) issuePair(tx *gorm.DB, session *model.Session, previous *model.RefreshToken) (*Pair, error) {
+	access, claims, err := s.GenerateAccessToken(session.UserID, session.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	session.AccessTokenID = claims.ID
+	if err := tx.Save(session).Error; err != nil {
+		return nil, err
+	}
+
+	refresh := randomString(32)
+	row := model.RefreshToken{
+		UserID:    session.UserID,
+		TokenHash: hashToken(refresh),
+		FamilyID:  session.FamilyID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		if err := tx.Model(previous).Update("replaced_by", row.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &Pair{
+		UserID:           session.UserID,
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		ExpiresIn:        int64(s.accessTTL.Seconds()),
+		RefreshExpiresIn: int64(s.refreshTTL.Seconds()),
+	}, nil
+}
+
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func randomString(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -1,45 +1,176 @@
 package main
 
 import (
+	"ai-egg/app-service/internal/apperr"
 	"ai-egg/app-service/internal/config"
+	"ai-egg/app-service/internal/embedding"
+	"ai-egg/app-service/internal/filter"
+	"ai-egg/app-service/internal/mail"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/oauth"
+	"ai-egg/app-service/internal/pubsub"
+	"ai-egg/app-service/internal/ranking"
+	"ai-egg/app-service/internal/ratelimit"
+	"ai-egg/app-service/internal/repository"
 	"ai-egg/app-service/internal/router"
+	"ai-egg/app-service/internal/search"
+	"ai-egg/app-service/internal/service"
+	"ai-egg/app-service/internal/token"
+	"context"
 	"log"
+	"time"
 )
 
 func main() {
 	// 加载配置
 	cfg := config.Load()
 
+	// 旧客户端兼容模式下错误响应仍返回HTTP 200
+	apperr.SetLegacyStatus(cfg.Server.LegacyErrorStatus)
+
 	// 初始化数据库
 	config.InitDB(cfg)
 
 	// 自动迁移数据库表结构
-	if err := config.AutoMigrate(
-		&model.User{},
-		&model.Question{},
-		&model.Answer{},
-		&model.QuestionLike{},
-		&model.Note{},
-		&model.NoteLike{},
-		&model.Comment{},
-		&model.CommentLike{},
-		&model.Chat{},
-		&model.Message{},
-		&model.Village{},
-		&model.VillageMember{},
-		&model.Post{},
-		&model.PostLike{},
-	); err != nil {
+	if err := config.AutoMigrate(model.All()...); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	log.Println("Database migrated successfully")
 
+	// 未设置的邮箱改存NULL，空字符串会与唯一索引冲突
+	if err := config.GetDB().Model(&model.User{}).Where("email = ?", "").
+		Update("email", nil).Error; err != nil {
+		log.Printf("Failed to clear empty emails: %v", err)
+	}
+
+	// 授予配置中的用户管理员角色
+	if len(cfg.Admin.Usernames) > 0 {
+		if err := config.GetDB().Model(&model.User{}).
+			Where("username IN ?", cfg.Admin.Usernames).
+			Update("role", model.RoleAdmin).Error; err != nil {
+			log.Printf("Failed to grant admin role: %v", err)
+		}
+	}
+
 	// 初始化Redis
 	config.InitRedis(cfg)
 
+	// 初始化令牌服务，Redis不可用时吊销记录保存在内存中
+	var revocations token.RevocationStore = token.NewMemoryRevocationStore()
+	if client := config.GetRedis(); client != nil {
+		revocations = token.NewRedisRevocationStore(client)
+	}
+	token.Init(token.NewService(cfg.JWT, config.GetDB(), revocations))
+
+	// 初始化限流和登录失败锁定，Redis不可用时使用内存后端
+	if cfg.RateLimit.Enabled {
+		if client := config.GetRedis(); client != nil {
+			ratelimit.Init(ratelimit.NewRedisLimiter(client))
+			ratelimit.InitLockout(ratelimit.NewRedisLockout(client, ratelimit.DefaultLockoutPolicy))
+		} else {
+			ratelimit.Init(ratelimit.NewMemoryLimiter())
+			ratelimit.InitLockout(ratelimit.NewMemoryLockout(ratelimit.DefaultLockoutPolicy))
+		}
+	}
+
+	// 初始化发布订阅，用于通知实时推送，Redis不可用时只能推送到本实例的连接
+	if client := config.GetRedis(); client != nil {
+		pubsub.Init(pubsub.NewRedisBroker(client))
+	} else {
+		pubsub.Init(pubsub.NewMemoryBroker())
+	}
+
+	// 初始化第三方登录，配置有误的提供方跳过
+	var providers []*oauth.Provider
+	for _, pc := range cfg.OAuth.Providers {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oauth.NewProvider(ctx, pc)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to init oauth provider %s: %v", pc.Name, err)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	oauth.Init(providers)
+	if client := config.GetRedis(); client != nil {
+		oauth.InitStateStore(oauth.NewRedisStateStore(client))
+	}
+
+	// 初始化邮件发送，未配置SMTP时只打印到日志
+	if cfg.Mail.SMTPHost != "" {
+		mail.Init(mail.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From), cfg.Mail.LinkBaseURL)
+	} else {
+		mail.Init(mail.LogMailer{}, cfg.Mail.LinkBaseURL)
+	}
+
+	// 初始化内容过滤，词表加载失败时仍启用链接和灌水检测
+	if cfg.Filter.Enabled {
+		defaultAction, err := filter.ParseAction(cfg.Filter.DefaultAction)
+		if err != nil {
+			log.Printf("Invalid filter default action, using mask: %v", err)
+			defaultAction = filter.ActionMask
+		}
+		var rules []filter.Rule
+		if cfg.Filter.WordsFile != "" {
+			if rules, err = filter.LoadRules(cfg.Filter.WordsFile, defaultAction); err != nil {
+				log.Printf("Failed to load filter words: %v", err)
+			}
+		}
+		for _, word := range cfg.Filter.Words {
+			rules = append(rules, filter.Rule{Word: word, Action: defaultAction})
+		}
+
+		stages := []filter.Stage{
+			filter.NewWordStage(rules),
+			&filter.LinkStage{MaxLinks: cfg.Filter.MaxLinks, BlockedDomains: cfg.Filter.BlockedDomains},
+			&filter.SpamStage{MaxRepeat: cfg.Filter.MaxRepeat, MaxDuplicateLines: cfg.Filter.MaxDuplicateLines},
+		}
+		if cfg.Filter.ClassifierURL != "" {
+			stages = append(stages, &filter.ClassifierStage{
+				Classifier: filter.NewHTTPClassifier(cfg.Filter.ClassifierURL, cfg.Filter.ClassifierAPIKey),
+				Timeout:    cfg.Filter.ClassifierTimeout,
+			})
+		}
+		filter.Init(filter.New(stages...))
+		log.Printf("Content filter enabled with %d words", len(rules))
+	}
+
+	// 初始化全文检索，索引为空时从数据库重建
+	if cfg.Search.Engine == "memory" {
+		search.Init(search.NewMemoryIndexer())
+	} else {
+		search.Init(search.NewMySQLIndexer(config.GetDB()))
+	}
+	if count, err := search.GetIndexer().Count(); err == nil && count == 0 {
+		if err := search.Rebuild(config.GetDB(), search.GetIndexer()); err != nil {
+			log.Printf("Failed to build search index: %v", err)
+		}
+	}
+
+	// 初始化向量存储，在后台加载已有向量并补齐缺失的向量
+	var provider embedding.Provider = embedding.NewHashingProvider(256)
+	if cfg.Embedding.Provider == "http" {
+		provider = embedding.NewHTTPProvider(cfg.Embedding.APIURL, cfg.Embedding.APIKey, cfg.Embedding.Model)
+	}
+	store := embedding.NewStore(config.GetDB(), provider)
+	store.DuplicateThreshold = cfg.Embedding.DuplicateThreshold
+	embedding.Init(store)
+	go func() {
+		if err := store.Load(); err != nil {
+			log.Printf("Failed to load embeddings: %v", err)
+		}
+	}()
+
+	// 为升级前发布的问题和帖子计算热度
+	go func() {
+		if err := ranking.Backfill(config.GetDB()); err != nil {
+			log.Printf("Failed to backfill hot scores: %v", err)
+		}
+	}()
+
 	// 设置路由
-	r := router.SetupRouter()
+	r := router.SetupRouter(service.New(repository.New(config.GetDB())))
 
 	// 启动服务 - 监听所有网卡，支持局域网访问
 	addr := "0.0.0.0:" + cfg.Server.Port
@@ -3,11 +3,12 @@ package main
 import (
 	"ai-egg/app-service/internal/config"
 	"ai-egg/app-service/internal/model"
+	"ai-egg/app-service/internal/repository"
+	"ai-egg/app-service/internal/service"
 	"fmt"
 	"math/rand"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -18,12 +19,13 @@ func main() {
 	// 初始化数据库
 	config.InitDB(cfg)
 	db := config.GetDB()
+	services := service.New(repository.New(db))
 
 	// 清空现有数据
 	cleanData(db)
 
 	// 初始化数据
-	users := initUsers(db)
+	users := initUsers(services.Users)
 	questions := initQuestions(db, users)
 	initAnswers(db, users, questions)
 	initQuestionLikes(db, users, questions)
@@ -70,7 +72,7 @@ type UserProfile struct {
 	Specialties []string
 }
 
-func initUsers(db *gorm.DB) []model.User {
+func initUsers(userService *service.UserService) []model.User {
 	profiles := []UserProfile{
 		// 科技领域
 		{Username: "tech_guru", Email: "tech@guru.com", Bio: "资深软件架构师，10年+开发经验，专注云原生和微服务", Field: "科技", Specialties: []string{"Go", "Kubernetes", "微服务"}},
@@ -126,20 +128,28 @@ func initUsers(db *gorm.DB) []model.User {
 		{Username: "video_creator", Email: "video@creator.com", Bio: "视频创作者，B站百大UP主", Field: "设计", Specialties: []string{"剪辑", "调色", "内容创作"}},
 	}
 
+	// 通过用户服务注册，与正常注册流程使用相同的密码加密和用户名校验
 	var users []model.User
 	for _, profile := range profiles {
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
-		user := model.User{
-			Username:     profile.Username,
-			Email:        profile.Email,
-			Bio:          profile.Bio,
-			PasswordHash: string(hashedPassword),
-			Avatar:       fmt.Sprintf("https://api.dicebear.com/7.x/avataaars/svg?seed=%s", profile.Username),
+		user, err := userService.Register(service.Registration{
+			Username: profile.Username,
+			Password: "password123",
+			Email:    profile.Email,
+		})
+		if err != nil {
+			fmt.Printf("创建用户 %s 失败: %v\n", profile.Username, err)
+			continue
+		}
+		user, _, err = userService.UpdateProfile(user.ID, service.ProfileUpdate{
+			Bio:    profile.Bio,
+			Avatar: fmt.Sprintf("https://api.dicebear.com/7.x/avataaars/svg?seed=%s", profile.Username),
+		})
+		if err != nil {
+			fmt.Printf("更新用户 %s 资料失败: %v\n", profile.Username, err)
 		}
 		users = append(users, user)
 	}
 
-	db.Create(&users)
 	fmt.Printf("已创建 %d 个专业领域用户\n", len(users))
 	return users
 }
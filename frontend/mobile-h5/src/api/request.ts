@@ -1,5 +1,6 @@
 import axios, {
   type AxiosInstance,
+  type InternalAxiosRequestConfig,
   type AxiosRequestConfig,
   type AxiosResponse,
   type AxiosError
@@ -7,6 +8,7 @@ import axios, {
 import { showToast } from 'vant'
 import { useUserStore } from '@/stores/user'
 import type { ApiResponse } from '@/types'
+import type { TokenPair } from './user'
 
 // 创建axios实例
 const request: AxiosInstance = axios.create({
@@ -31,6 +33,31 @@ request.interceptors.request.use(
   }
 )
 
+// 多个请求同时过期时共用同一次刷新
+let refreshing: Promise<string> | null = null
+
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshing) {
+    const userStore = useUserStore()
+    refreshing = axios
+      .post<ApiResponse<TokenPair>>(`${request.defaults.baseURL}/token/refresh`, {
+        refreshToken: userStore.refreshToken
+      })
+      .then(({ data }) => {
+        if (data.code !== 200) {
+          throw data
+        }
+        userStore.setToken(data.data.token)
+        userStore.setRefreshToken(data.data.refreshToken)
+        return data.data.token
+      })
+      .finally(() => {
+        refreshing = null
+      })
+  }
+  return refreshing
+}
+
 // 响应拦截器
 request.interceptors.response.use(
   (response: AxiosResponse<ApiResponse<unknown>>) => {
@@ -41,15 +68,31 @@ request.interceptors.response.use(
     }
     return data.data as AxiosResponse
   },
-  (error: AxiosError<ApiResponse<unknown>>) => {
+  async (error: AxiosError<ApiResponse<unknown>>) => {
     const { response } = error
+    const config = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined
     if (response?.status === 401) {
       const userStore = useUserStore()
+      // 访问令牌过期时先尝试刷新，成功后重发原请求
+      if (userStore.refreshToken && config && !config._retried) {
+        config._retried = true
+        try {
+          const newToken = await refreshAccessToken()
+          config.headers.Authorization = `Bearer ${newToken}`
+          return request(config)
+        } catch {
+          // 刷新失败，按未登录处理
+        }
+      }
       userStore.logout()
       window.location.href = '/login'
       showToast('登录已过期，请重新登录')
+    } else if (response?.status === 429) {
+      // 限流或登录锁定，优先展示服务端给出的提示
+      showToast(response.data?.message || '请求过于频繁，请稍后再试')
     } else {
-      showToast(error.message || '网络错误')
+      // 业务错误返回对应的HTTP状态码，响应体中带有提示
+      showToast(response?.data?.message || error.message || '网络错误')
     }
     return Promise.reject(error)
   }
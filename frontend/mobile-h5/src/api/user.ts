@@ -7,7 +7,14 @@ import type {
   UpdateUserRequest
 } from '@/types'
 
-export const login = (data: LoginRequest): Promise<{ token: string; userId: number }> => {
+export interface TokenPair {
+  token: string
+  refreshToken: string
+  expiresIn: number
+  refreshExpiresIn: number
+}
+
+export const login = (data: LoginRequest): Promise<TokenPair & { userId: number }> => {
   return request.post('/login', data)
 }
 
@@ -16,12 +16,12 @@ export const getVillageDetail = (id: string | number): Promise<Village> => {
   return request.get(`/earth-village/${id}`)
 }
 
-export const joinVillage = (data: { villageId: string | number }): Promise<void> => {
-  return request.post('/earth-village/join', data)
+export const joinVillage = (id: string | number): Promise<void> => {
+  return request.post(`/earth-village/${id}/join`)
 }
 
-export const leaveVillage = (data: { villageId: string | number }): Promise<void> => {
-  return request.post('/earth-village/leave', data)
+export const leaveVillage = (id: string | number): Promise<void> => {
+  return request.post(`/earth-village/${id}/leave`)
 }
 
 export const getPosts = (
@@ -6,6 +6,7 @@ import type { User, LoginRequest } from '@/types'
 export const useUserStore = defineStore('user', () => {
   // State
   const token = ref<string>(localStorage.getItem('token') || '')
+  const refreshToken = ref<string>(localStorage.getItem('refreshToken') || '')
   const userInfo = ref<User | null>(null)
   const isLoading = ref(false)
 
@@ -19,10 +20,17 @@ export const useUserStore = defineStore('user', () => {
     localStorage.setItem('token', newToken)
   }
 
+  const setRefreshToken = (newToken: string): void => {
+    refreshToken.value = newToken
+    localStorage.setItem('refreshToken', newToken)
+  }
+
   const clearToken = (): void => {
     token.value = ''
+    refreshToken.value = ''
     userInfo.value = null
     localStorage.removeItem('token')
+    localStorage.removeItem('refreshToken')
   }
 
   const login = async (credentials: LoginRequest): Promise<boolean> => {
@@ -30,6 +38,7 @@ export const useUserStore = defineStore('user', () => {
     try {
       const res = await loginApi(credentials)
       setToken(res.token)
+      setRefreshToken(res.refreshToken)
       await fetchUserInfo()
       return true
     } catch (error) {
@@ -55,6 +64,7 @@ export const useUserStore = defineStore('user', () => {
 
   return {
     token,
+    refreshToken,
     userInfo,
     isLoading,
     isLoggedIn,
@@ -63,6 +73,7 @@ export const useUserStore = defineStore('user', () => {
     logout,
     fetchUserInfo,
     setToken,
+    setRefreshToken,
     clearToken
   }
 })
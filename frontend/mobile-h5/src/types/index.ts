@@ -3,6 +3,9 @@ export interface ApiResponse<T> {
   code: number
   message: string
   data: T
+  // 出错时的错误码，如 token_expired、not_found
+  error?: string
+  detail?: string
 }
 
 // 分页响应类型